@@ -0,0 +1,75 @@
+package backplane
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"skillly/pkg/config"
+)
+
+const (
+	roomTopicPrefix = "room:"
+	userTopicPrefix = "user:"
+	// AllUsersTopic reaches every user connected to the global socket
+	AllUsersTopic = "users"
+)
+
+// Event is a frame published to every instance of the backend
+// Data is the encoded frame, already JSON, and Except is the connection or the user it is not sent to
+type Event struct {
+	Topic  string          `json:"topic"`
+	Data   json.RawMessage `json:"data"`
+	Except string          `json:"except,omitempty"`
+}
+
+type Handler func(event Event)
+
+// Backplane fans out the chat frames across the instances of the backend
+// every subscriber receives every event, including the events published by its own instance
+type Backplane interface {
+	Publish(event Event) error
+	// Subscribe calls the handler for each event until the returned function is called
+	Subscribe(handler Handler) func()
+}
+
+// Default is the backplane used by the hub and the global notifications
+var Default Backplane = NewMemoryBackplane()
+
+// SetupBackplane uses Postgres LISTEN/NOTIFY when CHAT_BACKPLANE=postgres
+// otherwise the events stay in the process (single instance)
+func SetupBackplane() {
+	if os.Getenv("CHAT_BACKPLANE") == "postgres" {
+		Default = NewPostgresBackplane(config.DB)
+		log.Printf("Chat backplane: Postgres")
+		return
+	}
+
+	Default = NewMemoryBackplane()
+	log.Printf("Chat backplane: in memory")
+}
+
+func RoomTopic(room string) string {
+	return roomTopicPrefix + room
+}
+
+func UserTopic(userID string) string {
+	return userTopicPrefix + userID
+}
+
+// ParseRoomTopic returns the room of a room topic
+func ParseRoomTopic(topic string) (string, bool) {
+	return trimPrefix(topic, roomTopicPrefix)
+}
+
+// ParseUserTopic returns the user of a user topic
+func ParseUserTopic(topic string) (string, bool) {
+	return trimPrefix(topic, userTopicPrefix)
+}
+
+func trimPrefix(topic string, prefix string) (string, bool) {
+	if len(topic) <= len(prefix) || topic[:len(prefix)] != prefix {
+		return "", false
+	}
+	return topic[len(prefix):], true
+}
@@ -0,0 +1,61 @@
+package backplane
+
+import (
+	"log"
+	"sync"
+)
+
+// Number of events waiting for a slow subscriber before they are dropped
+const subscriberBuffer = 1024
+
+// MemoryBackplane delivers the events to the subscribers of the process
+// each subscriber has its own goroutine so that publishing never blocks
+type MemoryBackplane struct {
+	mutex       sync.RWMutex
+	subscribers map[int]chan Event
+	next        int
+}
+
+func NewMemoryBackplane() *MemoryBackplane {
+	return &MemoryBackplane{subscribers: map[int]chan Event{}}
+}
+
+func (b *MemoryBackplane) Publish(event Event) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, events := range b.subscribers {
+		select {
+		case events <- event:
+		default:
+			log.Printf("backplane subscriber is too slow, event on %s dropped", event.Topic)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBackplane) Subscribe(handler Handler) func() {
+	b.mutex.Lock()
+	id := b.next
+	b.next++
+	events := make(chan Event, subscriberBuffer)
+	b.subscribers[id] = events
+	b.mutex.Unlock()
+
+	go func() {
+		for event := range events {
+			handler(event)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+
+			delete(b.subscribers, id)
+			close(events)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package backplane
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+
+	"skillly/pkg/models"
+)
+
+const (
+	postgresChannel = "chat_backplane"
+	// Postgres refuses NOTIFY payloads of 8000 bytes or more, larger events are stored
+	maxNotifyPayload = 7999
+	// How long the stored events are kept for the instances to read them
+	storedEventTTL = time.Minute
+	// Delay before listening again after the connection was lost
+	reconnectDelay = time.Second
+)
+
+// notifyPayload is the payload of a NOTIFY, an event or the ID of a stored event
+type notifyPayload struct {
+	Event
+	StoredID uint `json:"stored_id,omitempty"`
+}
+
+// postgresBackplane publishes the events with NOTIFY, every instance LISTENs on the same channel
+type postgresBackplane struct {
+	db *gorm.DB
+}
+
+func NewPostgresBackplane(db *gorm.DB) Backplane {
+	return &postgresBackplane{db: db}
+}
+
+func (b *postgresBackplane) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) <= maxNotifyPayload {
+		return b.db.Exec("SELECT pg_notify(?, ?)", postgresChannel, string(payload)).Error
+	}
+
+	// The notification is sent on commit, once the stored event can be read
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		stored := models.BackplaneEvent{Payload: string(payload)}
+		if err := tx.Create(&stored).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("created_at < ?", time.Now().Add(-storedEventTTL)).Delete(&models.BackplaneEvent{}).Error; err != nil {
+			return err
+		}
+
+		reference, err := json.Marshal(notifyPayload{StoredID: stored.ID})
+		if err != nil {
+			return err
+		}
+		return tx.Exec("SELECT pg_notify(?, ?)", postgresChannel, string(reference)).Error
+	})
+}
+
+func (b *postgresBackplane) Subscribe(handler Handler) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for ctx.Err() == nil {
+			err := b.listen(ctx, handler)
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("backplane connection lost: %v", err)
+			time.Sleep(reconnectDelay)
+		}
+	}()
+
+	return cancel
+}
+
+// listen holds a connection of the pool until the context is cancelled or the connection fails
+func (b *postgresBackplane) listen(ctx context.Context, handler Handler) error {
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var listenErr error
+	conn.Raw(func(driverConn any) error {
+		pgxConn := driverConn.(*stdlib.Conn).Conn()
+
+		if _, listenErr = pgxConn.Exec(ctx, "LISTEN "+pgx.Identifier{postgresChannel}.Sanitize()); listenErr != nil {
+			return driver.ErrBadConn
+		}
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				listenErr = err
+				// The connection is still listening, never give it back to the pool
+				return driver.ErrBadConn
+			}
+
+			event, err := b.readEvent(notification.Payload)
+			if err != nil {
+				log.Printf("invalid backplane event: %v", err)
+				continue
+			}
+			handler(event)
+		}
+	})
+
+	return listenErr
+}
+
+// readEvent decodes the payload of a notification, the stored events are read from their table
+func (b *postgresBackplane) readEvent(payload string) (Event, error) {
+	var received notifyPayload
+	if err := json.Unmarshal([]byte(payload), &received); err != nil {
+		return Event{}, err
+	}
+	if received.StoredID == 0 {
+		return received.Event, nil
+	}
+
+	var stored models.BackplaneEvent
+	if err := b.db.First(&stored, received.StoredID).Error; err != nil {
+		return Event{}, err
+	}
+	var event Event
+	err := json.Unmarshal([]byte(stored.Payload), &event)
+	return event, err
+}
@@ -1,8 +1,11 @@
 package broadcast
 
 import (
+	"encoding/json"
 	"log"
 	"sync"
+
+	"skillly/chat/backplane"
 )
 
 var (
@@ -29,8 +32,59 @@ func UnregisterUser(userID string) {
 	log.Printf("🌐 Utilisateur %s supprimé des messages globaux", userID)
 }
 
+// Listen délivre aux utilisateurs connectés à cette instance les messages publiés sur le backplane
+// par toutes les instances, jusqu'à l'appel de la fonction renvoyée
+func Listen(bp backplane.Backplane) func() {
+	return bp.Subscribe(func(event backplane.Event) {
+		if event.Topic == backplane.AllUsersTopic {
+			deliverToAllUsersExcept(event.Except, event.Data)
+		} else if userID, ok := backplane.ParseUserTopic(event.Topic); ok {
+			deliverToUser(userID, event.Data)
+		}
+	})
+}
+
+// BroadcastToUser envoie un message à un utilisateur, quelle que soit l'instance où il est connecté
+func BroadcastToUser(userID string, message []byte) {
+	publish(backplane.Event{Topic: backplane.UserTopic(userID), Data: json.RawMessage(message)})
+}
+
+// BroadcastToAllUsers envoie un message à tous les utilisateurs connectés
+func BroadcastToAllUsers(message []byte) {
+	BroadcastToAllUsersExcept("", message)
+}
+
 // BroadcastToAllUsersExcept envoie un message à tous les utilisateurs connectés sauf un
 func BroadcastToAllUsersExcept(excludeUserID string, message []byte) {
+	publish(backplane.Event{Topic: backplane.AllUsersTopic, Data: json.RawMessage(message), Except: excludeUserID})
+}
+
+func publish(event backplane.Event) {
+	if err := backplane.Default.Publish(event); err != nil {
+		log.Printf("⚠️ Erreur de publication sur %s: %v", event.Topic, err)
+	}
+}
+
+// deliverToUser envoie un message à un utilisateur connecté à cette instance
+func deliverToUser(userID string, message []byte) {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	messageChan, ok := globalConnections[userID]
+	if !ok {
+		return
+	}
+
+	select {
+	case messageChan <- message:
+		log.Printf("📡 Message global envoyé à l'utilisateur %s", userID)
+	default:
+		log.Printf("⚠️ Buffer plein pour l'utilisateur %s", userID)
+	}
+}
+
+// deliverToAllUsersExcept envoie un message à tous les utilisateurs connectés à cette instance sauf un
+func deliverToAllUsersExcept(excludeUserID string, message []byte) {
 	globalMutex.RLock()
 	defer globalMutex.RUnlock()
 
@@ -52,7 +106,7 @@ func BroadcastToAllUsersExcept(excludeUserID string, message []byte) {
 	log.Printf("📡 Message global diffusé à %d utilisateurs", count)
 }
 
-// GetConnectedUsers retourne la liste des utilisateurs connectés
+// GetConnectedUsers retourne la liste des utilisateurs connectés à cette instance
 func GetConnectedUsers() []string {
 	globalMutex.RLock()
 	defer globalMutex.RUnlock()
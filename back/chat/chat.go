@@ -30,13 +30,12 @@ var (
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
+	CheckOrigin:     checkOrigin,
 }
 
 // serveWs handles websocket requests from the peer.
-func ServeWs(hub *models.Hub, room string, w http.ResponseWriter, r *http.Request) {
+// The client ID comes from the authenticated token, never from the request.
+func ServeWs(hub *models.Hub, room string, clientID string, w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
@@ -44,20 +43,15 @@ func ServeWs(hub *models.Hub, room string, w http.ResponseWriter, r *http.Reques
 	}
 
 	// Create a new client
-	client := models.NewClient(r.URL.Query().Get("id"), hub, conn, message.NewMessageService())
+	client := models.NewClient(clientID, hub, conn, message.NewMessageService())
 	// Register the client to the hub
 	hub.Register <- client
-	// Create a new room
-	if _, ok := hub.Rooms[room]; !ok {
-		hub.Rooms[room] = models.NewRoom(
-			room, // Room name from the URL
-		)
-		go hub.Rooms[room].RunRoom()
-	}
+	// Get or create the room, its name comes from the URL
+	chatRoom := hub.JoinRoom(room)
 
 	// Register the client to the room
-	hub.Rooms[room].Register <- client
-	client.Rooms[room] = hub.Rooms[room]
+	chatRoom.Register <- client
+	client.Rooms[room] = chatRoom
 
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
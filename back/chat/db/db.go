@@ -1,10 +1,12 @@
 package chatDB
 
 import (
+	"context"
 	"log"
 	"os"
 	"skillly/chat/config"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
@@ -40,9 +42,39 @@ func InitMongoDB(
 	config.DBMongo.Collection("room")
 	config.DBMongo.Collection("message")
 
+	createIndexes()
+
 	log.Printf("Connected to MongoDB database: %s", dbName)
 }
 
+// createIndexes creates the indexes used by the chat queries, it is a no-op when they exist
+func createIndexes() {
+	// History of a room, paginated by creation date
+	_, err := config.DBMongo.Collection("message").Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "room", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create MongoDB indexes: %v", err)
+	}
+
+	// Attachments of a message
+	_, err = config.DBMongo.Collection("attachment").Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "message_id", Value: 1}},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create MongoDB indexes: %v", err)
+	}
+
+	// One read cursor per user and room
+	_, err = config.DBMongo.Collection("readcursor").Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "room", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create MongoDB indexes: %v", err)
+	}
+}
+
 func SetupDB() {
 	dbUser := os.Getenv("DB_USER")
 	dbPassword := os.Getenv("DB_PASSWORD")
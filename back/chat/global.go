@@ -14,9 +14,7 @@ var (
 	globalUpgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		CheckOrigin:     checkOrigin,
 	}
 
 	// Map pour stocker les connexions globales par utilisateur
@@ -133,61 +131,22 @@ func (c *GlobalClient) readPump() {
 }
 
 // BroadcastToUser envoie un message à un utilisateur spécifique via son WebSocket global
+// le message passe par le backplane pour atteindre l'instance où l'utilisateur est connecté
 func BroadcastToUser(userID string, message []byte) {
-	globalMutex.RLock()
-	client, exists := globalConnections[userID]
-	globalMutex.RUnlock()
-
-	if exists {
-		select {
-		case client.Send <- message:
-			log.Printf("📡 Message global envoyé à l'utilisateur %s", userID)
-		default:
-			log.Printf("⚠️ Buffer plein pour l'utilisateur %s, fermeture de la connexion", userID)
-			client.Conn.Close()
-		}
-	} else {
-		log.Printf("ℹ️ Utilisateur %s non connecté au WebSocket global", userID)
-	}
+	broadcast.BroadcastToUser(userID, message)
 }
 
 // BroadcastToAllUsers envoie un message à tous les utilisateurs connectés
 func BroadcastToAllUsers(message []byte) {
-	globalMutex.RLock()
-	defer globalMutex.RUnlock()
-
-	for userID, client := range globalConnections {
-		select {
-		case client.Send <- message:
-			log.Printf("📡 Message global envoyé à l'utilisateur %s", userID)
-		default:
-			log.Printf("⚠️ Buffer plein pour l'utilisateur %s, fermeture de la connexion", userID)
-			client.Conn.Close()
-		}
-	}
+	broadcast.BroadcastToAllUsers(message)
 }
 
 // BroadcastToAllUsersExcept envoie un message à tous les utilisateurs connectés sauf un
 func BroadcastToAllUsersExcept(excludeUserID string, message []byte) {
-	globalMutex.RLock()
-	defer globalMutex.RUnlock()
-
-	for userID, client := range globalConnections {
-		if userID == excludeUserID {
-			continue // Ignorer l'utilisateur exclu
-		}
-
-		select {
-		case client.Send <- message:
-			log.Printf("📡 Message global envoyé à l'utilisateur %s", userID)
-		default:
-			log.Printf("⚠️ Buffer plein pour l'utilisateur %s, fermeture de la connexion", userID)
-			client.Conn.Close()
-		}
-	}
+	broadcast.BroadcastToAllUsersExcept(excludeUserID, message)
 }
 
-// GetConnectedUsers retourne la liste des utilisateurs connectés
+// GetConnectedUsers retourne la liste des utilisateurs connectés à cette instance
 func GetConnectedUsers() []string {
 	globalMutex.RLock()
 	defer globalMutex.RUnlock()
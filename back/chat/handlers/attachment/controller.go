@@ -0,0 +1,104 @@
+package attachment
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	chatMiddleware "skillly/chat/middleware"
+	"skillly/chat/models"
+	"skillly/pkg/middleware"
+)
+
+// UploadAttachmentHandler envoie un fichier dans une room
+// @Summary Envoyer une pièce jointe
+// @Description Enregistre un fichier (PDF, image, texte ou document Word/OpenDocument, 10 Mo maximum). La pièce jointe est ensuite envoyée avec un message via son ID.
+// @Tags messages
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param roomId path string true "ID de la room"
+// @Param file formData file true "Fichier"
+// @Success 201 {object} models.Attachment "Pièce jointe en attente d'envoi"
+// @Failure 400 {object} map[string]string "Fichier manquant"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Failure 413 {object} map[string]string "Fichier trop volumineux"
+// @Failure 415 {object} map[string]string "Type de fichier non autorisé"
+// @Router /messages/room/{roomId}/attachments [post]
+func UploadAttachmentHandler(c *gin.Context) {
+	// Leave room for the multipart headers
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.MaxAttachmentSize+(1<<20))
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrFileTooLarge.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Fichier requis"})
+		}
+		return
+	}
+
+	attachmentService := NewAttachmentService()
+	attachment, err := attachmentService.Upload(c.Param("roomId"), fmt.Sprint(c.Keys["user_id"]), header)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrFileTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrFileTypeNotAllowed):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachmentHandler télécharge une pièce jointe d'une room
+// @Summary Télécharger une pièce jointe
+// @Description Télécharge le fichier d'une pièce jointe envoyée dans la room
+// @Tags messages
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param roomId path string true "ID de la room"
+// @Param attachmentId path string true "ID de la pièce jointe"
+// @Success 200 {file} file "Contenu du fichier"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Failure 404 {object} map[string]string "Pièce jointe introuvable"
+// @Router /messages/room/{roomId}/attachments/{attachmentId} [get]
+func DownloadAttachmentHandler(c *gin.Context) {
+	attachmentService := NewAttachmentService()
+	attachment, content, err := attachmentService.Open(c.Param("roomId"), fmt.Sprint(c.Keys["user_id"]), c.Param("attachmentId"))
+	if err != nil {
+		if errors.Is(err, ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer content.Close()
+
+	// Never let the browser render an uploaded file inline
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.MimeType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// AddRoutes ajoute les routes pour les pièces jointes
+func AddRoutes(r *gin.Engine) {
+	attachmentGroup := r.Group("/messages/room/:roomId/attachments", middleware.AuthMiddleware(), chatMiddleware.RoomMemberMiddleware("roomId"))
+	{
+		attachmentGroup.POST("", UploadAttachmentHandler)
+		attachmentGroup.GET("/:attachmentId", DownloadAttachmentHandler)
+	}
+}
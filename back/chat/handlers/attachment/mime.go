@@ -0,0 +1,44 @@
+package attachment
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+type allowedType struct {
+	mimeType string
+	// sniffed is the type detected from the content of the file,
+	// office documents are zip or OLE archives and can not be told apart from their content
+	sniffed string
+}
+
+// allowedTypes is the allowlist of the files which can be sent in a conversation, by extension
+var allowedTypes = map[string]allowedType{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".png":  {"image/png", "image/png"},
+	".jpg":  {"image/jpeg", "image/jpeg"},
+	".jpeg": {"image/jpeg", "image/jpeg"},
+	".webp": {"image/webp", "image/webp"},
+	".txt":  {"text/plain", "text/plain"},
+	".doc":  {"application/msword", "application/octet-stream"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".odt":  {"application/vnd.oasis.opendocument.text", "application/zip"},
+}
+
+// detectMimeType returns the MIME type of a file if it is allowed
+// the extension and the content of the file must both match the allowlist
+func detectMimeType(fileName string, head []byte) (string, bool) {
+	allowed, ok := allowedTypes[strings.ToLower(filepath.Ext(fileName))]
+	if !ok {
+		return "", false
+	}
+
+	sniffed, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || sniffed != allowed.sniffed {
+		return "", false
+	}
+
+	return allowed.mimeType, true
+}
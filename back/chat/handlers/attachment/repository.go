@@ -0,0 +1,152 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"skillly/chat/models"
+)
+
+var ErrAttachmentNotFound = errors.New("Attachment not found in this room")
+
+// AttachmentRepository stores the metadata of the files uploaded in the rooms
+type AttachmentRepository interface {
+	models.Repository[models.Attachment]
+	GetRoomAttachment(room string, attachmentID string) (models.Attachment, error)
+	ClaimAttachments(room string, senderID string, attachmentIDs []string, messageID bson.ObjectID) ([]models.Attachment, error)
+	ReleaseAttachments(messageID bson.ObjectID) error
+	DeleteMessageAttachments(messageID bson.ObjectID) ([]models.Attachment, error)
+	DeletePendingAttachments(before time.Time) ([]models.Attachment, error)
+	DeleteRoomAttachments(room string) ([]models.Attachment, error)
+}
+
+type attachmentRepository struct {
+	models.Repository[models.Attachment]
+	db *mongo.Database
+}
+
+func NewAttachmentRepository(db *mongo.Database) AttachmentRepository {
+	return &attachmentRepository{
+		Repository: models.NewRepository[models.Attachment](db),
+		db:         db,
+	}
+}
+
+// GetRoomAttachment returns an attachment of the room which is not deleted
+func (r *attachmentRepository) GetRoomAttachment(room string, attachmentID string) (models.Attachment, error) {
+	objectID, err := bson.ObjectIDFromHex(attachmentID)
+	if err != nil {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+
+	var attachment models.Attachment
+	err = r.collection().FindOne(context.TODO(), bson.M{
+		"_id":        objectID,
+		"room":       room,
+		"deleted_at": bson.M{"$exists": false},
+	}).Decode(&attachment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	return attachment, err
+}
+
+// ClaimAttachments links pending attachments of the sender to a message
+// either every attachment is claimed or none of them
+func (r *attachmentRepository) ClaimAttachments(room string, senderID string, attachmentIDs []string, messageID bson.ObjectID) ([]models.Attachment, error) {
+	ctx := context.TODO()
+
+	objectIDs := []bson.ObjectID{}
+	for _, attachmentID := range attachmentIDs {
+		objectID, err := bson.ObjectIDFromHex(attachmentID)
+		if err != nil {
+			return nil, models.ErrInvalidAttachments
+		}
+		for _, claimed := range objectIDs {
+			if claimed == objectID {
+				return nil, models.ErrInvalidAttachments
+			}
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	result, err := r.collection().UpdateMany(ctx, bson.M{
+		"_id":         bson.M{"$in": objectIDs},
+		"room":        room,
+		"uploader_id": senderID,
+		"message_id":  bson.M{"$exists": false},
+		"deleted_at":  bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"message_id": messageID}})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ModifiedCount != int64(len(objectIDs)) {
+		if err := r.ReleaseAttachments(messageID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvalidAttachments
+	}
+
+	results, err := r.collection().Find(ctx, bson.M{"message_id": messageID})
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []models.Attachment{}
+	err = results.All(ctx, &attachments)
+	return attachments, err
+}
+
+// ReleaseAttachments makes the attachments of a message which could not be saved pending again
+func (r *attachmentRepository) ReleaseAttachments(messageID bson.ObjectID) error {
+	_, err := r.collection().UpdateMany(context.TODO(),
+		bson.M{"message_id": messageID},
+		bson.M{"$unset": bson.M{"message_id": ""}},
+	)
+	return err
+}
+
+// DeleteMessageAttachments marks the attachments of a deleted message as deleted and returns them
+func (r *attachmentRepository) DeleteMessageAttachments(messageID bson.ObjectID) ([]models.Attachment, error) {
+	return r.deleteAttachments(bson.M{"message_id": messageID, "deleted_at": bson.M{"$exists": false}})
+}
+
+// DeletePendingAttachments marks the attachments uploaded before a date and never sent as deleted and returns them
+func (r *attachmentRepository) DeletePendingAttachments(before time.Time) ([]models.Attachment, error) {
+	return r.deleteAttachments(bson.M{
+		"message_id": bson.M{"$exists": false},
+		"created_at": bson.M{"$lt": before},
+		"deleted_at": bson.M{"$exists": false},
+	})
+}
+
+// DeleteRoomAttachments marks the attachments of a room as deleted and returns them
+func (r *attachmentRepository) DeleteRoomAttachments(room string) ([]models.Attachment, error) {
+	return r.deleteAttachments(bson.M{"room": room, "deleted_at": bson.M{"$exists": false}})
+}
+
+func (r *attachmentRepository) deleteAttachments(filter bson.M) ([]models.Attachment, error) {
+	ctx := context.TODO()
+
+	results, err := r.collection().Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []models.Attachment{}
+	if err := results.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+
+	_, err = r.collection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+	return attachments, err
+}
+
+func (r *attachmentRepository) collection() *mongo.Collection {
+	return r.db.Collection("attachment")
+}
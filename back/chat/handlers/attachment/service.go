@@ -0,0 +1,112 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"skillly/chat/config"
+	"skillly/chat/models"
+	"skillly/chat/storage"
+)
+
+var (
+	ErrFileTooLarge       = errors.New("File is too large")
+	ErrFileTypeNotAllowed = errors.New("File type is not allowed")
+)
+
+type AttachmentService interface {
+	Upload(room string, uploaderID string, header *multipart.FileHeader) (models.Attachment, error)
+	Open(room string, userID string, attachmentID string) (models.Attachment, io.ReadCloser, error)
+}
+
+type attachmentService struct {
+	attachmentRepository AttachmentRepository
+	storage              storage.Storage
+}
+
+// NewAttachmentService creates a new instance of AttachmentService
+func NewAttachmentService() AttachmentService {
+	return &attachmentService{
+		attachmentRepository: NewAttachmentRepository(config.DBMongo),
+		storage:              storage.Default,
+	}
+}
+
+// Upload checks and stores a file, the attachment is pending until the uploader sends it in a message
+func (s *attachmentService) Upload(room string, uploaderID string, header *multipart.FileHeader) (models.Attachment, error) {
+	if header.Size > models.MaxAttachmentSize {
+		return models.Attachment{}, ErrFileTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return models.Attachment{}, ErrFileTypeNotAllowed
+	}
+
+	fileName := filepath.Base(header.Filename)
+	mimeType, ok := detectMimeType(fileName, head[:n])
+	if !ok {
+		return models.Attachment{}, ErrFileTypeNotAllowed
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return models.Attachment{}, err
+	}
+
+	attachment := models.Attachment{
+		ID:         bson.NewObjectID(),
+		Room:       room,
+		UploaderID: uploaderID,
+		FileName:   fileName,
+		MimeType:   mimeType,
+		Size:       header.Size,
+		CreatedAt:  time.Now(),
+	}
+	attachment.StorageKey = attachment.ID.Hex()
+
+	if err := s.storage.Save(attachment.StorageKey, file); err != nil {
+		return models.Attachment{}, err
+	}
+
+	if err := s.attachmentRepository.Create(&attachment); err != nil {
+		s.storage.Delete(attachment.StorageKey)
+		return models.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// Open returns an attachment of the room and its content
+// a pending attachment can only be read by its uploader
+func (s *attachmentService) Open(room string, userID string, attachmentID string) (models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.attachmentRepository.GetRoomAttachment(room, attachmentID)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+
+	if attachment.MessageID == nil && attachment.UploaderID != userID {
+		return models.Attachment{}, nil, ErrAttachmentNotFound
+	}
+
+	content, err := s.storage.Open(attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return models.Attachment{}, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
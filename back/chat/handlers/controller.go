@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"fmt"
+
 	"skillly/chat"
+	"skillly/chat/handlers/attachment"
 	"skillly/chat/handlers/message"
+	chatMiddleware "skillly/chat/middleware"
 	"skillly/chat/models"
+	"skillly/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,27 +17,38 @@ import (
 
 func AddRoutes(r *gin.Engine, hub *models.Hub) {
 
-	message.AddRoutes(r)
+	message.AddRoutes(r, hub)
+	attachment.AddRoutes(r)
 	wsGroup := r.Group("/ws")
 	{
 		// @Summary WebSocket Connection
 		// @Description Établit une connexion WebSocket pour le chat en temps réel
 		// @Tags websocket
 		// @Param roomId path string true "ID de la room de chat"
+		// @Param token query string true "Token JWT"
 		// @Router /ws/{roomId} [get]
-		wsGroup.GET("/:roomId", func(c *gin.Context) {
+		wsGroup.GET("/:roomId", middleware.WsAuthMiddleware(), chatMiddleware.RoomMemberMiddleware("roomId"), func(c *gin.Context) {
 			roomId := c.Param("roomId")
+			userID := fmt.Sprint(c.Keys["user_id"])
 
-			chat.ServeWs(hub, roomId, c.Writer, c.Request)
+			chat.ServeWs(hub, roomId, userID, c.Writer, c.Request)
 		})
 
 		// @Summary Global WebSocket Connection
 		// @Description Établit une connexion WebSocket globale pour recevoir tous les messages de l'utilisateur
 		// @Tags websocket
 		// @Param userId path string true "ID de l'utilisateur"
+		// @Param token query string true "Token JWT"
 		// @Router /ws/user/{userId} [get]
-		wsGroup.GET("/user/:userId", func(c *gin.Context) {
-			userID := c.Param("userId")
+		wsGroup.GET("/user/:userId", middleware.WsAuthMiddleware(), func(c *gin.Context) {
+			userID := fmt.Sprint(c.Keys["user_id"])
+
+			// A user can only listen to their own notifications
+			if c.Param("userId") != userID {
+				c.JSON(403, gin.H{"error": "Forbidden"})
+				return
+			}
+
 			chat.ServeGlobalWs(userID, c.Writer, c.Request)
 		})
 	}
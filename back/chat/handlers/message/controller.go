@@ -1,22 +1,33 @@
 package message
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	messageDto "skillly/chat/handlers/message/dto"
+	"skillly/chat/handlers/receipt"
+	chatMiddleware "skillly/chat/middleware"
 	"skillly/chat/models"
+	"skillly/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetMessagesByRoomHandler récupère tous les messages d'une room spécifique
+// GetMessagesByRoomHandler récupère une page de l'historique d'une room
 // @Summary Récupérer les messages d'une room
-// @Description Récupère l'historique des messages d'une conversation
+// @Description Récupère l'historique des messages d'une conversation, page par page. Sans curseur, renvoie les messages les plus récents. Les messages sont triés du plus ancien au plus récent.
 // @Tags messages
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param roomId path string true "ID de la room"
-// @Success 200 {array} models.Message "Liste des messages"
-// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Param before query string false "ID du message avant lequel récupérer l'historique"
+// @Param after query string false "ID du message après lequel récupérer l'historique"
+// @Param limit query int false "Nombre de messages (50 par défaut, 100 maximum)"
+// @Success 200 {object} models.MessagePage "Page de messages"
+// @Failure 400 {object} map[string]string "Erreur de validation ou curseur invalide"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
 // @Failure 500 {object} map[string]string "Erreur serveur"
 // @Router /messages/room/{roomId} [get]
 func GetMessagesByRoomHandler(c *gin.Context) {
@@ -26,43 +37,75 @@ func GetMessagesByRoomHandler(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("🔍 [MESSAGES] Récupération des messages pour la room: %s\n", roomID)
+	query := messageDto.MessagePageQuery{}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	messageService := NewMessageService()
-	messages, err := messageService.GetMessagesByRoomID(roomID)
+	page, err := messageService.GetMessagesPage(roomID, query)
 	if err != nil {
-		fmt.Printf("❌ [MESSAGES] Erreur lors de la récupération: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des messages: " + err.Error()})
+		if errors.Is(err, ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des messages: " + err.Error()})
+		}
 		return
 	}
 
-	fmt.Printf("✅ [MESSAGES] %d messages trouvés pour la room %s\n", len(messages), roomID)
+	c.JSON(http.StatusOK, page)
+}
 
-	// Log détaillé de tous les messages pour debug
-	for i, msg := range messages {
-		fmt.Printf("[MESSAGES][%d] Room=%s, Sender=%s, Content=%s, CreatedAt=%v\n", i, msg.Room, msg.SenderID, msg.Content, msg.CreatedAt)
-	}
+// MarkRoomReadHandler marque une room comme lue jusqu'à un message
+// @Summary Marquer une room comme lue
+// @Description Déplace le curseur de lecture de l'utilisateur jusqu'au message indiqué et notifie les membres de la room
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param roomId path string true "ID de la room"
+// @Param readData body messageDto.MarkReadDTO true "Dernier message lu"
+// @Success 200 {object} models.ReadCursor "Curseur de lecture"
+// @Failure 400 {object} map[string]string "Message introuvable dans la room"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Router /messages/room/{roomId}/read [post]
+func MarkRoomReadHandler(hub *models.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roomID := c.Param("roomId")
 
-	// Log du premier message pour debug
-	if len(messages) > 0 {
-		fmt.Printf("📝 [MESSAGES] Premier message: Room=%s, Sender=%s, Content=%s\n",
-			messages[0].Room, messages[0].SenderID, messages[0].Content)
-	} else {
-		fmt.Printf("📝 [MESSAGES] Aucun message trouvé pour la room %s\n", roomID)
-	}
+		dto := messageDto.MarkReadDTO{}
+		if err := c.ShouldBindJSON(&dto); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-	// S'assurer qu'on retourne toujours un tableau JSON valide
-	if messages == nil {
-		messages = []models.Message{}
-	}
+		messageService := NewMessageService()
+		cursor, err := messageService.MarkRoomRead(roomID, fmt.Sprint(c.Keys["user_id"]), dto.MessageID)
+		if err != nil {
+			if errors.Is(err, receipt.ErrMessageNotInRoom) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		// Notifier les membres connectés à la room
+		if frame, err := models.NewReadFrame(cursor); err == nil {
+			hub.Publish(models.Frame{Room: roomID, Data: frame})
+		}
 
-	c.JSON(http.StatusOK, messages)
+		c.JSON(http.StatusOK, cursor)
+	}
 }
 
 // AddRoutes ajoute les routes pour les messages
-func AddRoutes(r *gin.Engine) {
+func AddRoutes(r *gin.Engine, hub *models.Hub) {
 	messageGroup := r.Group("/messages")
 	{
-		messageGroup.GET("/room/:roomId", GetMessagesByRoomHandler)
+		messageGroup.GET("/room/:roomId", middleware.AuthMiddleware(), chatMiddleware.RoomMemberMiddleware("roomId"), GetMessagesByRoomHandler)
+		messageGroup.POST("/room/:roomId/read", middleware.AuthMiddleware(), chatMiddleware.RoomMemberMiddleware("roomId"), MarkRoomReadHandler(hub))
 	}
 }
@@ -3,5 +3,7 @@ package messageDto
 type CreateMessageDTO struct {
 	Room     string `json:"room" binding:"required"`
 	SenderID string `json:"sender" binding:"required"`
-	Content  string `json:"content" binding:"required"`
+	Content  string `json:"content"`
+	// IDs of pending attachments uploaded by the sender in the room
+	Attachments []string `json:"attachments"`
 }
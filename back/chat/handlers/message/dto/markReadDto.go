@@ -0,0 +1,5 @@
+package messageDto
+
+type MarkReadDTO struct {
+	MessageID string `json:"message_id" binding:"required"`
+}
@@ -0,0 +1,14 @@
+package messageDto
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// MessagePageQuery selects a page of the history of a room
+// before and after are message IDs, only one of them can be set
+type MessagePageQuery struct {
+	Before string `form:"before"`
+	After  string `form:"after"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+}
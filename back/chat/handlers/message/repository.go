@@ -1,19 +1,29 @@
 package message
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	messageDto "skillly/chat/handlers/message/dto"
 	"skillly/chat/models"
 
 	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+var ErrInvalidCursor = errors.New("Invalid cursor")
+
 // MessageRepository defines the interface for message data operations
 type MessageRepository interface {
 	models.Repository[models.Message]
 	CreateMessage(dto messageDto.CreateMessageDTO) (models.Message, error)
+	CreateMessageWithAttachments(messageID bson.ObjectID, dto messageDto.CreateMessageDTO, attachments []models.AttachmentRef) (models.Message, error)
+	GetPage(room string, query messageDto.MessagePageQuery) (models.MessagePage, error)
+	EditMessage(room string, messageID string, senderID string, content string) (models.Message, error)
+	DeleteMessage(room string, messageID string, senderID string) (models.Message, error)
+	SetReaction(room string, messageID string, userID string, emoji string, active bool) (models.Message, error)
 }
 
 type messageRepository struct {
@@ -31,15 +41,21 @@ func NewMessageRepository(db *mongo.Database) MessageRepository {
 
 // CreateMessage inserts a new message record into the database
 func (r *messageRepository) CreateMessage(dto messageDto.CreateMessageDTO) (models.Message, error) {
+	return r.CreateMessageWithAttachments(bson.NewObjectID(), dto, nil)
+}
+
+// CreateMessageWithAttachments inserts a new message with the given ID
+// the ID is chosen by the caller so that the attachments can be claimed before the insert
+func (r *messageRepository) CreateMessageWithAttachments(messageID bson.ObjectID, dto messageDto.CreateMessageDTO, attachments []models.AttachmentRef) (models.Message, error) {
 	message := models.Message{
-		Room:      dto.Room,
-		SenderID:  dto.SenderID,
-		Content:   dto.Content,
-		CreatedAt: time.Now(), // Set the created time to now
+		ID:          messageID,
+		Room:        dto.Room,
+		SenderID:    dto.SenderID,
+		Content:     dto.Content,
+		CreatedAt:   time.Now(), // Set the created time to now
+		Attachments: attachments,
 	}
 
-	fmt.Println("Creating message:", message)
-
 	err := r.Repository.Create(&message)
 	if err != nil {
 		return models.Message{}, err
@@ -47,3 +63,159 @@ func (r *messageRepository) CreateMessage(dto messageDto.CreateMessageDTO) (mode
 
 	return message, nil
 }
+
+// GetPage returns a page of the history of a room
+// messages are ordered by (created_at, _id) so messages sent at the same time are never skipped
+func (r *messageRepository) GetPage(room string, query messageDto.MessagePageQuery) (models.MessagePage, error) {
+	ctx := context.TODO()
+
+	if query.Before != "" && query.After != "" {
+		return models.MessagePage{}, ErrInvalidCursor
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = messageDto.DefaultPageLimit
+	}
+	if limit > messageDto.MaxPageLimit {
+		limit = messageDto.MaxPageLimit
+	}
+
+	forward := query.After != ""
+	cursorID := query.Before
+	if forward {
+		cursorID = query.After
+	}
+
+	filter := bson.M{"room": room}
+	if cursorID != "" {
+		cursor, err := r.getRoomMessage(ctx, room, cursorID)
+		if err != nil {
+			return models.MessagePage{}, err
+		}
+
+		operator := "$lt"
+		if forward {
+			operator = "$gt"
+		}
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{operator: cursor.CreatedAt}},
+			bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{operator: cursor.ID}},
+		}
+	}
+
+	// Going back in time the newest messages come first, they are reversed below
+	order := -1
+	if forward {
+		order = 1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(int64(limit + 1))
+
+	results, err := r.db.Collection("message").Find(ctx, filter, opts)
+	if err != nil {
+		return models.MessagePage{}, err
+	}
+
+	messages := []models.Message{}
+	if err := results.All(ctx, &messages); err != nil {
+		return models.MessagePage{}, err
+	}
+
+	page := models.MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.NextCursor = page.Messages[limit-1].ID.Hex()
+	}
+
+	if !forward {
+		for i, j := 0, len(page.Messages)-1; i < j; i, j = i+1, j-1 {
+			page.Messages[i], page.Messages[j] = page.Messages[j], page.Messages[i]
+		}
+	}
+
+	return page, nil
+}
+
+func (r *messageRepository) getRoomMessage(ctx context.Context, room string, messageID string) (models.Message, error) {
+	objectID, err := bson.ObjectIDFromHex(messageID)
+	if err != nil {
+		return models.Message{}, ErrInvalidCursor
+	}
+
+	var message models.Message
+	err = r.db.Collection("message").FindOne(ctx, bson.M{"_id": objectID, "room": room}).Decode(&message)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Message{}, ErrInvalidCursor
+	}
+	return message, err
+}
+
+// EditMessage replaces the content of a message, only its sender can edit it
+func (r *messageRepository) EditMessage(room string, messageID string, senderID string, content string) (models.Message, error) {
+	return r.updateMessage(room, messageID, senderID, true, bson.M{
+		"$set": bson.M{"content": content, "edited_at": time.Now()},
+	})
+}
+
+// DeleteMessage turns a message into a tombstone, only its sender can delete it
+// the content, the reactions and the attachments are cleared so they can no longer be read from the history
+func (r *messageRepository) DeleteMessage(room string, messageID string, senderID string) (models.Message, error) {
+	return r.updateMessage(room, messageID, senderID, true, bson.M{
+		"$set":   bson.M{"content": "", "deleted_at": time.Now()},
+		"$unset": bson.M{"reactions": "", "attachments": ""},
+	})
+}
+
+// SetReaction adds or removes the reaction of a user on a message
+func (r *messageRepository) SetReaction(room string, messageID string, userID string, emoji string, active bool) (models.Message, error) {
+	reaction := models.Reaction{Emoji: emoji, UserID: userID}
+
+	update := bson.M{"$pull": bson.M{"reactions": reaction}}
+	if active {
+		update = bson.M{"$addToSet": bson.M{"reactions": reaction}}
+	}
+
+	return r.updateMessage(room, messageID, userID, false, update)
+}
+
+// updateMessage applies an update to a message of the room which is not deleted
+// when senderOnly is set, the update is applied only if the user is the sender of the message
+func (r *messageRepository) updateMessage(room string, messageID string, userID string, senderOnly bool, update bson.M) (models.Message, error) {
+	ctx := context.TODO()
+
+	objectID, err := bson.ObjectIDFromHex(messageID)
+	if err != nil {
+		return models.Message{}, models.ErrMessageNotFound
+	}
+
+	filter := bson.M{"_id": objectID, "room": room, "deleted_at": bson.M{"$exists": false}}
+	if senderOnly {
+		filter["sender_id"] = userID
+	}
+
+	var message models.Message
+	err = r.db.Collection("message").
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&message)
+	if err == nil {
+		return message, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Message{}, err
+	}
+
+	// Nothing matched, find out why
+	err = r.db.Collection("message").FindOne(ctx, bson.M{"_id": objectID, "room": room}).Decode(&message)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Message{}, models.ErrMessageNotFound
+	}
+	if err != nil {
+		return models.Message{}, err
+	}
+	if message.DeletedAt != nil {
+		return models.Message{}, models.ErrMessageDeleted
+	}
+	return models.Message{}, models.ErrNotMessageSender
+}
@@ -1,65 +1,127 @@
 package message
 
 import (
-	"fmt"
+	"log"
 	"skillly/chat/config"
+	"skillly/chat/handlers/attachment"
 	messageDto "skillly/chat/handlers/message/dto"
+	"skillly/chat/handlers/receipt"
 	"skillly/chat/models"
-	"skillly/pkg/utils"
+	"skillly/chat/storage"
+	pkgConfig "skillly/pkg/config"
+	"skillly/pkg/handlers/match"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type MessageService interface {
 	CreateMessage(dto messageDto.CreateMessageDTO) (models.Message, error)
-	GetMessagesByRoomID(roomID string) ([]models.Message, error)
+	GetMessagesPage(roomID string, query messageDto.MessagePageQuery) (models.MessagePage, error)
+	MarkRoomRead(room string, userID string, messageID string) (models.ReadCursor, error)
+	EditMessage(room string, messageID string, senderID string, content string) (models.Message, error)
+	DeleteMessage(room string, messageID string, senderID string) (models.Message, error)
+	SetReaction(room string, messageID string, userID string, emoji string, active bool) (models.Message, error)
+	GetRoomMembers(room string) ([]uint, error)
 }
 
 type messageService struct {
 	messageRepository MessageRepository
+	receiptRepository receipt.ReceiptRepository
+	// Attachments
+	attachmentRepository attachment.AttachmentRepository
+	storage              storage.Storage
+	// Members of the rooms
+	matchRepository match.MatchRepository
 }
 
 // NewMessageService creates a new instance of MessageService
 func NewMessageService() MessageService {
 	return &messageService{
-		messageRepository: NewMessageRepository(config.DBMongo),
+		messageRepository:    NewMessageRepository(config.DBMongo),
+		receiptRepository:    receipt.NewReceiptRepository(config.DBMongo),
+		attachmentRepository: attachment.NewAttachmentRepository(config.DBMongo),
+		storage:              storage.Default,
+		matchRepository:      match.NewMatchRepository(pkgConfig.DB),
 	}
 }
 
 // CreateMessage creates a new message in the database
+// the attachments must be pending attachments uploaded by the sender in the room
 func (s *messageService) CreateMessage(dto messageDto.CreateMessageDTO) (models.Message, error) {
+	if len(dto.Attachments) == 0 {
+		return s.messageRepository.CreateMessage(dto)
+	}
+
+	if len(dto.Attachments) > models.MaxAttachmentsPerMessage {
+		return models.Message{}, models.ErrInvalidAttachments
+	}
 
-	createdMessage, err := s.messageRepository.CreateMessage(dto)
+	messageID := bson.NewObjectID()
+	attachments, err := s.attachmentRepository.ClaimAttachments(dto.Room, dto.SenderID, dto.Attachments, messageID)
 	if err != nil {
 		return models.Message{}, err
 	}
 
+	refs := []models.AttachmentRef{}
+	for _, attachment := range attachments {
+		refs = append(refs, attachment.Ref())
+	}
+
+	createdMessage, err := s.messageRepository.CreateMessageWithAttachments(messageID, dto, refs)
+	if err != nil {
+		s.attachmentRepository.ReleaseAttachments(messageID)
+		return models.Message{}, err
+	}
+
 	return createdMessage, nil
 }
 
-// GetMessagesByRoomID retrieves messages for a specific room
-func (s *messageService) GetMessagesByRoomID(roomID string) ([]models.Message, error) {
-	fmt.Printf("🔍 [SERVICE] Recherche des messages pour room: %s\n", roomID)
+// GetMessagesPage retrieves a page of the history of a room
+func (s *messageService) GetMessagesPage(roomID string, query messageDto.MessagePageQuery) (models.MessagePage, error) {
+	return s.messageRepository.GetPage(roomID, query)
+}
 
-	params := utils.QueryParams{
-		Filters: map[string]string{
-			"room": roomID,
-		},
-		Sort:  "created_at",
-		Order: "asc",
-	}
+// MarkRoomRead moves the read cursor of a user up to a message of the room
+func (s *messageService) MarkRoomRead(room string, userID string, messageID string) (models.ReadCursor, error) {
+	return s.receiptRepository.MarkRead(room, userID, messageID)
+}
 
-	fmt.Printf("🔍 [SERVICE] Paramètres de recherche: %+v\n", params)
+// EditMessage replaces the content of a message of the sender
+func (s *messageService) EditMessage(room string, messageID string, senderID string, content string) (models.Message, error) {
+	return s.messageRepository.EditMessage(room, messageID, senderID, content)
+}
 
-	messages, err := s.messageRepository.GetAll(params)
+// DeleteMessage soft-deletes a message of the sender and removes its files
+func (s *messageService) DeleteMessage(room string, messageID string, senderID string) (models.Message, error) {
+	deletedMessage, err := s.messageRepository.DeleteMessage(room, messageID, senderID)
 	if err != nil {
-		fmt.Printf("❌ [SERVICE] Erreur repository: %v\n", err)
-		return []models.Message{}, err
+		return models.Message{}, err
 	}
 
-	fmt.Printf("✅ [SERVICE] %d messages récupérés depuis MongoDB\n", len(messages))
-
-	if messages == nil {
-		messages = []models.Message{}
+	attachments, err := s.attachmentRepository.DeleteMessageAttachments(deletedMessage.ID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	for _, attachment := range attachments {
+		if err := s.storage.Delete(attachment.StorageKey); err != nil {
+			log.Printf("error deleting attachment %s: %v", attachment.ID.Hex(), err)
+		}
 	}
 
-	return messages, nil
+	return deletedMessage, nil
+}
+
+// SetReaction adds or removes an emoji reaction of a user on a message
+func (s *messageService) SetReaction(room string, messageID string, userID string, emoji string, active bool) (models.Message, error) {
+	return s.messageRepository.SetReaction(room, messageID, userID, emoji, active)
+}
+
+// GetRoomMembers returns the user IDs of the participants of the match that owns the room
+func (s *messageService) GetRoomMembers(room string) ([]uint, error) {
+	matchID, err := strconv.ParseUint(room, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return s.matchRepository.GetParticipantUserIDs(uint(matchID))
 }
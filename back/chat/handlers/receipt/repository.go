@@ -0,0 +1,110 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"skillly/chat/models"
+)
+
+var ErrMessageNotInRoom = errors.New("Message not found in this room")
+
+// ReceiptRepository stores the read cursor of each user in each room
+type ReceiptRepository interface {
+	models.Repository[models.ReadCursor]
+	MarkRead(room string, userID string, messageID string) (models.ReadCursor, error)
+	GetCursor(room string, userID string) (models.ReadCursor, error)
+	CountUnread(room string, userID string) (int64, error)
+}
+
+type receiptRepository struct {
+	models.Repository[models.ReadCursor]
+	db *mongo.Database
+}
+
+func NewReceiptRepository(db *mongo.Database) ReceiptRepository {
+	return &receiptRepository{
+		Repository: models.NewRepository[models.ReadCursor](db),
+		db:         db,
+	}
+}
+
+// MarkRead moves the cursor of the user up to the given message
+// the cursor never goes back: reading an older message keeps the current cursor
+func (r *receiptRepository) MarkRead(room string, userID string, messageID string) (models.ReadCursor, error) {
+	ctx := context.TODO()
+
+	objectID, err := bson.ObjectIDFromHex(messageID)
+	if err != nil {
+		return models.ReadCursor{}, ErrMessageNotInRoom
+	}
+
+	var message models.Message
+	err = r.db.Collection("message").FindOne(ctx, bson.M{"_id": objectID, "room": room}).Decode(&message)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.ReadCursor{}, ErrMessageNotInRoom
+		}
+		return models.ReadCursor{}, err
+	}
+
+	// A single upsert so that concurrent reads never move the cursor back: the cursor only takes
+	// the message if it comes after the last read one in the (created_at, _id) order of the history
+	newer := bson.M{"$or": bson.A{
+		bson.M{"$lt": bson.A{"$last_read_at", message.CreatedAt}},
+		bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{"$last_read_at", message.CreatedAt}},
+			bson.M{"$lt": bson.A{"$last_read_message_id", message.ID}},
+		}},
+	}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"last_read_message_id": bson.M{"$cond": bson.A{newer, message.ID, "$last_read_message_id"}},
+		"updated_at":           bson.M{"$cond": bson.A{newer, time.Now(), "$updated_at"}},
+		"last_read_at":         bson.M{"$max": bson.A{"$last_read_at", message.CreatedAt}},
+	}}}
+
+	var cursor models.ReadCursor
+	err = r.collection().FindOneAndUpdate(ctx,
+		bson.M{"room": room, "user_id": userID},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&cursor)
+	if err != nil {
+		return models.ReadCursor{}, err
+	}
+
+	return cursor, nil
+}
+
+func (r *receiptRepository) GetCursor(room string, userID string) (models.ReadCursor, error) {
+	var cursor models.ReadCursor
+	err := r.collection().FindOne(context.TODO(), bson.M{"room": room, "user_id": userID}).Decode(&cursor)
+	return cursor, err
+}
+
+// CountUnread counts the messages of the other members after the cursor of the user in the (created_at, _id) order,
+// so that the messages sent in the same millisecond as the last read one are counted. Deleted messages are not counted
+func (r *receiptRepository) CountUnread(room string, userID string) (int64, error) {
+	filter := bson.M{"room": room, "sender_id": bson.M{"$ne": userID}, "deleted_at": bson.M{"$exists": false}}
+
+	cursor, err := r.GetCursor(room, userID)
+	if err == nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$gt": cursor.LastReadAt}},
+			bson.M{"created_at": cursor.LastReadAt, "_id": bson.M{"$gt": cursor.LastReadMessageID}},
+		}
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+
+	return r.db.Collection("message").CountDocuments(context.TODO(), filter)
+}
+
+func (r *receiptRepository) collection() *mongo.Collection {
+	return r.db.Collection("readcursor")
+}
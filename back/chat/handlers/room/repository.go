@@ -1,9 +1,11 @@
 package room
 
 import (
+	"context"
 	"skillly/chat/models"
 	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
@@ -11,6 +13,9 @@ import (
 type RoomRepository interface {
 	models.Repository[models.Room]
 	CreateRoom(name string) (models.Room, error)
+	GetRoomsCreatedBefore(before time.Time) ([]models.Room, error)
+	MarkOrphanedRooms(names []string, at time.Time) error
+	DeleteRoom(name string) error
 }
 
 type roomRepository struct {
@@ -40,3 +45,47 @@ func (r *roomRepository) CreateRoom(name string) (models.Room, error) {
 
 	return room, nil
 }
+
+// GetRoomsCreatedBefore returns the rooms created before a date
+func (r *roomRepository) GetRoomsCreatedBefore(before time.Time) ([]models.Room, error) {
+	ctx := context.TODO()
+	results, err := r.db.Collection("room").Find(ctx, bson.M{"created_at": bson.M{"$lt": before}})
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := []models.Room{}
+	if err := results.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// MarkOrphanedRooms records when the rooms lost their match, the rooms already marked keep their date
+func (r *roomRepository) MarkOrphanedRooms(names []string, at time.Time) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := r.db.Collection("room").UpdateMany(context.TODO(),
+		bson.M{"name": bson.M{"$in": names}, "orphaned_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"orphaned_at": at}},
+	)
+	return err
+}
+
+// DeleteRoom deletes a room with its messages and read cursors, the attachments are deleted apart
+// because their files are in the storage
+func (r *roomRepository) DeleteRoom(name string) error {
+	ctx := context.TODO()
+	for _, collection := range []string{"message", "readcursor", "room"} {
+		filter := bson.M{"room": name}
+		if collection == "room" {
+			filter = bson.M{"name": name}
+		}
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"skillly/pkg/config"
+	"skillly/pkg/handlers/match"
+)
+
+// RoomMemberMiddleware checks that the authenticated user is a participant of the room
+// a room is identified by the ID of its match: only the candidate and the recruiters
+// of the company that owns the job post can access it
+func RoomMemberMiddleware(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		matchID, err := strconv.ParseUint(c.Param(param), 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid room ID"})
+			c.Abort()
+			return
+		}
+
+		members, err := match.NewMatchRepository(config.DB).GetParticipantUserIDs(uint(matchID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(404, gin.H{"error": "Room not found"})
+			} else {
+				c.JSON(500, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+
+		userID, _ := c.Keys["user_id"].(uint)
+		if !slices.Contains(members, userID) {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// MaxAttachmentSize is the maximum size of an uploaded file (10 MB)
+	MaxAttachmentSize = 10 << 20
+	// MaxAttachmentsPerMessage is the maximum number of files sent with a message
+	MaxAttachmentsPerMessage = 5
+)
+
+var ErrInvalidAttachments = errors.New("Attachments must be uploaded by the sender in this room and not sent yet")
+
+// Attachment is a file uploaded in a room, it is pending until a message of its uploader references it
+// the content is kept in the storage under StorageKey
+type Attachment struct {
+	ID         bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Room       string         `bson:"room" json:"room"`
+	UploaderID string         `bson:"uploader_id" json:"uploader_id"`
+	MessageID  *bson.ObjectID `bson:"message_id,omitempty" json:"message_id,omitempty"`
+	FileName   string         `bson:"file_name" json:"file_name"`
+	MimeType   string         `bson:"mime_type" json:"mime_type"`
+	Size       int64          `bson:"size" json:"size"`
+	StorageKey string         `bson:"storage_key" json:"-"`
+	CreatedAt  time.Time      `bson:"created_at" json:"created_at"`
+	DeletedAt  *time.Time     `bson:"deleted_at,omitempty" json:"-"`
+}
+
+// AttachmentRef is the copy of an attachment kept on its message
+type AttachmentRef struct {
+	ID       bson.ObjectID `bson:"_id" json:"id"`
+	FileName string        `bson:"file_name" json:"file_name"`
+	MimeType string        `bson:"mime_type" json:"mime_type"`
+	Size     int64         `bson:"size" json:"size"`
+}
+
+func (a Attachment) Ref() AttachmentRef {
+	return AttachmentRef{ID: a.ID, FileName: a.FileName, MimeType: a.MimeType, Size: a.Size}
+}
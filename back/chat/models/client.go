@@ -1,16 +1,19 @@
 package models
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
 	/* "skillly/chat/handlers/message" */
-	"skillly/chat/broadcast"
 	messageDto "skillly/chat/handlers/message/dto"
+	"skillly/chat/notification"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 const (
@@ -21,27 +24,35 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 	// Maximum message size allowed from peer.
-	maxMessageSize = 512
-)
-
-var (
-	newline = []byte{'\n'}
-	space   = []byte{' '}
+	maxMessageSize = 4096
+	// Maximum size of a reaction, an emoji can be made of several code points.
+	maxEmojiLength = 32
 )
 
 // Define an interface for the message service
 type MessageService interface {
 	CreateMessage(dto messageDto.CreateMessageDTO) (Message, error)
+	MarkRoomRead(room string, userID string, messageID string) (ReadCursor, error)
+	EditMessage(room string, messageID string, senderID string, content string) (Message, error)
+	DeleteMessage(room string, messageID string, senderID string) (Message, error)
+	SetReaction(room string, messageID string, userID string, emoji string, active bool) (Message, error)
+	GetRoomMembers(room string) ([]uint, error)
 }
 
 type Client struct {
-	Id    string
-	Hub   *Hub
-	Rooms map[string]*Room
-	Conn  *websocket.Conn
-	// Buffered channel of outbound messages.
+	Id string
+	// ConnID identifies the connection, a user can be connected from several devices
+	ConnID string
+	Hub    *Hub
+	Rooms  map[string]*Room
+	Conn   *websocket.Conn
+	// Buffered channel of outbound messages, it is only closed by closeSend
 	Send           chan []byte
 	MessageService MessageService
+
+	// The room and the read pump both queue frames, closed tells them the channel is closed
+	sendMutex sync.Mutex
+	closed    bool
 }
 
 // read messages from the client WebSocket connection and forwarding them to the appropriate room for broadcasting
@@ -63,46 +74,259 @@ func (c *Client) ReadPump(room string) {
 			}
 			break
 		}
-		content = bytes.TrimSpace(bytes.Replace(content, newline, space, -1))
-		if room, ok := c.Rooms[room]; ok {
+		room, ok := c.Rooms[room]
+		if !ok {
+			continue
+		}
 
-			// Create a message DTO & unmarshal the content into it
-			messageDto := messageDto.CreateMessageDTO{}
-			json.Unmarshal(content, &messageDto)
+		envelope, protocolErr := DecodeEnvelope(content)
+		if protocolErr != nil {
+			c.sendFrame(NewErrorFrame(envelope.ID, protocolErr.Code, protocolErr.Message))
+			continue
+		}
 
-			createdMessage, err := c.MessageService.CreateMessage(messageDto)
+		switch envelope.Type {
+		case MessageEvent:
+			c.handleMessage(room, envelope)
+		case TypingEvent:
+			c.handleTyping(room, envelope)
+		case ReadEvent:
+			c.handleRead(room, envelope)
+		case EditEvent:
+			c.handleEdit(room, envelope)
+		case DeleteEvent:
+			c.handleDelete(room, envelope)
+		case ReactionEvent:
+			c.handleReaction(room, envelope)
+		}
+	}
+}
 
-			if err != nil {
-				log.Printf("error creating message: %v", err)
-				continue
-			}
+// handleMessage stores a new message, acknowledges it to the sender and broadcasts it to the room
+func (c *Client) handleMessage(room *Room, envelope Envelope) {
+	payload, err := DecodePayload[MessagePayload](envelope)
+	if err != nil || (strings.TrimSpace(payload.Content) == "" && len(payload.AttachmentIDs) == 0) {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, "A message needs a content or attachments"))
+		return
+	}
 
-			// Diffuser le message dans la room
-			room.Broadcast <- Message{
-				SenderID: c.Id,
-				Content:  string(content),
-			}
+	// The room and the sender come from the connection, not from the payload
+	createdMessage, err := c.MessageService.CreateMessage(messageDto.CreateMessageDTO{
+		Room:        room.Name,
+		SenderID:    c.Id,
+		Content:     payload.Content,
+		Attachments: payload.AttachmentIDs,
+	})
 
-			// Diffuser le message globalement à tous les autres utilisateurs
-			// (sauf l'expéditeur)
-			globalMessage := map[string]interface{}{
-				"type":      "new_message",
-				"senderId":  c.Id,
-				"roomId":    messageDto.Room,
-				"content":   messageDto.Content,
-				"timestamp": createdMessage.CreatedAt,
-			}
+	if errors.Is(err, ErrInvalidAttachments) {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, err.Error()))
+		return
+	}
+	if err != nil {
+		log.Printf("error creating message: %v", err)
+		c.sendFrame(NewErrorFrame(envelope.ID, InternalError, "The message could not be saved"))
+		return
+	}
 
-			// Convertir en JSON
-			globalMessageJSON, err := json.Marshal(globalMessage)
-			if err != nil {
-				log.Printf("error marshaling global message: %v", err)
-				continue
-			}
+	ack, err := NewEnvelope(AckEvent, envelope.ID, room.Name, c.Id, AckPayload{
+		MessageID: createdMessage.ID.Hex(),
+		CreatedAt: createdMessage.CreatedAt,
+	})
+	if err == nil {
+		c.sendFrame(ack)
+	}
 
-			// Envoyer à tous les utilisateurs connectés (sauf l'expéditeur)
-			broadcast.BroadcastToAllUsersExcept(c.Id, globalMessageJSON)
-		}
+	// Diffuser le message dans la room
+	frame, err := NewEnvelope(MessageEvent, "", room.Name, c.Id, MessagePayload{
+		MessageID:   createdMessage.ID.Hex(),
+		Content:     createdMessage.Content,
+		Attachments: createdMessage.Attachments,
+		CreatedAt:   createdMessage.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("error encoding message: %v", err)
+		return
+	}
+	c.Hub.Publish(Frame{Room: room.Name, Data: frame, Except: c.ConnID})
+
+	// Notifier les autres participants du match sur leur socket global
+	members, err := c.MessageService.GetRoomMembers(room.Name)
+	if err != nil {
+		log.Printf("error getting members of room %s: %v", room.Name, err)
+		return
+	}
+	notification.Send(members, notification.Event{
+		Type:      notification.NewMessageEvent,
+		SenderID:  c.Id,
+		RoomID:    room.Name,
+		Content:   createdMessage.Content,
+		Timestamp: createdMessage.CreatedAt,
+	})
+}
+
+// handleTyping forwards the typing indicator to the other members of the room
+func (c *Client) handleTyping(room *Room, envelope Envelope) {
+	payload, err := DecodePayload[TypingPayload](envelope)
+	if err != nil {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, err.Error()))
+		return
+	}
+
+	frame, err := NewEnvelope(TypingEvent, "", room.Name, c.Id, payload)
+	if err != nil {
+		return
+	}
+	c.Hub.Publish(Frame{Room: room.Name, Data: frame, Except: c.ConnID})
+}
+
+// handleRead moves the read cursor of the user and sends the receipt to the room
+// the other devices of the user are in the room too and clear their unread badge
+func (c *Client) handleRead(room *Room, envelope Envelope) {
+	payload, err := DecodePayload[ReadPayload](envelope)
+	if err != nil || payload.MessageID == "" {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, "A read receipt needs a message_id"))
+		return
+	}
+
+	cursor, err := c.MessageService.MarkRoomRead(room.Name, c.Id, payload.MessageID)
+	if err != nil {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, err.Error()))
+		return
+	}
+
+	frame, err := NewReadFrame(cursor)
+	if err != nil {
+		return
+	}
+	c.Hub.Publish(Frame{Room: room.Name, Data: frame, Except: c.ConnID})
+}
+
+// handleEdit replaces the content of a message of the user and broadcasts the new content
+func (c *Client) handleEdit(room *Room, envelope Envelope) {
+	payload, err := DecodePayload[EditPayload](envelope)
+	if err != nil || payload.MessageID == "" || strings.TrimSpace(payload.Content) == "" {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, "An edit needs a message_id and a content"))
+		return
+	}
+
+	message, err := c.MessageService.EditMessage(room.Name, payload.MessageID, c.Id, payload.Content)
+	if err != nil {
+		c.sendMessageError(envelope.ID, err)
+		return
+	}
+
+	c.ackAndBroadcast(room, envelope, message, EditEvent, EditPayload{
+		MessageID: message.ID.Hex(),
+		Content:   message.Content,
+		EditedAt:  message.EditedAt,
+	})
+}
+
+// handleDelete turns a message of the user into a tombstone and broadcasts the deletion
+func (c *Client) handleDelete(room *Room, envelope Envelope) {
+	payload, err := DecodePayload[DeletePayload](envelope)
+	if err != nil || payload.MessageID == "" {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, "A deletion needs a message_id"))
+		return
+	}
+
+	message, err := c.MessageService.DeleteMessage(room.Name, payload.MessageID, c.Id)
+	if err != nil {
+		c.sendMessageError(envelope.ID, err)
+		return
+	}
+
+	c.ackAndBroadcast(room, envelope, message, DeleteEvent, DeletePayload{
+		MessageID: message.ID.Hex(),
+		DeletedAt: message.DeletedAt,
+	})
+}
+
+// handleReaction adds or removes a reaction of the user and broadcasts the reactions of the message
+func (c *Client) handleReaction(room *Room, envelope Envelope) {
+	payload, err := DecodePayload[ReactionPayload](envelope)
+	emoji := strings.TrimSpace(payload.Emoji)
+	if err != nil || payload.MessageID == "" || emoji == "" || len(emoji) > maxEmojiLength || !utf8.ValidString(emoji) {
+		c.sendFrame(NewErrorFrame(envelope.ID, InvalidPayloadError, "A reaction needs a message_id and an emoji"))
+		return
+	}
+
+	message, err := c.MessageService.SetReaction(room.Name, payload.MessageID, c.Id, emoji, payload.Active)
+	if err != nil {
+		c.sendMessageError(envelope.ID, err)
+		return
+	}
+
+	c.ackAndBroadcast(room, envelope, message, ReactionEvent, ReactionPayload{
+		MessageID: message.ID.Hex(),
+		Emoji:     emoji,
+		Active:    payload.Active,
+		Reactions: message.Reactions,
+	})
+}
+
+// ackAndBroadcast acknowledges a change of a message to the user and broadcasts it to the room
+func (c *Client) ackAndBroadcast(room *Room, envelope Envelope, message Message, eventType EventType, payload interface{}) {
+	ack, err := NewEnvelope(AckEvent, envelope.ID, room.Name, c.Id, AckPayload{
+		MessageID: message.ID.Hex(),
+		CreatedAt: message.CreatedAt,
+	})
+	if err == nil {
+		c.sendFrame(ack)
+	}
+
+	frame, err := NewEnvelope(eventType, "", room.Name, c.Id, payload)
+	if err != nil {
+		log.Printf("error encoding %s: %v", eventType, err)
+		return
+	}
+	c.Hub.Publish(Frame{Room: room.Name, Data: frame, Except: c.ConnID})
+}
+
+// sendMessageError answers a change of a message with the error code matching the cause
+func (c *Client) sendMessageError(id string, err error) {
+	switch {
+	case errors.Is(err, ErrMessageNotFound), errors.Is(err, ErrMessageDeleted):
+		c.sendFrame(NewErrorFrame(id, NotFoundError, err.Error()))
+	case errors.Is(err, ErrNotMessageSender):
+		c.sendFrame(NewErrorFrame(id, ForbiddenError, err.Error()))
+	default:
+		log.Printf("error updating message: %v", err)
+		c.sendFrame(NewErrorFrame(id, InternalError, "The message could not be updated"))
+	}
+}
+
+// sendFrame queues a frame for this client only, it is dropped if the client is too slow
+func (c *Client) sendFrame(frame []byte) {
+	if !c.trySend(frame) {
+		log.Printf("send buffer full for client %s, frame dropped", c.Id)
+	}
+}
+
+// trySend queues a frame without blocking, it returns false if the buffer is full or closed
+func (c *Client) trySend(frame []byte) bool {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if c.closed {
+		return false
+	}
+	select {
+	case c.Send <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend closes the send channel once, the write pump then closes the connection
+func (c *Client) closeSend() {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.Send)
 	}
 }
 
@@ -123,20 +347,8 @@ func (c *Client) WritePump() {
 				return
 			}
 
-			w, err := c.Conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			// Add queued chat messages to the current websocket message.
-			n := len(c.Send)
-			for i := 0; i < n; i++ {
-				w.Write(newline)
-				w.Write(<-c.Send)
-			}
-
-			if err := w.Close(); err != nil {
+			// One envelope per websocket message so that each frame is valid JSON
+			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				return
 			}
 		case <-ticker.C:
@@ -151,6 +363,7 @@ func (c *Client) WritePump() {
 func NewClient(id string, hub *Hub, conn *websocket.Conn, msgService MessageService) *Client {
 	return &Client{
 		Id:             id,
+		ConnID:         bson.NewObjectID().Hex(),
 		Hub:            hub,
 		Rooms:          make(map[string]*Room),
 		Conn:           conn,
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProtocolVersion is the version of the envelope exchanged on the chat sockets (see chat/PROTOCOL.md)
+const ProtocolVersion = 1
+
+type EventType string
+
+const (
+	MessageEvent  EventType = "message"
+	AckEvent      EventType = "ack"
+	ErrorEvent    EventType = "error"
+	TypingEvent   EventType = "typing"
+	ReadEvent     EventType = "read"
+	PresenceEvent EventType = "presence"
+	EditEvent     EventType = "edit"
+	DeleteEvent   EventType = "delete"
+	ReactionEvent EventType = "reaction"
+)
+
+// Error codes sent in the error frames
+const (
+	InvalidJSONError        = "invalid_json"
+	UnsupportedVersionError = "unsupported_version"
+	UnknownTypeError        = "unknown_type"
+	InvalidPayloadError     = "invalid_payload"
+	InternalError           = "internal_error"
+	NotFoundError           = "not_found"
+	ForbiddenError          = "forbidden"
+)
+
+const (
+	OnlinePresence  = "online"
+	OfflinePresence = "offline"
+)
+
+// Envelope is the JSON frame exchanged on the chat sockets
+// ID is chosen by the client and sent back in the ack or error frame of the request
+type Envelope struct {
+	Version   int             `json:"v"`
+	Type      EventType       `json:"type"`
+	ID        string          `json:"id,omitempty"`
+	Room      string          `json:"room,omitempty"`
+	SenderID  string          `json:"sender_id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// MessagePayload is sent by the client with the content and the IDs of the uploaded attachments
+// and broadcast by the server with the stored message
+type MessagePayload struct {
+	MessageID     string          `json:"message_id,omitempty"`
+	Content       string          `json:"content"`
+	AttachmentIDs []string        `json:"attachment_ids,omitempty"`
+	Attachments   []AttachmentRef `json:"attachments,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type AckPayload struct {
+	MessageID string    `json:"message_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ErrorPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type TypingPayload struct {
+	Typing bool `json:"typing"`
+}
+
+type ReadPayload struct {
+	MessageID string    `json:"message_id"`
+	ReadAt    time.Time `json:"read_at"`
+}
+
+// EditPayload is sent by the client with the message ID and the new content
+// and broadcast by the server with the edit date
+type EditPayload struct {
+	MessageID string     `json:"message_id"`
+	Content   string     `json:"content"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+type DeletePayload struct {
+	MessageID string     `json:"message_id"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ReactionPayload adds the emoji when Active is true and removes it otherwise
+// the server broadcasts it with all the reactions of the message
+type ReactionPayload struct {
+	MessageID string     `json:"message_id"`
+	Emoji     string     `json:"emoji"`
+	Active    bool       `json:"active"`
+	Reactions []Reaction `json:"reactions,omitempty"`
+}
+
+type PresencePayload struct {
+	Status string `json:"status"`
+}
+
+// NewEnvelope encodes a server frame
+func NewEnvelope(eventType EventType, id string, room string, senderID string, payload interface{}) ([]byte, error) {
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Envelope{
+		Version:   ProtocolVersion,
+		Type:      eventType,
+		ID:        id,
+		Room:      room,
+		SenderID:  senderID,
+		Timestamp: time.Now(),
+		Payload:   rawPayload,
+	})
+}
+
+// NewReadFrame encodes the read receipt of a user, sent to the members of the room
+func NewReadFrame(cursor ReadCursor) ([]byte, error) {
+	return NewEnvelope(ReadEvent, "", cursor.Room, cursor.UserID, ReadPayload{
+		MessageID: cursor.LastReadMessageID.Hex(),
+		ReadAt:    cursor.LastReadAt,
+	})
+}
+
+// NewErrorFrame encodes an error frame answering the request with the given ID
+func NewErrorFrame(id string, code string, message string) []byte {
+	frame, _ := NewEnvelope(ErrorEvent, id, "", "", ErrorPayload{Code: code, Message: message})
+	return frame
+}
+
+// DecodeEnvelope decodes and validates a frame sent by a client
+func DecodeEnvelope(data []byte) (Envelope, *ErrorPayload) {
+	envelope := Envelope{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return envelope, &ErrorPayload{Code: InvalidJSONError, Message: err.Error()}
+	}
+
+	if envelope.Version != ProtocolVersion {
+		return envelope, &ErrorPayload{Code: UnsupportedVersionError, Message: "Unsupported protocol version"}
+	}
+
+	switch envelope.Type {
+	case MessageEvent, TypingEvent, ReadEvent, EditEvent, DeleteEvent, ReactionEvent:
+	default:
+		return envelope, &ErrorPayload{Code: UnknownTypeError, Message: "Unknown event type: " + string(envelope.Type)}
+	}
+
+	return envelope, nil
+}
+
+// DecodePayload decodes the payload of an envelope
+func DecodePayload[T any](envelope Envelope) (T, error) {
+	var payload T
+	if len(envelope.Payload) == 0 {
+		return payload, nil
+	}
+	err := json.Unmarshal(envelope.Payload, &payload)
+	return payload, err
+}
@@ -1,16 +1,30 @@
 package models
 
-import "fmt"
+import (
+	"encoding/json"
+	"log"
+	"sync"
 
+	"skillly/chat/backplane"
+)
+
+// Hub keeps the rooms of the clients connected to this instance
+// frames go through the backplane so that they reach the clients connected to every instance
 type Hub struct {
 	Clients    map[string]*Client
 	Rooms      map[string]*Room
 	Unregister chan *Client
 	Register   chan *Client
-	Broadcast  chan Message
+	Backplane  backplane.Backplane
+
+	// Rooms are created by the HTTP handlers and read by the backplane
+	roomsMutex sync.RWMutex
 }
 
 func (h *Hub) RunHub() {
+	unsubscribe := h.Backplane.Subscribe(h.deliver)
+	defer unsubscribe()
+
 	for {
 		select {
 		case client := <-h.Register:
@@ -19,23 +33,66 @@ func (h *Hub) RunHub() {
 		case client := <-h.Unregister:
 			if _, ok := h.Clients[client.Id]; ok {
 				delete(h.Clients, client.Id)
-				close(client.Send)
-			}
-		case message := <-h.Broadcast:
-			fmt.Println("message", message)
-			if room, ok := h.Rooms[message.Room]; ok {
-				room.Broadcast <- message
+				client.closeSend()
 			}
 		}
 	}
 }
 
+// JoinRoom returns the room with the given name, it is created and started on the first call
+func (h *Hub) JoinRoom(name string) *Room {
+	h.roomsMutex.Lock()
+	defer h.roomsMutex.Unlock()
+
+	room, ok := h.Rooms[name]
+	if !ok {
+		room = NewRoom(name, h)
+		h.Rooms[name] = room
+		go room.RunRoom()
+	}
+	return room
+}
+
+// Publish sends a frame to the clients of the room connected to any instance
+func (h *Hub) Publish(frame Frame) {
+	err := h.Backplane.Publish(backplane.Event{
+		Topic:  backplane.RoomTopic(frame.Room),
+		Data:   json.RawMessage(frame.Data),
+		Except: frame.Except,
+	})
+	if err != nil {
+		log.Printf("error publishing frame to room %s: %v", frame.Room, err)
+	}
+}
+
+// deliver sends a frame received from the backplane to the clients of this instance
+func (h *Hub) deliver(event backplane.Event) {
+	roomName, ok := backplane.ParseRoomTopic(event.Topic)
+	if !ok {
+		return
+	}
+
+	h.roomsMutex.RLock()
+	room, ok := h.Rooms[roomName]
+	h.roomsMutex.RUnlock()
+	if !ok {
+		return
+	}
+
+	room.Broadcast <- Frame{Room: roomName, Data: event.Data, Except: event.Except}
+}
+
+// NewHub creates a hub on the default backplane
 func NewHub() *Hub {
+	return NewHubWithBackplane(backplane.Default)
+}
+
+func NewHubWithBackplane(bp backplane.Backplane) *Hub {
 	return &Hub{
 		Clients:    make(map[string]*Client),
 		Rooms:      make(map[string]*Room),
 		Unregister: make(chan *Client),
 		Register:   make(chan *Client),
-		Broadcast:  make(chan Message),
+		Backplane:  bp,
 	}
 }
@@ -1,16 +1,42 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+var (
+	ErrMessageNotFound  = errors.New("Message not found in this room")
+	ErrNotMessageSender = errors.New("Only the sender can change this message")
+	ErrMessageDeleted   = errors.New("Message has been deleted")
+)
+
 // Message represents a message in a chat room
+// a deleted message is kept as a tombstone: DeletedAt is set and the content is cleared
 type Message struct {
 	ID        bson.ObjectID `bson:"_id,omitempty"`
 	Room      string        `bson:"room"`
 	SenderID  string        `bson:"sender_id"`
 	Content   string        `bson:"content"`
 	CreatedAt time.Time     `bson:"created_at"`
+	EditedAt  *time.Time    `bson:"edited_at,omitempty"`
+	DeletedAt *time.Time    `bson:"deleted_at,omitempty"`
+	Reactions []Reaction    `bson:"reactions,omitempty"`
+	// Attachments keeps a copy of the files sent with the message
+	Attachments []AttachmentRef `bson:"attachments,omitempty"`
+}
+
+// Reaction is an emoji added to a message by a user, a user can add each emoji once
+type Reaction struct {
+	Emoji  string `bson:"emoji" json:"emoji"`
+	UserID string `bson:"user_id" json:"user_id"`
+}
+
+// MessagePage is a page of the history of a room, messages are sorted from the oldest to the newest
+// NextCursor is the message ID to pass to fetch the following page, it is empty on the last page
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"next_cursor"`
 }
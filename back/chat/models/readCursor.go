@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ReadCursor is the last message read by a user in a room
+// every message of the room after (LastReadAt, LastReadMessageID) in the order of the history is unread
+type ReadCursor struct {
+	ID                bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Room              string        `bson:"room" json:"room"`
+	UserID            string        `bson:"user_id" json:"user_id"`
+	LastReadMessageID bson.ObjectID `bson:"last_read_message_id" json:"last_read_message_id"`
+	LastReadAt        time.Time     `bson:"last_read_at" json:"last_read_at"`
+	UpdatedAt         time.Time     `bson:"updated_at" json:"updated_at"`
+}
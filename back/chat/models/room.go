@@ -1,53 +1,90 @@
 package models
 
 import (
+	"log"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// Frame is an encoded envelope broadcast to the clients of a room
+type Frame struct {
+	Room string
+	Data []byte
+	// Except is the connection not sent the frame, usually the client that emitted it
+	Except string
+}
+
 // Room represents a chat room
 type Room struct {
 	ID        bson.ObjectID `bson:"_id,omitempty"`
 	Name      string        `bson:"name"`
 	CreatedAt time.Time     `bson:"created_at"`
+	// OrphanedAt is when the purge found the match of the room deleted
+	OrphanedAt *time.Time `bson:"orphaned_at,omitempty"`
 
 	// Temporary fields for processing
 	Clients    map[*Client]bool `bson:"-" json:"-"`
 	Unregister chan *Client     `bson:"-" json:"-"`
 	Register   chan *Client     `bson:"-" json:"-"`
-	Broadcast  chan Message     `bson:"-" json:"-"`
+	Broadcast  chan Frame       `bson:"-" json:"-"`
+	hub        *Hub
 }
 
 func (r *Room) RunRoom() {
 	for {
 		select {
 		case client := <-r.Register:
+			// Tell the newcomer who is already there, without blocking the room on a slow newcomer
+			for other := range r.Clients {
+				if frame, err := NewEnvelope(PresenceEvent, "", r.Name, other.Id, PresencePayload{Status: OnlinePresence}); err == nil {
+					client.sendFrame(frame)
+				}
+			}
 			r.Clients[client] = true
+			r.sendPresence(client, OnlinePresence)
 		case client := <-r.Unregister:
 			if _, ok := r.Clients[client]; ok {
 				delete(r.Clients, client)
-				close(client.Send)
-			}
-		case message := <-r.Broadcast:
-			for client := range r.Clients {
-				select {
-				case client.Send <- []byte(message.Content):
-				default:
-					close(client.Send)
-					delete(r.Clients, client)
-				}
+				client.closeSend()
+				r.sendPresence(client, OfflinePresence)
 			}
+		case frame := <-r.Broadcast:
+			r.broadcast(frame)
+		}
+	}
+}
+
+// sendPresence tells the other clients of the room that a client joined or left
+func (r *Room) sendPresence(client *Client, status string) {
+	data, err := NewEnvelope(PresenceEvent, "", r.Name, client.Id, PresencePayload{Status: status})
+	if err != nil {
+		log.Printf("error encoding presence: %v", err)
+		return
+	}
+	r.hub.Publish(Frame{Room: r.Name, Data: data, Except: client.ConnID})
+}
+
+func (r *Room) broadcast(frame Frame) {
+	for client := range r.Clients {
+		if client.ConnID == frame.Except {
+			continue
+		}
+		// A client too slow to keep up is disconnected
+		if !client.trySend(frame.Data) {
+			client.closeSend()
+			delete(r.Clients, client)
 		}
 	}
 }
 
-func NewRoom(name string) *Room {
+func NewRoom(name string, hub *Hub) *Room {
 	return &Room{
 		Name:       name,
+		hub:        hub,
 		Clients:    make(map[*Client]bool),
 		Unregister: make(chan *Client),
 		Register:   make(chan *Client),
-		Broadcast:  make(chan Message),
+		Broadcast:  make(chan Frame),
 	}
 }
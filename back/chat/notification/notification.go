@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"skillly/chat/broadcast"
+)
+
+type EventType string
+
+const (
+	NewMessageEvent       EventType = "new_message"
+	NewMatchEvent         EventType = "new_match"
+	NewApplicationEvent   EventType = "new_application"
+	ApplicationStateEvent EventType = "application_state"
+	InterviewEvent        EventType = "interview"
+	RecruiterRequestEvent EventType = "recruiter_request"
+	RecruiterStateEvent   EventType = "recruiter_state"
+	JobPostDeletedEvent   EventType = "job_post_deleted"
+)
+
+// Event is sent on the global socket (/ws/user/:userId) of the users concerned by it
+// SenderID is the user that triggered the event, the sender never receives its own event
+type Event struct {
+	Type          EventType `json:"type"`
+	SenderID      string    `json:"senderId,omitempty"`
+	RoomID        string    `json:"roomId,omitempty"`
+	Content       string    `json:"content,omitempty"`
+	MatchID       uint      `json:"matchId,omitempty"`
+	ApplicationID uint      `json:"applicationId,omitempty"`
+	JobPostID     uint      `json:"jobPostId,omitempty"`
+	InterviewID   uint      `json:"interviewId,omitempty"`
+	RecruiterID   uint      `json:"recruiterId,omitempty"`
+	CompanyID     uint      `json:"companyId,omitempty"`
+	State         string    `json:"state,omitempty"`
+	Role          string    `json:"role,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Send delivers the event to each user, whatever the instance the user is connected to
+func Send(userIDs []uint, event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("error encoding %s notification: %v", event.Type, err)
+		return
+	}
+
+	sent := map[string]bool{}
+	for _, userID := range userIDs {
+		id := fmt.Sprint(userID)
+		if id == event.SenderID || sent[id] {
+			continue
+		}
+		sent[id] = true
+		broadcast.BroadcastToUser(id, data)
+	}
+}
@@ -0,0 +1,24 @@
+package chat
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// checkOrigin rejects browsers from origins that are not in ALLOWED_ORIGINS (comma separated)
+// native clients don't send an Origin header and are accepted, they are authenticated by their token
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
+	if allowedOrigins == "" {
+		allowedOrigins = "http://localhost:8081"
+	}
+
+	return slices.Contains(strings.Split(allowedOrigins, ","), origin)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// diskStorage writes each file in a directory, the key is the file name
+type diskStorage struct {
+	dir string
+}
+
+func NewDiskStorage(dir string) Storage {
+	return &diskStorage{dir: dir}
+}
+
+func (s *diskStorage) Save(key string, content io.Reader) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path(key), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(s.path(key))
+		return err
+	}
+	return file.Close()
+}
+
+func (s *diskStorage) Open(key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *diskStorage) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path keeps the file inside the directory whatever the key
+func (s *diskStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key))
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+)
+
+var ErrNotFound = errors.New("File not found")
+
+// Storage keeps the content of the uploaded files, the implementation is chosen at startup
+type Storage interface {
+	Save(key string, content io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Default is the storage used by the services
+var Default Storage = NewMemoryStorage()
+
+// SetupStorage writes the files in UPLOAD_DIR
+func SetupStorage() {
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "tmp/uploads"
+	}
+	Default = NewDiskStorage(dir)
+	log.Printf("Storage: writing files to %s", dir)
+}
@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# Télécharge la liste complète des communes de France depuis l'API Découpage administratif
+# et l'écrit au format du gazetteer : name,department,latitude,longitude,postal_codes,
+# les communes les plus peuplées en premier pour départager les homonymes sans département
+OUTPUT="${1:-pkg/geo/communes.csv}"
+
+echo "🔄 Téléchargement des communes..."
+COMMUNES=$(curl -sf "https://geo.api.gouv.fr/communes?fields=nom,codeDepartement,codesPostaux,centre,population&format=json")
+
+if [ $? -ne 0 ] || [ -z "$COMMUNES" ]; then
+    echo "❌ Erreur lors du téléchargement des communes"
+    exit 1
+fi
+
+echo "name,department,latitude,longitude,postal_codes" > "$OUTPUT"
+echo "$COMMUNES" | jq -r 'sort_by(-(.population // 0)) | .[] | select(.centre != null)
+    | [.nom, .codeDepartement, .centre.coordinates[1], .centre.coordinates[0], (.codesPostaux // [] | join(" "))] | @csv' >> "$OUTPUT"
+
+echo "✅ $(($(wc -l < "$OUTPUT") - 1)) communes écrites dans $OUTPUT"
@@ -7,12 +7,17 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"skillly/chat/backplane"
+	"skillly/chat/broadcast"
 	chatDB "skillly/chat/db"
 	chatHandler "skillly/chat/handlers"
 	chatModels "skillly/chat/models"
+	"skillly/chat/storage"
 
 	"skillly/pkg/db"
 	"skillly/pkg/handlers"
+	"skillly/pkg/mailer"
+	"skillly/pkg/scheduler"
 
 	// Swagger imports
 	_ "skillly/docs" // This will be generated by swag init
@@ -48,12 +53,17 @@ func main() {
 	// Init the database
 	db.SetupDB()
 	chatDB.SetupDB()
+	mailer.SetupMailer()
+	storage.SetupStorage()
+	backplane.SetupBackplane()
+	scheduler.SetupScheduler()
 
 	// Create a new gin router
 	r := gin.Default()
-	// Create a new chat hub
+	// Create a new chat hub, the frames of every instance go through the backplane
 	hub := chatModels.NewHub()
 	go hub.RunHub()
+	broadcast.Listen(backplane.Default)
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{"http://localhost:8081"},
@@ -3,10 +3,12 @@ package db
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"os"
 	"skillly/pkg/config"
@@ -36,6 +38,7 @@ func Init(
 	log.Println("Connected to database")
 
 	// MIGRATIONS
+	dedupApplications()
 	config.DB.AutoMigrate(
 		&models.File{},
 		&models.Company{},
@@ -48,8 +51,213 @@ func Init(
 		&models.CandidateReview{},
 		&models.CompanyReview{},
 		&models.Application{},
+		&models.ApplicationStateHistory{},
 		&models.Match{},
+		&models.Swipe{},
+		&models.Interview{},
+		&models.Session{},
+		&models.SessionRefreshToken{},
+		&models.UserToken{},
+		&models.ScheduledJob{},
+		&models.CompanyInvitation{},
+		&models.BackplaneEvent{},
+		&models.DataMigration{},
 	)
+
+	createSearchIndexes()
+	migrateSalaries()
+	geocodeLocations()
+	migrateContractTypes()
+	runOnce("activate_company_admins", activateCompanyAdmins)
+	warnCompaniesWithoutAdmin()
+}
+
+// dedupApplications merges the applications of a candidate to the same job post made before a candidate could apply
+// only once, so that their unique index can be created. The matched or pending application is kept, or the first one,
+// and the matches and the history of the others are moved to it
+func dedupApplications() {
+	if !config.DB.Migrator().HasTable(&models.Application{}) ||
+		config.DB.Migrator().HasIndex(&models.Application{}, "idx_application_candidate_job_post") {
+		return
+	}
+
+	var duplicates []struct {
+		ID     uint
+		KeptID uint
+	}
+	err := config.DB.Raw(`SELECT id, kept_id FROM (
+			SELECT id, FIRST_VALUE(id) OVER (PARTITION BY candidate_id, job_post_id ORDER BY state = ? DESC, state = ? DESC, id) AS kept_id
+			FROM applications
+		) ranked WHERE id <> kept_id`, models.MatchedApplication, models.PendingApplication).
+		Scan(&duplicates).Error
+	if err != nil {
+		log.Printf("error listing the duplicated applications: %v", err)
+		return
+	}
+
+	// The history may not be created yet
+	references := []string{"matches"}
+	if config.DB.Migrator().HasTable(&models.ApplicationStateHistory{}) {
+		references = append(references, "application_state_histories")
+	}
+
+	for _, duplicate := range duplicates {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			for _, table := range references {
+				if err := tx.Exec("UPDATE "+table+" SET application_id = ? WHERE application_id = ?", duplicate.KeptID, duplicate.ID).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Exec("DELETE FROM applications WHERE id = ?", duplicate.ID).Error
+		})
+		if err != nil {
+			log.Printf("error merging the application %d into the application %d: %v", duplicate.ID, duplicate.KeptID, err)
+		}
+	}
+	if len(duplicates) > 0 {
+		log.Printf("Merged %d duplicated applications", len(duplicates))
+	}
+}
+
+// createSearchIndexes indexes the text of the job posts for the full-text search in each language,
+// the expressions must stay the same as the ones searched by the jobPost repository
+func createSearchIndexes() {
+	for _, language := range []string{"french", "english"} {
+		err := config.DB.Exec(fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS idx_job_posts_search_%s ON job_posts USING GIN (to_tsvector('%s', coalesce(title, '') || ' ' || coalesce(description, '')))",
+			language, language,
+		)).Error
+		if err != nil {
+			log.Printf("error creating the %s search index of the job posts: %v", language, err)
+		}
+	}
+}
+
+// migrateSalaries fills the salary amounts of the job posts created with a salary label only,
+// the labels which cannot be parsed are left without amounts
+func migrateSalaries() {
+	var jobPosts []models.JobPost
+	err := config.DB.Where("salary_min IS NULL AND salary_max IS NULL AND salary_range <> ''").Find(&jobPosts).Error
+	if err != nil {
+		log.Printf("error listing the job post salaries to migrate: %v", err)
+		return
+	}
+
+	migrated := 0
+	for _, jobPost := range jobPosts {
+		salary, ok := models.ParseSalary(jobPost.Salary_range)
+		if !ok {
+			continue
+		}
+		err := config.DB.Model(&jobPost).UpdateColumns(map[string]interface{}{
+			"salary_min":      salary.Min,
+			"salary_max":      salary.Max,
+			"salary_currency": salary.Currency,
+			"salary_period":   salary.Period,
+			"salary_basis":    salary.Basis,
+		}).Error
+		if err != nil {
+			log.Printf("error migrating the salary of the job post %d: %v", jobPost.ID, err)
+			continue
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		log.Printf("Migrated the salaries of %d job posts", migrated)
+	}
+}
+
+// geocodeLocations fills the coordinates of the job posts, candidates and companies located before they
+// were geocoded, or with a location which was unknown to a previous gazetteer
+func geocodeLocations() {
+	for _, table := range []string{"job_posts", "profile_candidates", "companies"} {
+		var rows []struct {
+			ID       uint
+			Location string
+		}
+		err := config.DB.Table(table).Select("id, location").Where("latitude IS NULL AND location <> ''").Scan(&rows).Error
+		if err != nil {
+			log.Printf("error listing the %s to geocode: %v", table, err)
+			continue
+		}
+
+		for _, row := range rows {
+			coordinates := models.Locate(row.Location)
+			if coordinates.Latitude == nil {
+				continue
+			}
+			err := config.DB.Table(table).Where("id = ?", row.ID).
+				UpdateColumns(map[string]interface{}{"latitude": coordinates.Latitude, "longitude": coordinates.Longitude}).Error
+			if err != nil {
+				log.Printf("error geocoding the %s %d: %v", table, row.ID, err)
+			}
+		}
+	}
+}
+
+// migrateContractTypes rewrites the contract types written before they were validated, such as "cdi"
+// or "Alternance", the unknown values are left as they are
+func migrateContractTypes() {
+	columns := map[string]string{"job_posts": "contract_type", "profile_candidates": "prefered_contract"}
+	for table, column := range columns {
+		var values []string
+		err := config.DB.Table(table).Distinct(column).Where(column+" NOT IN ? AND "+column+" <> ''", models.ContractTypes).
+			Pluck(column, &values).Error
+		if err != nil {
+			log.Printf("error listing the contract types of the %s: %v", table, err)
+			continue
+		}
+
+		for _, value := range values {
+			contract, ok := models.ParseContractType(value)
+			if !ok {
+				log.Printf("unknown contract type %q in the %s", value, table)
+				continue
+			}
+			if err := config.DB.Table(table).Where(column+" = ?", value).UpdateColumn(column, contract).Error; err != nil {
+				log.Printf("error migrating the contract type %q of the %s: %v", value, table, err)
+			}
+		}
+	}
+}
+
+// runOnce runs a one-off data migration and records it in the same transaction,
+// so that it is skipped at the next startups and by the other instances
+func runOnce(name string, migrate func(tx *gorm.DB) error) {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DataMigration{Name: name, AppliedAt: time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return migrate(tx)
+	})
+	if err != nil {
+		log.Printf("error running the data migration %s: %v", name, err)
+	}
+}
+
+// activateCompanyAdmins approves the admins registered before the join requests existed.
+// The other pending recruiters are left to the admins, a pending requester is never made admin
+func activateCompanyAdmins(tx *gorm.DB) error {
+	return tx.Model(&models.ProfileRecruiter{}).Where("role = ? AND state = ?", models.AdminRole, models.PendingState).
+		UpdateColumn("state", models.ActiveState).Error
+}
+
+// warnCompaniesWithoutAdmin logs the companies left without an active admin, such as when the last admin
+// deleted the account. Nobody can answer their join requests until an operator chooses an admin
+func warnCompaniesWithoutAdmin() {
+	var companyIDs []uint
+	err := config.DB.Model(&models.ProfileRecruiter{}).Distinct("company_id").
+		Where("company_id NOT IN (?)", config.DB.Model(&models.ProfileRecruiter{}).Select("company_id").
+			Where("role = ? AND state = ?", models.AdminRole, models.ActiveState)).
+		Pluck("company_id", &companyIDs).Error
+	if err != nil {
+		log.Printf("error listing the companies without admin: %v", err)
+		return
+	}
+	if len(companyIDs) > 0 {
+		log.Printf("The companies %v have no active admin, an operator must choose one", companyIDs)
+	}
 }
 
 func SetupDB() {
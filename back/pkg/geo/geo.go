@@ -0,0 +1,252 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EarthRadius is the mean radius of the Earth in kilometers
+const EarthRadius = 6371.0
+
+var (
+	ErrUnknownLocation = errors.New("Unknown location")
+	ErrMissingCenter   = errors.New("A location or a latitude and a longitude are required to search in a radius")
+)
+
+// Point is a position in decimal degrees
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Distance is the great-circle distance between two points in kilometers
+func Distance(from Point, to Point) float64 {
+	lat1, lat2 := radians(from.Latitude), radians(to.Latitude)
+	dLat := lat2 - lat1
+	dLng := radians(to.Longitude - from.Longitude)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// DistanceSQL is the great-circle distance in kilometers between the latitude and longitude columns
+// of a table and a point, its arguments are given by DistanceArgs
+func DistanceSQL(table string) string {
+	return fmt.Sprintf("%[2]f * acos(LEAST(1, GREATEST(-1, cos(radians(?)) * cos(radians(%[1]s.latitude)) * cos(radians(%[1]s.longitude) - radians(?)) + sin(radians(?)) * sin(radians(%[1]s.latitude)))))",
+		table, EarthRadius)
+}
+
+// DistanceArgs are the arguments of DistanceSQL
+func (p Point) DistanceArgs() []interface{} {
+	return []interface{}{p.Latitude, p.Longitude, p.Latitude}
+}
+
+// The gazetteer bundled with the API only lists the prefectures and the largest cities of France,
+// generate-communes.sh downloads the complete list of the communes in the same CSV format:
+// name,department,latitude,longitude,postal_codes with a header line, the postal codes being optional
+// and separated by spaces. GAZETTEER_FILE can also point to such a list
+//
+//go:embed communes.csv
+var bundledCommunes string
+
+// commune is a place of the gazetteer
+type commune struct {
+	department  string
+	postalCodes []string
+	point       Point
+}
+
+var (
+	// The communes by name, the homonyms in the order of the gazetteer
+	gazetteer     map[string][]commune
+	gazetteerOnce sync.Once
+)
+
+// Geocode finds the coordinates of a location such as "Lyon", "Saint Etienne", "Paris 15e" or "33000 Bordeaux, France".
+// The homonyms are told apart by a postal code or a department, such as "97400 Saint-Denis" or "Saint-Denis (93)",
+// the first one of the gazetteer is chosen otherwise
+func Geocode(location string) (Point, bool) {
+	gazetteerOnce.Do(loadGazetteer)
+
+	communes := gazetteer[normalizeName(location)]
+	if len(communes) == 0 {
+		return Point{}, false
+	}
+
+	postalCode, department := locationHints(location)
+	if postalCode != "" {
+		for _, homonym := range communes {
+			for _, code := range homonym.postalCodes {
+				if code == postalCode {
+					return homonym.point, true
+				}
+			}
+		}
+	}
+	if department != "" {
+		for _, homonym := range communes {
+			if homonym.department == department {
+				return homonym.point, true
+			}
+		}
+	}
+	return communes[0].point, true
+}
+
+var (
+	postalCodePattern = regexp.MustCompile(`\b(\d{5})\b`)
+	departmentPattern = regexp.MustCompile(`^(\d{2,3}|2[AB])$`)
+)
+
+// locationHints finds the postal code of a location and its department, given by the postal code,
+// between parentheses or after a comma
+func locationHints(location string) (string, string) {
+	location = strings.ToUpper(location)
+
+	if postalCode := postalCodePattern.FindString(location); postalCode != "" {
+		return postalCode, postalCodeDepartment(postalCode)
+	}
+
+	parts := strings.FieldsFunc(location, func(r rune) bool { return r == '(' || r == ')' || r == ',' })
+	for i, part := range parts {
+		if department := strings.TrimSpace(part); i > 0 && departmentPattern.MatchString(department) {
+			return "", department
+		}
+	}
+	return "", ""
+}
+
+// postalCodeDepartment is the department of a postal code, overseas and in Corsica they differ from its first two digits
+func postalCodeDepartment(postalCode string) string {
+	switch {
+	case strings.HasPrefix(postalCode, "97"), strings.HasPrefix(postalCode, "98"):
+		return postalCode[:3]
+	case strings.HasPrefix(postalCode, "200"), strings.HasPrefix(postalCode, "201"):
+		return "2A"
+	case strings.HasPrefix(postalCode, "20"):
+		return "2B"
+	default:
+		return postalCode[:2]
+	}
+}
+
+func loadGazetteer() {
+	var reader io.Reader = strings.NewReader(bundledCommunes)
+	if path := os.Getenv("GAZETTEER_FILE"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Printf("error opening the gazetteer %s, the bundled one is used: %v", path, err)
+		} else {
+			defer file.Close()
+			reader = file
+		}
+	}
+
+	communes, err := readGazetteer(reader)
+	if err != nil {
+		log.Printf("error reading the gazetteer, the bundled one is used: %v", err)
+		communes, _ = readGazetteer(strings.NewReader(bundledCommunes))
+	}
+	gazetteer = communes
+}
+
+func readGazetteer(reader io.Reader) (map[string][]commune, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1 // The postal codes are optional
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	communes := map[string][]commune{}
+	for i, record := range records {
+		if i == 0 {
+			continue // Header
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("line %d: expected name,department,latitude,longitude,postal_codes", i+1)
+		}
+		latitude, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		longitude, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		place := commune{
+			department: strings.ToUpper(strings.TrimSpace(record[1])),
+			point:      Point{Latitude: latitude, Longitude: longitude},
+		}
+		if len(record) > 4 {
+			place.postalCodes = strings.Fields(record[4])
+		}
+		name := normalizeName(record[0])
+		communes[name] = append(communes[name], place)
+	}
+	return communes, nil
+}
+
+var (
+	accents = strings.NewReplacer(
+		"é", "e", "è", "e", "ê", "e", "ë", "e",
+		"à", "a", "â", "a", "ä", "a",
+		"î", "i", "ï", "i",
+		"ô", "o", "ö", "o",
+		"ù", "u", "û", "u", "ü", "u",
+		"ç", "c", "œ", "oe",
+		"-", " ", "'", " ", "’", " ", "(", " ", ")", " ",
+	)
+	// Postal codes, departments, arrondissements such as "15e" or "1er" and the words which are not part of the name
+	ignoredWords  = regexp.MustCompile(`^(\d+(e|eme|er)?|2[ab]|cedex|france)$`)
+	abbreviations = map[string]string{"st": "saint", "ste": "sainte"}
+)
+
+// normalizeName keeps the words of the commune name, before the first comma
+func normalizeName(location string) string {
+	location = strings.Split(location, ",")[0]
+	location = accents.Replace(strings.ToLower(location))
+
+	words := []string{}
+	for _, word := range strings.Fields(location) {
+		if ignoredWords.MatchString(word) {
+			continue
+		}
+		if full, ok := abbreviations[word]; ok {
+			word = full
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Center is the point a radius is searched around, given by its coordinates or by a location to geocode
+func Center(location string, latitude *float64, longitude *float64) (Point, error) {
+	if latitude != nil && longitude != nil {
+		return Point{Latitude: *latitude, Longitude: *longitude}, nil
+	}
+	if location == "" {
+		return Point{}, ErrMissingCenter
+	}
+
+	point, ok := Geocode(location)
+	if !ok {
+		return Point{}, fmt.Errorf("%w: %s", ErrUnknownLocation, location)
+	}
+	return point, nil
+}
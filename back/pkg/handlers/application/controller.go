@@ -8,7 +8,7 @@ import (
 )
 
 // @Summary Créer une candidature
-// @Description Permet à un candidat de postuler à une offre d'emploi
+// @Description Permet à un candidat de postuler à une offre d'emploi, le score de compatibilité et son détail (score_factors) sont calculés par le serveur
 // @Tags applications
 // @Accept json
 // @Produce json
@@ -16,9 +16,11 @@ import (
 // @Param id path int true "ID de l'offre d'emploi"
 // @Param applicationData body applicationDto.CreateApplicationDTO true "Données de la candidature"
 // @Success 201 {object} map[string]interface{} "Candidature créée avec succès"
-// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 400 {object} map[string]string "Erreur de validation, offre expirée ou fermée"
 // @Failure 401 {object} map[string]string "Non autorisé"
 // @Failure 403 {object} map[string]string "Accès refusé - candidats uniquement"
+// @Failure 404 {object} map[string]string "Offre d'emploi non trouvée"
+// @Failure 409 {object} map[string]string "Candidature déjà envoyée pour cette offre"
 // @Router /application/{id} [post]
 func CreateApplicationHandler(c *gin.Context) {
 	applicationService := NewApplicationService()
@@ -57,30 +59,70 @@ func GetMyApplicationsHandler(c *gin.Context) {
 	applicationService.GetMe(c)
 }
 
+// @Summary Récupérer une candidature
+// @Description Récupère une candidature et l'historique de ses statuts, pour son candidat ou les recruteurs de l'entreprise de l'offre
+// @Tags applications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la candidature"
+// @Success 200 {object} models.Application "Candidature avec son historique"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé"
+// @Failure 404 {object} map[string]string "Candidature non trouvée"
+// @Router /application/{id} [get]
+func GetApplicationHandler(c *gin.Context) {
+	applicationService := NewApplicationService()
+	applicationService.GetApplication(c)
+}
+
 // @Summary Mettre à jour le statut d'une candidature
-// @Description Permet à un recruteur de changer le statut d'une candidature (pending, matched, rejected, accepted)
+// @Description Permet à un recruteur de l'entreprise de l'offre de changer le statut d'une candidature. Transitions autorisées : pending vers matched (crée le match), rejected ou closed, matched vers closed
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID de la candidature"
 // @Param stateData body applicationDto.UpdateApplicationStateDTO true "Nouveau statut"
-// @Success 200 {object} map[string]interface{} "Statut mis à jour avec succès"
+// @Success 200 {object} models.Application "Candidature mise à jour"
 // @Failure 400 {object} map[string]string "Erreur de validation"
 // @Failure 401 {object} map[string]string "Non autorisé"
-// @Failure 403 {object} map[string]string "Accès refusé - recruteurs uniquement"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs de l'entreprise uniquement"
 // @Failure 404 {object} map[string]string "Candidature non trouvée"
+// @Failure 409 {object} map[string]string "Transition non autorisée depuis le statut actuel"
 // @Router /application/{id}/state [put]
 func UpdateApplicationStateHandler(c *gin.Context) {
 	applicationService := NewApplicationService()
 	applicationService.UpdateApplicationState(c)
 }
 
+// @Summary Retirer une candidature
+// @Description Permet au candidat de retirer sa candidature en attente ou matchée : elle passe à l'état closed, le match est libéré et les recruteurs sont notifiés
+// @Tags applications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la candidature"
+// @Param withdrawData body applicationDto.WithdrawApplicationDTO false "Raison du retrait"
+// @Success 200 {object} models.Application "Candidature retirée"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - candidat de la candidature uniquement"
+// @Failure 404 {object} map[string]string "Candidature non trouvée"
+// @Failure 409 {object} map[string]string "Candidature déjà refusée ou fermée"
+// @Router /application/{id}/withdraw [post]
+func WithdrawApplicationHandler(c *gin.Context) {
+	applicationService := NewApplicationService()
+	applicationService.WithdrawApplication(c)
+}
+
 func AddRoutes(r *gin.Engine) {
 	app := r.Group("/application")
 
 	app.POST("/:id", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), CreateApplicationHandler)
 	app.GET("/jobpost/:id", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), GetOfferApplicationsHandler)
 	app.GET("/me", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), GetMyApplicationsHandler)
+	app.GET("/:id", middleware.AuthMiddleware(), GetApplicationHandler)
+	app.POST("/:id/withdraw", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), WithdrawApplicationHandler)
 	app.PUT("/:id/state", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), UpdateApplicationStateHandler)
 }
@@ -2,7 +2,6 @@ package applicationDto
 
 type CreateApplicationDTO struct {
 	CoverLetterID *uint `json:"cover_id"`
-	Score         int   `json:"score"`
 
 	// from the url params & the middleware
 	CandidateID uint `json:"candidate_id"`
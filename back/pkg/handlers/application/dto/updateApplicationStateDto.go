@@ -1,6 +1,12 @@
 package applicationDto
 
+import (
+	"skillly/pkg/utils"
+)
+
 // UpdateApplicationStateDTO defines the structure for updating the application state
+// the transitions allowed from the current state are checked by the server
 type UpdateApplicationStateDTO struct {
-	State string `json:"state" binding:"required,oneof=pending matched rejected accepted"` // Ensure state is one of the allowed values
+	State  utils.ApplicationState `json:"state" binding:"required,oneof=matched rejected closed"`
+	Reason string                 `json:"reason" binding:"max=500"`
 }
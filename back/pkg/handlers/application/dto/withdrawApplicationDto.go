@@ -0,0 +1,6 @@
+package applicationDto
+
+// WithdrawApplicationDTO is the optional reason of a candidate withdrawing their application
+type WithdrawApplicationDTO struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
@@ -1,16 +1,29 @@
 package application
 
 import (
+	"errors"
+	"time"
+
 	applicationDto "skillly/pkg/handlers/application/dto"
 	"skillly/pkg/models"
+	"skillly/pkg/scoring"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrAlreadyApplied = errors.New("Already applied to this job post")
+	ErrJobPostExpired = errors.New("Job post expired")
+	ErrJobPostClosed  = errors.New("Job post not open to applications")
+)
+
 type ApplicationRepository interface {
 	models.Repository[models.Application]
 	CreateApplication(dto applicationDto.CreateApplicationDTO, tx *gorm.DB) (models.Application, error)
-	UpdateApplicationState(applicationID uint, state string, tx *gorm.DB) error
+	GetApplicationUserIDs(applicationID uint) (uint, []uint, error)
+	ComputeScore(candidateID uint, jobPostID uint, tx *gorm.DB) (int, []models.ScoreFactor, error)
+	RescoreCandidateApplications(candidateID uint, tx *gorm.DB) error
+	RescoreJobPostApplications(jobPostID uint, tx *gorm.DB) error
 }
 
 type applicationRepository struct {
@@ -25,12 +38,40 @@ func NewApplicationRepository(db *gorm.DB) ApplicationRepository {
 	}
 }
 
+// CreateApplication creates an application scored by the scoring engine,
+// the job post must be published and not expired and the candidate must not have applied to it yet
 func (r *applicationRepository) CreateApplication(dto applicationDto.CreateApplicationDTO, tx *gorm.DB) (models.Application, error) {
+	var jobPost models.JobPost
+	if err := tx.First(&jobPost, dto.JobPostID).Error; err != nil {
+		return models.Application{}, err
+	}
+	if jobPost.State == models.ExpiredJobPost || !jobPost.Expiration_Date.After(time.Now()) {
+		return models.Application{}, ErrJobPostExpired
+	}
+	if !jobPost.IsOpen(time.Now()) {
+		return models.Application{}, ErrJobPostClosed
+	}
+
+	var count int64
+	err := tx.Model(&models.Application{}).Where("candidate_id = ? AND job_post_id = ?", dto.CandidateID, dto.JobPostID).Count(&count).Error
+	if err != nil {
+		return models.Application{}, err
+	}
+	if count > 0 {
+		return models.Application{}, ErrAlreadyApplied
+	}
+
+	score, factors, err := r.ComputeScore(dto.CandidateID, dto.JobPostID, tx)
+	if err != nil {
+		return models.Application{}, err
+	}
+
 	application := models.Application{
 		CoverLetterID: dto.CoverLetterID,
 		CandidateID:   dto.CandidateID,
 		JobPostID:     dto.JobPostID,
-		Score:         dto.Score,
+		Score:         score,
+		ScoreFactors:  factors,
 	}
 
 	createdApplication := tx.Create(&application)
@@ -41,13 +82,79 @@ func (r *applicationRepository) CreateApplication(dto applicationDto.CreateAppli
 	return application, nil
 }
 
-func (r *applicationRepository) UpdateApplicationState(applicationID uint, state string, tx *gorm.DB) error {
-	result := tx.Model(&models.Application{}).Where("id = ?", applicationID).Update("state", state)
+// GetApplicationUserIDs returns the user ID of the candidate and the user IDs of the active recruiters
+// of the company that owns the job post of the application
+func (r *applicationRepository) GetApplicationUserIDs(applicationID uint) (uint, []uint, error) {
+	var candidateIDs []uint
+	result := r.db.Table("applications").
+		Joins("JOIN profile_candidates ON profile_candidates.id = applications.candidate_id").
+		Where("applications.id = ?", applicationID).
+		Pluck("profile_candidates.user_id", &candidateIDs)
 	if result.Error != nil {
-		return result.Error
+		return 0, nil, result.Error
+	}
+	if len(candidateIDs) == 0 {
+		return 0, nil, gorm.ErrRecordNotFound
 	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound // Or a custom error indicating application not found
+
+	var recruiterIDs []uint
+	result = r.db.Table("applications").
+		Joins("JOIN job_posts ON job_posts.id = applications.job_post_id").
+		Joins("JOIN profile_recruiters ON profile_recruiters.company_id = job_posts.company_id").
+		Where("applications.id = ? AND profile_recruiters.state = ?", applicationID, models.ActiveState).
+		Pluck("profile_recruiters.user_id", &recruiterIDs)
+	if result.Error != nil {
+		return 0, nil, result.Error
 	}
+
+	return candidateIDs[0], recruiterIDs, nil
+}
+
+// ComputeScore computes the compatibility score of a candidate for a job post
+func (r *applicationRepository) ComputeScore(candidateID uint, jobPostID uint, tx *gorm.DB) (int, []models.ScoreFactor, error) {
+	var candidate models.ProfileCandidate
+	if err := tx.Preload("Skills").Preload("Certifications").First(&candidate, candidateID).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var jobPost models.JobPost
+	if err := tx.Preload("Skills").Preload("Certifications").First(&jobPost, jobPostID).Error; err != nil {
+		return 0, nil, err
+	}
+
+	score, factors := scoring.Compute(candidate, jobPost)
+	return score, factors, nil
+}
+
+// RescoreCandidateApplications recomputes the scores of the applications of a candidate after a profile change
+func (r *applicationRepository) RescoreCandidateApplications(candidateID uint, tx *gorm.DB) error {
+	return r.rescore(tx.Where("candidate_id = ?", candidateID), tx)
+}
+
+// RescoreJobPostApplications recomputes the scores of the applications to a job post after it changed
+func (r *applicationRepository) RescoreJobPostApplications(jobPostID uint, tx *gorm.DB) error {
+	return r.rescore(tx.Where("job_post_id = ?", jobPostID), tx)
+}
+
+func (r *applicationRepository) rescore(query *gorm.DB, tx *gorm.DB) error {
+	var applications []models.Application
+	if err := query.Find(&applications).Error; err != nil {
+		return err
+	}
+
+	for _, application := range applications {
+		score, factors, err := r.ComputeScore(application.CandidateID, application.JobPostID, tx)
+		if err != nil {
+			return err
+		}
+
+		// Updating from a struct applies the JSON serializer of the factors
+		err = tx.Model(&application).Select("score", "score_factors").
+			Updates(models.Application{Score: score, ScoreFactors: factors}).Error
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
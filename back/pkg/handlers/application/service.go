@@ -1,12 +1,19 @@
 package application
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"skillly/chat/notification"
 	"skillly/pkg/config"
 	applicationDto "skillly/pkg/handlers/application/dto"
-	"skillly/pkg/handlers/jobPost"
+	"skillly/pkg/handlers/applicationState"
+	"skillly/pkg/handlers/match"
 	"skillly/pkg/models"
 	"skillly/pkg/utils"
 )
@@ -15,18 +22,24 @@ type ApplicationService interface {
 	CreateApplication(c *gin.Context)
 	GetMe(c *gin.Context)
 	GetOfferApplications(c *gin.Context)
+	GetApplication(c *gin.Context)
 	UpdateApplicationState(c *gin.Context)
+	WithdrawApplication(c *gin.Context)
 }
 
 type applicationService struct {
-	applicationRepository ApplicationRepository
-	jobPostRepository     jobPost.JobPostRepository
+	applicationRepository      ApplicationRepository
+	applicationStateRepository applicationState.ApplicationStateRepository
+	jobPostRepository          models.Repository[models.JobPost]
+	matchRepository            match.MatchRepository // To match the candidates liked by the recruiters
 }
 
 func NewApplicationService() ApplicationService {
 	return &applicationService{
-		applicationRepository: NewApplicationRepository(config.DB),
-		jobPostRepository:     jobPost.NewJobPostRepository(config.DB),
+		applicationRepository:      NewApplicationRepository(config.DB),
+		applicationStateRepository: applicationState.NewApplicationStateRepository(config.DB),
+		jobPostRepository:          models.NewRepository[models.JobPost](config.DB),
+		matchRepository:            match.NewMatchRepository(config.DB),
 	}
 }
 
@@ -44,14 +57,71 @@ func (s *applicationService) CreateApplication(c *gin.Context) {
 
 	dto.JobPostID = jobPostId
 	dto.CandidateID = candidateId.(uint)
-	application, err := s.applicationRepository.CreateApplication(dto, config.DB)
 
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		c.JSON(500, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+
+	// A recruiter liking the candidate at the same time waits for the application
+	if err := s.matchRepository.LockCandidacy(dto.JobPostID, dto.CandidateID, tx); err != nil {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": "Failed to lock the candidacy: " + err.Error()})
+		return
+	}
+
+	application, err := s.applicationRepository.CreateApplication(dto, tx)
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		tx.Rollback()
+		switch {
+		case errors.Is(err, ErrAlreadyApplied):
+			c.JSON(409, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(404, gin.H{"error": "Job post not found"})
+		default:
+			c.JSON(400, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	c.JSON(200, application)
+	// A recruiter already liked the candidate, the interest is mutual
+	var newMatch *models.Match
+	swipe, err := s.matchRepository.GetSwipe(dto.JobPostID, dto.CandidateID, tx)
+	if err == nil && swipe.Decision == models.LikeSwipe {
+		userId, _ := c.Keys["user_id"].(uint)
+		created, err := s.matchRepository.CreateMatchFromApplication(application.ID, &userId, tx)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(500, gin.H{"error": "Failed to create match: " + err.Error()})
+			return
+		}
+		newMatch = &created
+		application.State = models.MatchedApplication
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	// Notifier les recruteurs de l'entreprise
+	s.notify(application.ID, fmt.Sprint(c.Keys["user_id"]), notification.Event{
+		Type:          notification.NewApplicationEvent,
+		ApplicationID: application.ID,
+		JobPostID:     application.JobPostID,
+		State:         string(application.State),
+	})
+	if newMatch != nil {
+		match.Announce(*newMatch)
+	}
+
+	c.JSON(201, application)
 }
 
 func (s *applicationService) GetMe(c *gin.Context) {
@@ -113,6 +183,51 @@ func (s *applicationService) GetOfferApplications(c *gin.Context) {
 	c.JSON(200, applications)
 }
 
+// GetApplication returns an application with its state history, to its candidate and to the recruiters of its company
+func (s *applicationService) GetApplication(c *gin.Context) {
+	applicationID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	application, err := s.applicationRepository.GetByID(applicationID, &[]string{
+		"JobPost.Company", "JobPost.Skills", "JobPost.Certifications",
+		"Candidate.User", "Candidate.Skills", "Candidate.Certifications",
+	})
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Application not found"})
+		return
+	}
+
+	switch c.Keys["user_role"] {
+	case string(models.RoleCandidate):
+		candidateId := c.Keys["candidate_id"]
+		if application.CandidateID != candidateId.(uint) {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
+	case string(models.RoleRecruiter):
+		companyId := c.Keys["company_id"]
+		if application.JobPost.CompanyID != companyId.(uint) {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
+	default:
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	application.History, err = s.applicationStateRepository.GetHistory(application.ID, config.DB.Preload("Actor"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to get the application history: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, application)
+}
+
+// UpdateApplicationState moves an application of the recruiter's company to a new state,
+// moving it to matched creates the match like a mutual interest, closing or rejecting it releases the match
 func (s *applicationService) UpdateApplicationState(c *gin.Context) {
 	applicationID, err := utils.GetId(c)
 	if err != nil {
@@ -126,26 +241,114 @@ func (s *applicationService) UpdateApplicationState(c *gin.Context) {
 		return
 	}
 
-	// TODO: Add authorization check: Ensure the recruiter making the request
-	// has the right to modify this specific application (e.g., by checking
-	// if the application belongs to a job post owned by their company).
-	// This might involve fetching the application and checking its job_post relationship.
+	application, err := s.applicationRepository.GetByID(applicationID, &[]string{"JobPost"})
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Application not found"})
+		return
+	}
+	companyId := c.Keys["company_id"]
+	if application.JobPost.CompanyID != companyId.(uint) {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return
+	}
 
-	// Using a transaction, although potentially overkill if it's the only operation.
 	tx := config.DB.Begin()
 	if tx.Error != nil {
 		c.JSON(500, gin.H{"error": "Failed to start transaction"})
 		return
 	}
 
-	err = s.applicationRepository.UpdateApplicationState(applicationID, dto.State, tx)
+	userId, _ := c.Keys["user_id"].(uint)
+	var newMatch *models.Match
+	if dto.State == models.MatchedApplication {
+		var created models.Match
+		created, err = s.matchRepository.CreateMatchFromApplication(applicationID, &userId, tx)
+		newMatch = &created
+		application = created.Application
+	} else {
+		application, err = s.applicationStateRepository.Transition(applicationID, dto.State, &userId, dto.Reason, tx)
+	}
 	if err != nil {
 		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "Application not found"})
-		} else {
-			c.JSON(500, gin.H{"error": "Failed to update application state: " + err.Error()})
+		s.transitionError(c, err)
+		return
+	}
+
+	// A closed or rejected application releases its match, like a withdrawal
+	if dto.State == models.ClosedApplication || dto.State == models.RejectedApplication {
+		if err := s.matchRepository.DeleteApplicationMatches(applicationID, tx); err != nil {
+			tx.Rollback()
+			c.JSON(500, gin.H{"error": "Failed to release the match: " + err.Error()})
+			return
 		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	if newMatch != nil {
+		match.Announce(*newMatch)
+	} else {
+		// Notifier le candidat et les autres recruteurs
+		s.notify(applicationID, fmt.Sprint(c.Keys["user_id"]), notification.Event{
+			Type:          notification.ApplicationStateEvent,
+			ApplicationID: applicationID,
+			JobPostID:     application.JobPostID,
+			State:         string(dto.State),
+		})
+	}
+
+	c.JSON(200, application)
+}
+
+// WithdrawApplication lets a candidate close their application, the match of the application is released
+func (s *applicationService) WithdrawApplication(c *gin.Context) {
+	applicationID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	// The reason is optional
+	dto := applicationDto.WithdrawApplicationDTO{}
+	if err := c.ShouldBindJSON(&dto); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if dto.Reason == "" {
+		dto.Reason = "Withdrawn by the candidate"
+	}
+
+	application, err := s.applicationRepository.GetByID(applicationID, nil)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Application not found"})
+		return
+	}
+	candidateId := c.Keys["candidate_id"]
+	if application.CandidateID != candidateId.(uint) {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		c.JSON(500, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+
+	userId, _ := c.Keys["user_id"].(uint)
+	application, err = s.applicationStateRepository.Transition(applicationID, models.ClosedApplication, &userId, dto.Reason, tx)
+	if err != nil {
+		tx.Rollback()
+		s.transitionError(c, err)
+		return
+	}
+
+	if err := s.matchRepository.DeleteApplicationMatches(applicationID, tx); err != nil {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": "Failed to release the match: " + err.Error()})
 		return
 	}
 
@@ -155,5 +358,43 @@ func (s *applicationService) UpdateApplicationState(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Application state updated successfully"})
+	// Notifier les recruteurs de l'entreprise
+	s.notify(applicationID, fmt.Sprint(c.Keys["user_id"]), notification.Event{
+		Type:          notification.ApplicationStateEvent,
+		ApplicationID: applicationID,
+		JobPostID:     application.JobPostID,
+		State:         string(models.ClosedApplication),
+	})
+
+	c.JSON(200, application)
+}
+
+// transitionError answers the error of a transition
+func (s *applicationService) transitionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, applicationState.ErrInvalidTransition):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(404, gin.H{"error": "Application not found"})
+	default:
+		c.JSON(500, gin.H{"error": "Failed to update application state: " + err.Error()})
+	}
+}
+
+// notify sends an application event to the users concerned, except the user who triggered it
+// a new application concerns the recruiters only, a state change the candidate too
+func (s *applicationService) notify(applicationID uint, senderID string, event notification.Event) {
+	candidateUserID, recruiterUserIDs, err := s.applicationRepository.GetApplicationUserIDs(applicationID)
+	if err != nil {
+		log.Printf("error getting the users of application %d: %v", applicationID, err)
+		return
+	}
+
+	userIDs := recruiterUserIDs
+	if event.Type != notification.NewApplicationEvent {
+		userIDs = append(userIDs, candidateUserID)
+	}
+
+	event.SenderID = senderID
+	notification.Send(userIDs, event)
 }
@@ -0,0 +1,74 @@
+package applicationState
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+)
+
+var ErrInvalidTransition = errors.New("Invalid application state transition")
+
+// ApplicationStateRepository moves the applications through their states and keeps their history
+type ApplicationStateRepository interface {
+	models.Repository[models.ApplicationStateHistory]
+	Transition(applicationID uint, state utils.ApplicationState, actorID *uint, reason string, tx *gorm.DB) (models.Application, error)
+	GetHistory(applicationID uint, tx *gorm.DB) ([]models.ApplicationStateHistory, error)
+}
+
+type applicationStateRepository struct {
+	models.Repository[models.ApplicationStateHistory]
+	db *gorm.DB
+}
+
+func NewApplicationStateRepository(db *gorm.DB) ApplicationStateRepository {
+	return &applicationStateRepository{
+		Repository: models.NewRepository[models.ApplicationStateHistory](db),
+		db:         db,
+	}
+}
+
+// Transition moves the application to a new state if it is allowed from its current state,
+// the application is locked until the end of the transaction
+func (r *applicationStateRepository) Transition(applicationID uint, state utils.ApplicationState, actorID *uint, reason string, tx *gorm.DB) (models.Application, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&application, applicationID).Error; err != nil {
+		return models.Application{}, err
+	}
+
+	if !models.CanTransitionApplication(application.State, state) {
+		return models.Application{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, application.State, state)
+	}
+
+	history := models.ApplicationStateHistory{
+		ApplicationID: application.ID,
+		FromState:     application.State,
+		ToState:       state,
+		ActorID:       actorID,
+		Reason:        reason,
+	}
+	if err := tx.Model(&application).Update("state", state).Error; err != nil {
+		return models.Application{}, err
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return models.Application{}, err
+	}
+	application.State = state
+
+	return application, nil
+}
+
+// GetHistory returns the transitions of an application from the oldest to the newest
+func (r *applicationStateRepository) GetHistory(applicationID uint, tx *gorm.DB) ([]models.ApplicationStateHistory, error) {
+	var history []models.ApplicationStateHistory
+	err := tx.Where("application_id = ?", applicationID).Order("created_at, id").Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
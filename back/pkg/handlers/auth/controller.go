@@ -65,6 +65,103 @@ func GetCurrentUserHandler(c *gin.Context) {
 	authService.GetCurrentUser(c)
 }
 
+// @Summary Rafraîchir le token
+// @Description Échange un refresh token contre un nouveau token d'accès, le refresh token est renouvelé. Un ancien refresh token présenté de nouveau révoque toute la session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refreshData body authDto.RefreshTokenDTO true "Refresh token"
+// @Success 200 {object} map[string]string "Nouveau token d'accès et nouveau refresh token"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 401 {object} map[string]string "Refresh token invalide, expiré ou révoqué"
+// @Router /auth/refresh [post]
+func RefreshTokenHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.RefreshToken(c)
+}
+
+// @Summary Déconnexion
+// @Description Révoque la session courante
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Session révoquée"
+// @Failure 401 {object} map[string]string "Token manquant ou invalide"
+// @Router /auth/logout [post]
+func LogoutHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.Logout(c)
+}
+
+// @Summary Déconnexion de tous les appareils
+// @Description Révoque toutes les sessions de l'utilisateur connecté
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Sessions révoquées"
+// @Failure 401 {object} map[string]string "Token manquant ou invalide"
+// @Router /auth/logout/all [post]
+func LogoutAllHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.LogoutAll(c)
+}
+
+// @Summary Mot de passe oublié
+// @Description Envoie un lien de réinitialisation du mot de passe si un compte existe pour cet email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param forgotData body authDto.ForgotPasswordDTO true "Email du compte"
+// @Success 200 {object} map[string]string "Demande prise en compte"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Router /auth/forgot-password [post]
+func ForgotPasswordHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.ForgotPassword(c)
+}
+
+// @Summary Réinitialiser le mot de passe
+// @Description Définit un nouveau mot de passe avec le token reçu par email, toutes les sessions sont révoquées
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param resetData body authDto.ResetPasswordDTO true "Token et nouveau mot de passe"
+// @Success 200 {object} map[string]string "Mot de passe mis à jour"
+// @Failure 400 {object} map[string]string "Token invalide ou mot de passe trop faible"
+// @Router /auth/reset-password [post]
+func ResetPasswordHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.ResetPassword(c)
+}
+
+// @Summary Vérifier l'email
+// @Description Confirme l'adresse email avec le token reçu par email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verifyData body authDto.VerifyEmailDTO true "Token de vérification"
+// @Success 200 {object} map[string]string "Email vérifié"
+// @Failure 400 {object} map[string]string "Token invalide ou expiré"
+// @Router /auth/verify-email [post]
+func VerifyEmailHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.VerifyEmail(c)
+}
+
+// @Summary Renvoyer l'email de vérification
+// @Description Envoie un nouveau lien de vérification à l'utilisateur connecté
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Email envoyé"
+// @Failure 400 {object} map[string]string "Email déjà vérifié"
+// @Failure 401 {object} map[string]string "Token manquant ou invalide"
+// @Router /auth/verify-email/resend [post]
+func ResendVerificationEmailHandler(c *gin.Context) {
+	authService := NewAuthService()
+	authService.ResendVerificationEmail(c)
+}
+
 func AddRoutes(r *gin.Engine) {
 	au := r.Group("/auth")
 
@@ -72,4 +169,11 @@ func AddRoutes(r *gin.Engine) {
 	au.POST("/signup/candidate", RegisterCandidateHandler)
 	au.POST("/signup/recruiter", RegisterRecruiterHandler)
 	au.GET("/me", authMiddleware.AuthMiddleware(), GetCurrentUserHandler)
+	au.POST("/refresh", RefreshTokenHandler)
+	au.POST("/logout", authMiddleware.AuthMiddleware(), LogoutHandler)
+	au.POST("/logout/all", authMiddleware.AuthMiddleware(), LogoutAllHandler)
+	au.POST("/forgot-password", ForgotPasswordHandler)
+	au.POST("/reset-password", ResetPasswordHandler)
+	au.POST("/verify-email", VerifyEmailHandler)
+	au.POST("/verify-email/resend", authMiddleware.AuthMiddleware(), ResendVerificationEmailHandler)
 }
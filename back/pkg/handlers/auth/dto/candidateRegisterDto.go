@@ -1,6 +1,7 @@
 package authDto
 
 import (
+	"skillly/pkg/models"
 	"skillly/pkg/utils"
 )
 
@@ -11,11 +12,13 @@ type CandidateRegisterDTO struct {
 	Password         string             `json:"password" binding:"required"`
 	Bio              string             `json:"bio"`
 	ExperienceYear   int                `json:"experienceYears"`
-	PreferedContract utils.ContractType `json:"preferedContract"`
+	PreferedContract utils.ContractType `json:"preferedContract" binding:"omitempty,contract_type"`
 	PreferedJob      string             `json:"preferedJob"`
+	PreferedWorkMode utils.WorkMode     `json:"preferedWorkMode" binding:"omitempty,oneof=onsite hybrid remote"`
 	Location         string             `json:"location"`
 	Availability     string             `json:"availability"`
 	ResumeID         uint               `json:"resumeID"`
+	ExpectedSalary   models.Salary      `json:"expectedSalary"`
 
 	Certifications []uint `json:"certifications"`
 	Skills         []uint `json:"skills"`
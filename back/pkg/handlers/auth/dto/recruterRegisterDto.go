@@ -10,5 +10,7 @@ type RecruterRegisterDTO struct {
 	Title     string `json:"title"`
 	Company   uint   `json:"company"`
 
+	Invitation string `json:"invitation"` // Token of the invitation sent by an admin of the company
+
 	NewCompany *companyDto.CreateCompanyDTO `json:"newCompany"`
 }
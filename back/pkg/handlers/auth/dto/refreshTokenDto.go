@@ -0,0 +1,5 @@
+package authDto
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"skillly/pkg/mailer"
+	"skillly/pkg/models"
+)
+
+const PasswordResetTTL = time.Hour
+
+// appLink builds a link to a page of the front application
+func appLink(path string, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", os.Getenv("APP_URL"), path, token)
+}
+
+// sendMail logs the error instead of failing the request, the user can ask for a new mail
+func sendMail(mail mailer.Mail) {
+	if err := mailer.Default.Send(mail); err != nil {
+		log.Printf("error sending mail to %s: %v", mail.To, err)
+	}
+}
+
+// sendPasswordResetEmail creates a password reset token and sends it to the user
+func (s *authService) sendPasswordResetEmail(user models.User, tx *gorm.DB) error {
+	token, err := s.userTokenRepository.CreateToken(user.ID, models.PasswordResetPurpose, PasswordResetTTL, tx)
+	if err != nil {
+		return err
+	}
+
+	sendMail(mailer.Mail{
+		To:      user.Email,
+		Subject: "Skillly - Réinitialisation de votre mot de passe",
+		Body: fmt.Sprintf(
+			"Bonjour %s,\n\nPour choisir un nouveau mot de passe, ouvrez ce lien :\n%s\n\nCe lien expire dans 1 heure. Si vous n'êtes pas à l'origine de cette demande, ignorez ce message.",
+			user.FirstName, appLink("reset-password", token),
+		),
+	})
+	return nil
+}
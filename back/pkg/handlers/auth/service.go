@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"time"
 
@@ -15,39 +16,99 @@ import (
 	candidate "skillly/pkg/handlers/candidateProfile"
 	candidateDto "skillly/pkg/handlers/candidateProfile/dto"
 	"skillly/pkg/handlers/company"
+	"skillly/pkg/handlers/companyInvitation"
 	recruiter "skillly/pkg/handlers/recruiterProfile"
 	recruiterDto "skillly/pkg/handlers/recruiterProfile/dto"
+	"skillly/pkg/handlers/session"
 	"skillly/pkg/handlers/user"
 	userDto "skillly/pkg/handlers/user/dto"
+	"skillly/pkg/handlers/userToken"
 	"skillly/pkg/models"
 	"skillly/pkg/utils"
 )
 
+// Lifetime of an access token, a new one is obtained with the refresh token
+const AccessTokenTTL = 15 * time.Minute
+
 type AuthService interface {
 	RegisterCandidate(c *gin.Context)
 	RegisterRecruiter(c *gin.Context)
 	Login(c *gin.Context)
 	GetCurrentUser(c *gin.Context)
+	RefreshToken(c *gin.Context)
+	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerificationEmail(c *gin.Context)
 }
 
 type authService struct {
-	userRepository      user.UserRepository
-	companyRepository   company.CompanyRepository
-	recruiterRepository recruiter.RecruiterRepository
-	candidateRepository candidate.CandidateRepository
+	userRepository       user.UserRepository
+	companyRepository    company.CompanyRepository
+	recruiterRepository  recruiter.RecruiterRepository
+	candidateRepository  candidate.CandidateRepository
+	sessionRepository    session.SessionRepository
+	userTokenRepository  userToken.UserTokenRepository
+	invitationRepository companyInvitation.CompanyInvitationRepository
 }
 
 func NewAuthService() AuthService {
 	return &authService{
-		userRepository:      user.NewUserRepository(config.DB),
-		companyRepository:   company.NewCompanyRepository(config.DB),
-		recruiterRepository: recruiter.NewRecruiterRepository(config.DB),
-		candidateRepository: candidate.NewCandidateRepository(config.DB),
+		userRepository:       user.NewUserRepository(config.DB),
+		companyRepository:    company.NewCompanyRepository(config.DB),
+		recruiterRepository:  recruiter.NewRecruiterRepository(config.DB),
+		candidateRepository:  candidate.NewCandidateRepository(config.DB),
+		sessionRepository:    session.NewSessionRepository(config.DB),
+		userTokenRepository:  userToken.NewUserTokenRepository(config.DB),
+		invitationRepository: companyInvitation.NewCompanyInvitationRepository(config.DB),
+	}
+}
+
+// generateAccessToken signs a short-lived access token bound to a session
+func generateAccessToken(user models.User, sessionID uint) (string, error) {
+	claims := jwt.MapClaims{
+		"email":     user.Email,
+		"role":      user.Role,
+		"id":        user.ID,
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+		"sid":       sessionID,
+		"exp":       time.Now().Add(AccessTokenTTL).Unix(),
+	}
+
+	if user.Role == models.RoleRecruiter && user.ProfileRecruiter != nil {
+		claims["companyID"] = user.ProfileRecruiter.CompanyID
+		claims["companyRole"] = user.ProfileRecruiter.Role
+		claims["recruiterID"] = user.ProfileRecruiter.ID
+	} else if user.ProfileCandidate != nil {
+		claims["candidateID"] = user.ProfileCandidate.ID
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// openSession creates a new session for the user and returns the access and refresh tokens
+func (s *authService) openSession(c *gin.Context, user models.User, tx *gorm.DB) (string, string, error) {
+	newSession, refreshToken, err := s.sessionRepository.CreateSession(user.ID, c.Request.UserAgent(), tx)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := generateAccessToken(user, newSession.ID)
+	if err != nil {
+		return "", "", err
 	}
+
+	return accessToken, refreshToken, nil
 }
 
 // RegisterCandidate is a handler that creates a new candidate and user
 func (s *authService) RegisterCandidate(c *gin.Context) {
+	var verifiedUser models.User
+	var verificationToken string
 	err := config.DB.Transaction(func(tx *gorm.DB) error {
 		candidateRegister := authDto.CandidateRegisterDTO{}
 		err := c.BindJSON(&candidateRegister)
@@ -81,8 +142,10 @@ func (s *authService) RegisterCandidate(c *gin.Context) {
 			ExperienceYear:   candidateRegister.ExperienceYear,
 			PreferedContract: candidateRegister.PreferedContract,
 			PreferedJob:      candidateRegister.PreferedJob,
+			PreferedWorkMode: candidateRegister.PreferedWorkMode,
 			Availability:     candidateRegister.Availability,
 			ResumeID:         candidateRegister.ResumeID,
+			ExpectedSalary:   candidateRegister.ExpectedSalary,
 			Certifications:   candidateRegister.Certifications,
 			Skills:           candidateRegister.Skills,
 			User:             savedUser,
@@ -95,25 +158,24 @@ func (s *authService) RegisterCandidate(c *gin.Context) {
 			return err
 		}
 
-		// Create the token
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-			"email":       savedUser.Email,
-			"role":        savedUser.Role,
-			"id":          savedUser.ID,
-			"firstName":   savedUser.FirstName,
-			"lastName":    savedUser.LastName,
-			"candidateID": candidateProfile.ID,
-			"exp":         time.Now().Add(24 * time.Hour).Unix(),
-		})
+		// The verification email is sent once the user is committed
+		verificationToken, err = s.userTokenRepository.CreateToken(savedUser.ID, models.EmailVerificationPurpose, user.EmailVerificationTTL, tx)
+		if err != nil {
+			return err
+		}
+		verifiedUser = savedUser
 
-		tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+		// Create the session & tokens
+		savedUser.ProfileCandidate = &candidateProfile
+		tokenString, refreshToken, err := s.openSession(c, savedUser, tx)
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			return err
 		}
 
 		c.JSON(200, gin.H{
-			"user":  savedUser,
-			"token": tokenString,
+			"user":         savedUser,
+			"token":        tokenString,
+			"refreshToken": refreshToken,
 		})
 
 		return nil
@@ -122,10 +184,18 @@ func (s *authService) RegisterCandidate(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+
+	user.SendVerificationEmail(verifiedUser, verificationToken)
 }
 
-// RegisterRecruiter is a handler that creates a new recruiter and user
+// RegisterRecruiter is a handler that creates a new recruiter and user,
+// the recruiter creating a company is its admin, the invited one joins the company with the role of the invitation
+// and the one joining a company without invitation waits for the approval of its admins
 func (s *authService) RegisterRecruiter(c *gin.Context) {
+	var joinRequest *models.ProfileRecruiter
+	var joiningUser models.User
+	var verifiedUser models.User
+	var verificationToken string
 	err := config.DB.Transaction(func(tx *gorm.DB) error {
 		recruiterRegister := authDto.RecruterRegisterDTO{}
 		err := c.BindJSON(&recruiterRegister)
@@ -156,6 +226,7 @@ func (s *authService) RegisterRecruiter(c *gin.Context) {
 		newRecruiter := recruiterDto.CreateRecruiterDTO{
 			Title:     recruiterRegister.Title,
 			CompanyID: recruiterRegister.Company,
+			State:     models.PendingState,
 			User:      savedUser,
 		}
 
@@ -169,6 +240,19 @@ func (s *authService) RegisterRecruiter(c *gin.Context) {
 
 			newRecruiter.CompanyID = savedCompany.ID
 			newRecruiter.Role = models.AdminRole
+			newRecruiter.State = models.ActiveState
+		} else if recruiterRegister.Invitation != "" {
+			// The invited recruiter joins the company with the role chosen by the admin
+			invitation, err := s.invitationRepository.AcceptInvitation(recruiterRegister.Invitation, recruiterRegister.Email, tx)
+			if err != nil {
+				return err
+			}
+
+			newRecruiter.CompanyID = invitation.CompanyID
+			newRecruiter.Role = invitation.Role
+			newRecruiter.State = models.ActiveState
+		} else if _, err := s.companyRepository.GetByID(recruiterRegister.Company, nil); err != nil {
+			return fmt.Errorf("Company not found: %w", err)
 		}
 
 		// Create the recruiter
@@ -178,38 +262,48 @@ func (s *authService) RegisterRecruiter(c *gin.Context) {
 			return err
 		}
 
-		// Create the token
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-			"email":       savedUser.Email,
-			"role":        savedUser.Role,
-			"id":          savedUser.ID,
-			"firstName":   savedUser.FirstName,
-			"lastName":    savedUser.LastName,
-			"companyID":   recruiterProfile.CompanyID,
-			"companyRole": recruiterProfile.Role,
-			"recruiterID": recruiterProfile.ID,
-			"exp":         time.Now().Add(24 * time.Hour).Unix(),
-		})
-
-		// Sign the token with a secret key
-		tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+		// The verification email is sent once the user is committed
+		verificationToken, err = s.userTokenRepository.CreateToken(savedUser.ID, models.EmailVerificationPurpose, user.EmailVerificationTTL, tx)
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			return err
+		}
+		verifiedUser = savedUser
 
+		if recruiterProfile.State != models.ActiveState {
+			joinRequest = &recruiterProfile
+			joiningUser = savedUser
+		}
+
+		// Create the session & tokens
+		savedUser.ProfileRecruiter = &recruiterProfile
+		tokenString, refreshToken, err := s.openSession(c, savedUser, tx)
+		if err != nil {
+			return err
 		}
 
 		c.JSON(200, gin.H{
-			"user":  savedUser,
-			"token": tokenString,
+			"user":         savedUser,
+			"token":        tokenString,
+			"refreshToken": refreshToken,
 		})
 
 		return nil
 	})
 
+	if errors.Is(err, companyInvitation.ErrInvalidInvitation) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+
+	user.SendVerificationEmail(verifiedUser, verificationToken)
+
+	if joinRequest != nil {
+		company.NotifyJoinRequest(*joinRequest, joiningUser)
+	}
 }
 
 func (s *authService) Login(c *gin.Context) {
@@ -242,33 +336,17 @@ func (s *authService) Login(c *gin.Context) {
 		return
 	}
 
-	// Create the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"email":     user.Email,
-		"role":      user.Role,
-		"id":        user.ID,
-		"firstName": user.FirstName,
-		"lastName":  user.LastName,
-		"exp":       time.Now().Add(24 * time.Hour).Unix(),
-	})
-
-	if user.Role == models.RoleRecruiter {
-		token.Claims.(jwt.MapClaims)["companyID"] = user.ProfileRecruiter.CompanyID
-		token.Claims.(jwt.MapClaims)["authRole"] = user.ProfileRecruiter.Role
-		token.Claims.(jwt.MapClaims)["recruiterID"] = user.ProfileRecruiter.ID
-	} else {
-		token.Claims.(jwt.MapClaims)["candidateID"] = user.ProfileCandidate.ID
-	}
-
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	// Create the session & tokens
+	tokenString, refreshToken, err := s.openSession(c, user, config.DB)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"user":  user,
-		"token": tokenString,
+		"user":         user,
+		"token":        tokenString,
+		"refreshToken": refreshToken,
 	})
 }
 
@@ -296,3 +374,205 @@ func (s *authService) GetCurrentUser(c *gin.Context) {
 
 	c.JSON(200, currentUser)
 }
+
+// RefreshToken exchanges a refresh token for a new access token and rotates the refresh token
+func (s *authService) RefreshToken(c *gin.Context) {
+	dto := authDto.RefreshTokenDTO{}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentSession, err := s.sessionRepository.GetByRefreshToken(dto.RefreshToken)
+	if errors.Is(err, session.ErrRefreshTokenReused) {
+		s.revokeReusedSession(c, currentSession)
+		return
+	}
+	if err != nil || !currentSession.IsActive() {
+		c.JSON(401, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	currentUser, err := s.userRepository.GetByID(currentSession.UserID, &[]string{"ProfileCandidate", "ProfileRecruiter"})
+	if err != nil {
+		c.JSON(401, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	refreshToken, err := s.sessionRepository.RotateRefreshToken(&currentSession)
+	if errors.Is(err, session.ErrRefreshTokenReused) {
+		s.revokeReusedSession(c, currentSession)
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokenString, err := generateAccessToken(currentUser, currentSession.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	})
+}
+
+// revokeReusedSession answers a refresh token used twice, it may have been stolen so the whole session is revoked
+// and neither the thief nor the user can refresh it anymore
+func (s *authService) revokeReusedSession(c *gin.Context, reused models.Session) {
+	if err := s.sessionRepository.RevokeSession(reused.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(401, gin.H{"error": "Invalid refresh token"})
+}
+
+// Logout revokes the session of the current access token
+func (s *authService) Logout(c *gin.Context) {
+	sessionID := c.Keys["session_id"]
+
+	if err := s.sessionRepository.RevokeSession(sessionID.(uint)); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every session of the current user
+func (s *authService) LogoutAll(c *gin.Context) {
+	userID := c.Keys["user_id"]
+
+	if err := s.sessionRepository.RevokeUserSessions(userID.(uint), config.DB); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Logged out from all devices"})
+}
+
+// ForgotPassword sends a password reset link, the response doesn't tell if the email exists
+func (s *authService) ForgotPassword(c *gin.Context) {
+	dto := authDto.ForgotPasswordDTO{}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.userRepository.GetByEmail(dto.Email)
+	if err == nil {
+		if err := s.sendPasswordResetEmail(user, config.DB); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "If an account exists for this email, a reset link has been sent"})
+}
+
+// ResetPassword sets a new password with a reset token and revokes every session of the user
+func (s *authService) ResetPassword(c *gin.Context) {
+	dto := authDto.ResetPasswordDTO{}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := utils.ValidatePassword(dto.Password); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(dto.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		resetToken, err := s.userTokenRepository.ConsumeToken(dto.Token, models.PasswordResetPurpose, tx)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Update("password", string(hashedPassword)).Error
+		if err != nil {
+			return err
+		}
+
+		return s.sessionRepository.RevokeUserSessions(resetToken.UserID, tx)
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(400, gin.H{"error": "Invalid or expired token"})
+		} else {
+			c.JSON(500, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Password updated successfully"})
+}
+
+// VerifyEmail confirms the email of the user with a verification token
+func (s *authService) VerifyEmail(c *gin.Context) {
+	dto := authDto.VerifyEmailDTO{}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		verificationToken, err := s.userTokenRepository.ConsumeToken(dto.Token, models.EmailVerificationPurpose, tx)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", verificationToken.UserID).Update("email_verified_at", time.Now()).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(400, gin.H{"error": "Invalid or expired token"})
+		} else {
+			c.JSON(500, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerificationEmail sends a new verification link to the current user
+func (s *authService) ResendVerificationEmail(c *gin.Context) {
+	userID := c.Keys["user_id"]
+
+	currentUser, err := s.userRepository.GetByID(userID.(uint), nil)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "User not found"})
+		return
+	}
+
+	if currentUser.EmailVerifiedAt != nil {
+		c.JSON(400, gin.H{"error": "Email already verified"})
+		return
+	}
+
+	token, err := s.userTokenRepository.CreateToken(currentUser.ID, models.EmailVerificationPurpose, user.EmailVerificationTTL, config.DB)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	user.SendVerificationEmail(currentUser, token)
+
+	c.JSON(200, gin.H{"message": "Verification email sent"})
+}
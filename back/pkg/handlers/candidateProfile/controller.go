@@ -0,0 +1,46 @@
+package candidate
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"skillly/pkg/middleware"
+	"skillly/pkg/models"
+)
+
+// @Summary Rechercher des candidats
+// @Description Recherche parmi les candidats visibles (recruteurs uniquement). Les compétences et certifications sont cumulées (all) ou alternatives (any). Avec job_post_id, les candidats sont triés par score de compatibilité avec l'offre
+// @Tags candidates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param skills query []int false "IDs des compétences"
+// @Param skills_mode query string false "all (par défaut) ou any"
+// @Param certifications query []int false "IDs des certifications"
+// @Param certifications_mode query string false "all (par défaut) ou any"
+// @Param min_experience query int false "Années d'expérience minimum"
+// @Param contract query string false "Contrat préféré (CDI, CDD, STAGE, ALTERNANCE, FREELANCE, INTERIM)"
+// @Param location query string false "Localisation"
+// @Param near query string false "Ville au centre du rayon (l'offre sélectionnée par défaut)"
+// @Param lat query number false "Latitude du centre du rayon"
+// @Param lng query number false "Longitude du centre du rayon"
+// @Param radius_km query number false "Rayon de recherche en kilomètres, les candidats préférant le télétravail sont toujours inclus"
+// @Param work_mode query []string false "Modes de travail préférés : onsite, hybrid, remote (les candidats sans préférence sont inclus)" collectionFormat(multi)
+// @Param availability query string false "Disponibilité"
+// @Param job_post_id query int false "ID de l'offre pour trier par compatibilité"
+// @Param page query int false "Numéro de page"
+// @Param limit query int false "Nombre de candidats par page (20 par défaut, 50 au maximum)"
+// @Success 200 {object} models.CandidateSearchPage "Candidats trouvés"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs uniquement"
+// @Failure 404 {object} map[string]string "Offre d'emploi non trouvée"
+// @Router /candidate/search [get]
+func SearchCandidatesHandler(c *gin.Context) {
+	candidateService := NewCandidateService()
+	candidateService.SearchCandidates(c)
+}
+
+func AddRoutes(r *gin.Engine) {
+	cd := r.Group("/candidate")
+	cd.GET("/search", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), SearchCandidatesHandler)
+}
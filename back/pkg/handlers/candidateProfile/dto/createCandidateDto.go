@@ -9,10 +9,12 @@ type CreateCandidateDTO struct {
 	Bio              string             `json:"bio"`
 	Location         string             `json:"location"`
 	ExperienceYear   int                `json:"experience_year"`
-	PreferedContract utils.ContractType `json:"prefered_contract"`
+	PreferedContract utils.ContractType `json:"prefered_contract" binding:"omitempty,contract_type"`
 	PreferedJob      string             `json:"prefered_job"`
+	PreferedWorkMode utils.WorkMode     `json:"prefered_work_mode" binding:"omitempty,oneof=onsite hybrid remote"`
 	Availability     string             `json:"availability"`
 	ResumeID         uint               `json:"resume_id"`
+	ExpectedSalary   models.Salary      `json:"expected_salary"`
 
 	User           models.User `json:"user"`
 	Certifications []uint      `json:"certifications"`
@@ -0,0 +1,42 @@
+package candidateDto
+
+import (
+	"skillly/pkg/utils"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+
+	// A candidate must have every selected skill or certification
+	MatchAll = "all"
+	// A candidate must have at least one selected skill or certification
+	MatchAny = "any"
+)
+
+// SearchCandidatesDTO filters the discoverable candidates, the results are ranked
+// by their score for the job post when one is selected. The radius is searched around near,
+// around lat and lng, or around the selected job post. The candidates without preferred
+// work mode accept every work mode
+type SearchCandidatesDTO struct {
+	Skills             []uint             `form:"skills"`
+	SkillsMode         string             `form:"skills_mode" binding:"omitempty,oneof=all any"`
+	Certifications     []uint             `form:"certifications"`
+	CertificationsMode string             `form:"certifications_mode" binding:"omitempty,oneof=all any"`
+	MinExperience      int                `form:"min_experience" binding:"omitempty,min=0"`
+	Contract           utils.ContractType `form:"contract" binding:"omitempty,contract_type"`
+	Location           string             `form:"location"`
+	Near               string             `form:"near"`
+	Latitude           *float64           `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Longitude          *float64           `form:"lng" binding:"omitempty,min=-180,max=180"`
+	RadiusKm           float64            `form:"radius_km" binding:"omitempty,gt=0,max=1000"`
+	WorkModes          []utils.WorkMode   `form:"work_mode"`
+	Availability       string             `form:"availability"`
+	JobPostID          uint               `form:"job_post_id"`
+	Page               int                `form:"page" binding:"omitempty,min=1"`
+	Limit              int                `form:"limit" binding:"omitempty,min=1"`
+}
+
+type UpdateDiscoverableDTO struct {
+	Discoverable *bool `json:"discoverable" binding:"required"`
+}
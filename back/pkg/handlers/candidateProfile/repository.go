@@ -1,10 +1,17 @@
 package candidate
 
 import (
+	"errors"
+	"fmt"
+	"math"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	/* "skillly/pkg/config" */
+	"skillly/pkg/geo"
 	candidateDto "skillly/pkg/handlers/candidateProfile/dto"
 	"skillly/pkg/models"
+	"skillly/pkg/scoring"
 )
 
 type CandidateRepository interface {
@@ -12,6 +19,9 @@ type CandidateRepository interface {
 	CreateCandidate(dto candidateDto.CreateCandidateDTO, tx *gorm.DB) (models.ProfileCandidate, error)
 	SaveCandidateSkills(id uint, dto candidateDto.UpdateUserSkillsDTO) error
 	DeleteCandidateSkills(id uint, dto candidateDto.UpdateUserSkillsDTO) error
+	SetDiscoverable(id uint, discoverable bool, tx *gorm.DB) error
+	SetExpectedSalary(id uint, salary models.Salary, tx *gorm.DB) (models.Salary, error)
+	SearchCandidates(dto candidateDto.SearchCandidatesDTO, jobPost *models.JobPost, tx *gorm.DB) (models.CandidateSearchPage, error)
 }
 
 type candidateRepository struct {
@@ -32,12 +42,18 @@ func (r *candidateRepository) CreateCandidate(dto candidateDto.CreateCandidateDT
 	profile := models.ProfileCandidate{
 		Bio:              dto.Bio,
 		Location:         dto.Location,
+		Coordinates:      models.Locate(dto.Location),
+		PreferedWorkMode: dto.PreferedWorkMode,
 		ExperienceYear:   dto.ExperienceYear,
 		PreferedContract: dto.PreferedContract,
 		PreferedJob:      dto.PreferedJob,
 		Availability:     dto.Availability,
 		ResumeID:         dto.ResumeID,
 		UserID:           dto.User.ID,
+		ExpectedSalary:   dto.ExpectedSalary,
+	}
+	if err := profile.ExpectedSalary.Normalize(); err != nil {
+		return models.ProfileCandidate{}, err
 	}
 
 	createdCandidate := tx.Create(&profile)
@@ -129,3 +145,150 @@ func (r *candidateRepository) DeleteCandidateSkills(id uint, dto candidateDto.Up
 
 	return nil
 }
+
+// SetDiscoverable shows or hides the candidate in the search of the recruiters
+func (r *candidateRepository) SetDiscoverable(id uint, discoverable bool, tx *gorm.DB) error {
+	result := tx.Model(&models.ProfileCandidate{}).Where("id = ?", id).Update("discoverable", discoverable)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetExpectedSalary replaces the salary expected by the candidate, an empty salary removes it
+func (r *candidateRepository) SetExpectedSalary(id uint, salary models.Salary, tx *gorm.DB) (models.Salary, error) {
+	if err := salary.Normalize(); err != nil {
+		return models.Salary{}, err
+	}
+
+	result := tx.Model(&models.ProfileCandidate{}).Where("id = ?", id).Select("expected_salary_min", "expected_salary_max",
+		"expected_salary_currency", "expected_salary_period", "expected_salary_basis").
+		Updates(models.ProfileCandidate{ExpectedSalary: salary})
+	if result.Error != nil {
+		return models.Salary{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.Salary{}, gorm.ErrRecordNotFound
+	}
+	return salary, nil
+}
+
+// SearchCandidates returns a page of the discoverable candidates matching the filters,
+// ranked by their score for the job post when it is given, its skills and certifications must be loaded
+func (r *candidateRepository) SearchCandidates(dto candidateDto.SearchCandidatesDTO, jobPost *models.JobPost, tx *gorm.DB) (models.CandidateSearchPage, error) {
+	limit := dto.Limit
+	if limit <= 0 {
+		limit = candidateDto.DefaultSearchLimit
+	}
+	if limit > candidateDto.MaxSearchLimit {
+		limit = candidateDto.MaxSearchLimit
+	}
+	page := dto.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	var center *geo.Point
+	if dto.RadiusKm > 0 {
+		point, err := geo.Center(dto.Near, dto.Latitude, dto.Longitude)
+		if errors.Is(err, geo.ErrMissingCenter) && jobPost != nil {
+			if jobPostPoint, ok := jobPost.Coordinates.Point(); ok {
+				point, err = jobPostPoint, nil
+			}
+		}
+		if err != nil {
+			return models.CandidateSearchPage{}, err
+		}
+		center = &point
+	}
+
+	var total int64
+	if err := searchQuery(dto, center, tx).Count(&total).Error; err != nil {
+		return models.CandidateSearchPage{}, err
+	}
+
+	// The best fits first, then by ID
+	query := searchQuery(dto, center, tx)
+	if jobPost != nil {
+		score, args := scoring.ScoreSQL(*jobPost)
+		query = query.Order(clause.Expr{SQL: score + " DESC", Vars: args})
+	}
+	var candidates []models.ProfileCandidate
+	err := query.Order("profile_candidates.id").
+		Preload("User").Preload("Skills").Preload("Certifications").
+		Limit(limit).Offset((page - 1) * limit).
+		Find(&candidates).Error
+	if err != nil {
+		return models.CandidateSearchPage{}, err
+	}
+
+	results := []models.CandidateSearchResult{}
+	for _, candidate := range candidates {
+		result := models.CandidateSearchResult{ProfileCandidate: candidate, User: models.NewCandidateSearchUser(candidate.User)}
+		if point, ok := candidate.Coordinates.Point(); ok && center != nil {
+			distance := math.Round(geo.Distance(*center, point)*10) / 10
+			result.Distance = &distance
+		}
+		if jobPost != nil {
+			score, factors := scoring.Compute(candidate, *jobPost)
+			result.Score = &score
+			result.ScoreFactors = factors
+		}
+		results = append(results, result)
+	}
+
+	return models.CandidateSearchPage{Candidates: results, Total: int(total)}, nil
+}
+
+// searchQuery selects the discoverable candidates matching the filters, within the radius around the center when it is given
+// or preferring remote work
+func searchQuery(dto candidateDto.SearchCandidatesDTO, center *geo.Point, tx *gorm.DB) *gorm.DB {
+	query := tx.Model(&models.ProfileCandidate{}).Where("discoverable = ?", true)
+
+	if dto.MinExperience > 0 {
+		query = query.Where("experience_year >= ?", dto.MinExperience)
+	}
+	if dto.Contract != "" {
+		query = query.Where("prefered_contract = ?", dto.Contract)
+	}
+	if dto.Location != "" {
+		query = query.Where("location ILIKE ?", "%"+dto.Location+"%")
+	}
+	if dto.Availability != "" {
+		query = query.Where("availability ILIKE ?", "%"+dto.Availability+"%")
+	}
+	if len(dto.WorkModes) > 0 {
+		query = query.Where("prefered_work_mode IN ? OR COALESCE(prefered_work_mode, '') = ''", dto.WorkModes)
+	}
+	query = whereHasIDs(query, "user_skills", "skill_id", dto.Skills, dto.SkillsMode)
+	query = whereHasIDs(query, "user_certifications", "certification_id", dto.Certifications, dto.CertificationsMode)
+
+	// The candidates preferring remote work are in every radius, like the remote job posts
+	if center != nil {
+		query = query.Where("prefered_work_mode = ? OR (latitude IS NOT NULL AND "+geo.DistanceSQL("profile_candidates")+" <= ?)",
+			append(append([]interface{}{models.RemoteWork}, center.DistanceArgs()...), dto.RadiusKm)...)
+	}
+	return query
+}
+
+// whereHasIDs keeps the candidates owning all or any of the wanted IDs in the join table, according to the mode
+func whereHasIDs(query *gorm.DB, table string, column string, wanted []uint, mode string) *gorm.DB {
+	if len(wanted) == 0 {
+		return query
+	}
+
+	unique := map[uint]bool{}
+	for _, id := range wanted {
+		unique[id] = true
+	}
+	needed := len(unique)
+	if mode == candidateDto.MatchAny {
+		needed = 1
+	}
+
+	return query.Where(fmt.Sprintf("(SELECT COUNT(*) FROM %[1]s WHERE %[1]s.profile_candidate_id = profile_candidates.id AND %[1]s.%[2]s IN ?) >= ?", table, column),
+		wanted, needed)
+}
@@ -0,0 +1,62 @@
+package candidate
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"skillly/pkg/config"
+	"skillly/pkg/geo"
+	candidateDto "skillly/pkg/handlers/candidateProfile/dto"
+	"skillly/pkg/models"
+)
+
+type CandidateService interface {
+	SearchCandidates(c *gin.Context)
+}
+
+type candidateService struct {
+	candidateRepository CandidateRepository
+}
+
+func NewCandidateService() CandidateService {
+	return &candidateService{
+		candidateRepository: NewCandidateRepository(config.DB),
+	}
+}
+
+// SearchCandidates lets a recruiter browse the discoverable candidates
+func (s *candidateService) SearchCandidates(c *gin.Context) {
+	dto := candidateDto.SearchCandidatesDTO{}
+	if err := c.ShouldBindQuery(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The candidates are ranked for a job post of the recruiter's company
+	var jobPost *models.JobPost
+	if dto.JobPostID != 0 {
+		jobPost = &models.JobPost{}
+		if err := config.DB.Preload("Skills").Preload("Certifications").First(jobPost, dto.JobPostID).Error; err != nil {
+			c.JSON(404, gin.H{"error": "Job post not found"})
+			return
+		}
+		companyId := c.Keys["company_id"]
+		if jobPost.CompanyID != companyId.(uint) {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
+	}
+
+	page, err := s.candidateRepository.SearchCandidates(dto, jobPost, config.DB)
+	if err != nil {
+		if errors.Is(err, geo.ErrUnknownLocation) || errors.Is(err, geo.ErrMissingCenter) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "Failed to search candidates: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, page)
+}
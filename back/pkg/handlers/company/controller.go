@@ -1,6 +1,9 @@
 package company
 
 import (
+	"skillly/pkg/middleware"
+	"skillly/pkg/models"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,8 +19,164 @@ func GetAllCompaniesHandler(c *gin.Context) {
 	services.GetAll(c)
 }
 
+// @Summary Lister les recruteurs de l'entreprise
+// @Description Récupère les recruteurs de l'entreprise de l'admin, avec state=pending pour les demandes en attente
+// @Tags companies
+// @Produce json
+// @Security BearerAuth
+// @Param state query string false "État des recruteurs : pending, active ou rejected"
+// @Success 200 {array} models.ProfileRecruiter "Recruteurs de l'entreprise"
+// @Failure 400 {object} map[string]string "État invalide"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Router /company/recruiters [get]
+func GetCompanyRecruitersHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.GetRecruiters(c)
+}
+
+// @Summary Accepter un recruteur
+// @Description Accepte la demande d'un recruteur pour rejoindre l'entreprise, il peut alors accéder aux routes recruteurs. Le recruteur est notifié
+// @Tags companies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du profil recruteur"
+// @Success 200 {object} models.ProfileRecruiter "Recruteur actif"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Recruteur non trouvé dans l'entreprise"
+// @Failure 409 {object} map[string]string "La demande a déjà été traitée"
+// @Router /company/recruiters/{id}/approve [post]
+func ApproveRecruiterHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.ApproveRecruiter(c)
+}
+
+// @Summary Refuser un recruteur
+// @Description Refuse la demande d'un recruteur en attente pour rejoindre l'entreprise, il reste bloqué sur les routes recruteurs. Le recruteur est notifié
+// @Tags companies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du profil recruteur"
+// @Success 200 {object} models.ProfileRecruiter "Recruteur refusé"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Recruteur non trouvé dans l'entreprise"
+// @Failure 409 {object} map[string]string "La demande a déjà été traitée"
+// @Router /company/recruiters/{id}/reject [post]
+func RejectRecruiterHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.RejectRecruiter(c)
+}
+
+// @Summary Changer le rôle d'un recruteur
+// @Description Nomme admin ou recruteur un membre actif de l'entreprise, ses sessions sont révoquées pour que son prochain token ait le nouveau rôle. Le dernier admin ne peut pas être rétrogradé
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du profil recruteur"
+// @Param role body companyDto.ChangeRecruiterRoleDTO true "Nouveau rôle : admin ou recruiter"
+// @Success 200 {object} models.ProfileRecruiter "Recruteur avec son nouveau rôle"
+// @Failure 400 {object} map[string]string "Rôle invalide"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Recruteur actif non trouvé dans l'entreprise"
+// @Failure 409 {object} map[string]string "L'entreprise doit garder au moins un admin"
+// @Router /company/recruiters/{id}/role [put]
+func ChangeRecruiterRoleHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.ChangeRecruiterRole(c)
+}
+
+// @Summary Transférer les droits d'admin
+// @Description Nomme admin un membre actif de l'entreprise et rétrograde l'admin à l'origine de la requête en recruteur. Les sessions des deux sont révoquées
+// @Tags companies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du profil recruteur du nouvel admin"
+// @Success 200 {object} map[string]interface{} "Nouvel admin et ancien admin"
+// @Failure 400 {object} map[string]string "L'admin est déjà admin"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Recruteur actif non trouvé dans l'entreprise"
+// @Router /company/recruiters/{id}/transfer-admin [post]
+func TransferAdminHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.TransferAdmin(c)
+}
+
+// @Summary Retirer un recruteur de l'entreprise
+// @Description Retire un membre qui a quitté l'entreprise, ses offres sont réattribuées au recruteur reassign_to ou à l'admin, et ses sessions sont révoquées. Le dernier admin ne peut pas être retiré
+// @Tags companies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du profil recruteur"
+// @Param reassign_to query int false "ID du recruteur actif qui reprend les offres, l'admin par défaut"
+// @Success 200 {object} map[string]interface{} "Recruteur retiré et nombre d'offres réattribuées"
+// @Failure 400 {object} map[string]string "Recruteur de réattribution invalide"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Recruteur actif non trouvé dans l'entreprise"
+// @Failure 409 {object} map[string]string "L'entreprise doit garder au moins un admin"
+// @Router /company/recruiters/{id} [delete]
+func RemoveRecruiterHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.RemoveRecruiter(c)
+}
+
+// @Summary Inviter un recruteur
+// @Description Envoie par email un lien d'inscription valable 7 jours, le recruteur inscrit avec l'invitation est actif avec le rôle de l'invitation. Une nouvelle invitation remplace la précédente pour le même email
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invitation body companyDto.InviteRecruiterDTO true "Email et rôle (recruiter par défaut)"
+// @Success 201 {object} models.CompanyInvitation "Invitation envoyée"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 409 {object} map[string]string "Un utilisateur est déjà inscrit avec cet email"
+// @Router /company/invitations [post]
+func InviteRecruiterHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.InviteRecruiter(c)
+}
+
+// @Summary Lister les invitations
+// @Description Récupère les invitations de l'entreprise qui n'ont été ni acceptées ni expirées
+// @Tags companies
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.CompanyInvitation "Invitations en attente"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Router /company/invitations [get]
+func GetInvitationsHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.GetInvitations(c)
+}
+
+// @Summary Annuler une invitation
+// @Description Annule une invitation en attente, son lien ne peut plus être utilisé
+// @Tags companies
+// @Security BearerAuth
+// @Param id path int true "ID de l'invitation"
+// @Success 204 "Invitation annulée"
+// @Failure 403 {object} map[string]string "Accès refusé - admins actifs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Invitation en attente non trouvée"
+// @Router /company/invitations/{id} [delete]
+func RevokeInvitationHandler(c *gin.Context) {
+	services := NewCompanyService()
+	services.RevokeInvitation(c)
+}
+
 func AddRoutes(r *gin.Engine) {
 	co := r.Group("/company")
 
 	co.GET("/", GetAllCompaniesHandler)
+
+	// Management of the members of the company by its admins
+	admin := co.Group("", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), middleware.CompanyRoleMiddleware(models.AdminRole))
+	admin.GET("/recruiters", GetCompanyRecruitersHandler)
+	admin.POST("/recruiters/:id/approve", ApproveRecruiterHandler)
+	admin.POST("/recruiters/:id/reject", RejectRecruiterHandler)
+	admin.PUT("/recruiters/:id/role", ChangeRecruiterRoleHandler)
+	admin.POST("/recruiters/:id/transfer-admin", TransferAdminHandler)
+	admin.DELETE("/recruiters/:id", RemoveRecruiterHandler)
+	admin.POST("/invitations", InviteRecruiterHandler)
+	admin.GET("/invitations", GetInvitationsHandler)
+	admin.DELETE("/invitations/:id", RevokeInvitationHandler)
 }
@@ -0,0 +1,16 @@
+package companyDto
+
+import "skillly/pkg/utils"
+
+type InviteRecruiterDTO struct {
+	Email string            `json:"email" binding:"required,email"`
+	Role  utils.CompanyRole `json:"role" binding:"omitempty,oneof=admin recruiter"` // recruiter by default
+}
+
+type ChangeRecruiterRoleDTO struct {
+	Role utils.CompanyRole `json:"role" binding:"required,oneof=admin recruiter"`
+}
+
+type RemoveRecruiterQuery struct {
+	ReassignTo uint `form:"reassign_to"` // Recruiter taking over the job posts, the admin removing by default
+}
@@ -0,0 +1,103 @@
+package company
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"skillly/chat/notification"
+	"skillly/pkg/config"
+	"skillly/pkg/mailer"
+	"skillly/pkg/models"
+)
+
+// NotifyJoinRequest tells the active admins of the company that a recruiter asks to join it
+func NotifyJoinRequest(recruiter models.ProfileRecruiter, user models.User) {
+	var admins []models.User
+	err := config.DB.Where("id IN (?)",
+		config.DB.Model(&models.ProfileRecruiter{}).Select("user_id").
+			Where("company_id = ? AND role = ? AND state = ?", recruiter.CompanyID, models.AdminRole, models.ActiveState)).
+		Find(&admins).Error
+	if err != nil {
+		log.Printf("error listing the admins of the company %d: %v", recruiter.CompanyID, err)
+		return
+	}
+
+	var company models.Company
+	config.DB.Select("company_name").First(&company, recruiter.CompanyID)
+
+	userIDs := []uint{}
+	for _, admin := range admins {
+		userIDs = append(userIDs, admin.ID)
+		sendMail(mailer.Mail{
+			To:      admin.Email,
+			Subject: fmt.Sprintf("Skillly - %s %s souhaite rejoindre %s", user.FirstName, user.LastName, company.CompanyName),
+			Body: fmt.Sprintf(
+				"Bonjour %s,\n\n%s %s (%s) demande à rejoindre %s en tant que recruteur.\nAcceptez ou refusez sa demande depuis la gestion de votre entreprise.",
+				admin.FirstName, user.FirstName, user.LastName, user.Email, company.CompanyName,
+			),
+		})
+	}
+
+	notification.Send(userIDs, notification.Event{
+		Type:        notification.RecruiterRequestEvent,
+		SenderID:    fmt.Sprint(user.ID),
+		RecruiterID: recruiter.ID,
+		CompanyID:   recruiter.CompanyID,
+		State:       string(recruiter.State),
+	})
+}
+
+// notifyJoinDecision tells the recruiter that the join request was approved or rejected,
+// its user and company must be loaded
+func notifyJoinDecision(recruiter models.ProfileRecruiter, adminID uint) {
+	body := "Bonjour %s,\n\nVotre demande pour rejoindre %s a été acceptée, vous pouvez maintenant publier des offres et recruter pour l'entreprise."
+	if recruiter.State == models.RejectedState {
+		body = "Bonjour %s,\n\nVotre demande pour rejoindre %s a été refusée par un administrateur de l'entreprise."
+	}
+
+	sendMail(mailer.Mail{
+		To:      recruiter.User.Email,
+		Subject: "Skillly - Votre demande pour rejoindre " + recruiter.Company.CompanyName,
+		Body:    fmt.Sprintf(body, recruiter.User.FirstName, recruiter.Company.CompanyName),
+	})
+
+	notification.Send([]uint{recruiter.UserID}, notification.Event{
+		Type:        notification.RecruiterStateEvent,
+		SenderID:    fmt.Sprint(adminID),
+		RecruiterID: recruiter.ID,
+		CompanyID:   recruiter.CompanyID,
+		State:       string(recruiter.State),
+	})
+}
+
+// notifyMemberChange tells the recruiter that an admin changed the role or removed the recruiter from the company
+func notifyMemberChange(recruiter models.ProfileRecruiter, adminID uint) {
+	notification.Send([]uint{recruiter.UserID}, notification.Event{
+		Type:        notification.RecruiterStateEvent,
+		SenderID:    fmt.Sprint(adminID),
+		RecruiterID: recruiter.ID,
+		CompanyID:   recruiter.CompanyID,
+		State:       string(recruiter.State),
+		Role:        string(recruiter.Role),
+	})
+}
+
+// sendInvitation sends the link to register as a recruiter of the company
+func sendInvitation(invitation models.CompanyInvitation, token string, company models.Company, inviter string) {
+	sendMail(mailer.Mail{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("Skillly - %s vous invite à rejoindre %s", inviter, company.CompanyName),
+		Body: fmt.Sprintf(
+			"Bonjour,\n\n%s vous invite à rejoindre %s sur Skillly. Créez votre compte recruteur en ouvrant ce lien :\n%s/join-company?token=%s\n\nCe lien expire dans 7 jours.",
+			inviter, company.CompanyName, os.Getenv("APP_URL"), token,
+		),
+	})
+}
+
+// sendMail logs the error instead of failing the request, the notification is still sent
+func sendMail(mail mailer.Mail) {
+	if err := mailer.Default.Send(mail); err != nil {
+		log.Printf("error sending mail to %s: %v", mail.To, err)
+	}
+}
@@ -33,6 +33,7 @@ func (r *companyRepository) CreateCompany(dto companyDto.CreateCompanyDTO, tx *g
 		Industry:    dto.Industry,
 		WebSite:     dto.WebSite,
 		Location:    dto.Location,
+		Coordinates: models.Locate(dto.Location),
 		Logo:        dto.Logo,
 		Size:        dto.Size,
 	}
@@ -1,23 +1,47 @@
 package company
 
 import (
+	"errors"
+	"fmt"
+
 	"skillly/pkg/config"
+	companyDto "skillly/pkg/handlers/company/dto"
+	"skillly/pkg/handlers/companyInvitation"
+	recruiter "skillly/pkg/handlers/recruiterProfile"
+	"skillly/pkg/handlers/session"
+	"skillly/pkg/models"
 	"skillly/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CompanyService interface {
 	GetAll(c *gin.Context)
+	GetRecruiters(c *gin.Context)
+	ApproveRecruiter(c *gin.Context)
+	RejectRecruiter(c *gin.Context)
+	ChangeRecruiterRole(c *gin.Context)
+	TransferAdmin(c *gin.Context)
+	RemoveRecruiter(c *gin.Context)
+	InviteRecruiter(c *gin.Context)
+	GetInvitations(c *gin.Context)
+	RevokeInvitation(c *gin.Context)
 }
 
 type companyService struct {
-	companyRepository CompanyRepository
+	companyRepository    CompanyRepository
+	recruiterRepository  recruiter.RecruiterRepository
+	invitationRepository companyInvitation.CompanyInvitationRepository
+	sessionRepository    session.SessionRepository
 }
 
 func NewCompanyService() CompanyService {
 	return &companyService{
-		companyRepository: NewCompanyRepository(config.DB),
+		companyRepository:    NewCompanyRepository(config.DB),
+		recruiterRepository:  recruiter.NewRecruiterRepository(config.DB),
+		invitationRepository: companyInvitation.NewCompanyInvitationRepository(config.DB),
+		sessionRepository:    session.NewSessionRepository(config.DB),
 	}
 }
 
@@ -34,3 +58,272 @@ func (s *companyService) GetAll(
 
 	c.JSON(200, companies)
 }
+
+// GetRecruiters lists the recruiters of the admin's company, filtered by state (pending, active, rejected or removed)
+func (s *companyService) GetRecruiters(c *gin.Context) {
+	state := utils.RecruiterState(c.Query("state"))
+	switch state {
+	case "", models.PendingState, models.ActiveState, models.RejectedState, models.RemovedState:
+	default:
+		c.JSON(400, gin.H{"error": "Invalid state"})
+		return
+	}
+
+	companyId := c.Keys["company_id"]
+	recruiters, err := s.recruiterRepository.GetCompanyRecruiters(companyId.(uint), state)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, recruiters)
+}
+
+// ApproveRecruiter lets a recruiter who asked to join the admin's company act for it
+func (s *companyService) ApproveRecruiter(c *gin.Context) {
+	s.answerJoinRequest(c, models.ActiveState)
+}
+
+// RejectRecruiter refuses the join request of a recruiter, who stays blocked from the recruiter routes
+func (s *companyService) RejectRecruiter(c *gin.Context) {
+	s.answerJoinRequest(c, models.RejectedState)
+}
+
+func (s *companyService) answerJoinRequest(c *gin.Context, state utils.RecruiterState) {
+	recruiterID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	companyId := c.Keys["company_id"]
+	tx := config.DB.Begin()
+	answered, err := s.recruiterRepository.AnswerJoinRequest(recruiterID, companyId.(uint), state, tx)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(404, gin.H{"error": "Recruiter not found"})
+		case errors.Is(err, recruiter.ErrRequestAnswered):
+			c.JSON(409, gin.H{"error": err.Error()})
+		default:
+			c.JSON(500, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	notifyJoinDecision(answered, c.Keys["user_id"].(uint))
+
+	c.JSON(200, answered)
+}
+
+// ChangeRecruiterRole makes an active recruiter of the company an admin or a recruiter,
+// the sessions are revoked so that the next token has the new role
+func (s *companyService) ChangeRecruiterRole(c *gin.Context) {
+	recruiterID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	dto := companyDto.ChangeRecruiterRoleDTO{}
+	if err := c.BindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyId := c.Keys["company_id"]
+	tx := config.DB.Begin()
+	changed, err := s.recruiterRepository.ChangeRole(recruiterID, companyId.(uint), dto.Role, tx)
+	if err == nil {
+		err = s.sessionRepository.RevokeUserSessions(changed.UserID, tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		teamError(c, err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	notifyMemberChange(changed, c.Keys["user_id"].(uint))
+
+	c.JSON(200, changed)
+}
+
+// TransferAdmin makes an active recruiter of the company admin in place of the current admin,
+// the sessions of both are revoked so that their next tokens have their new roles
+func (s *companyService) TransferAdmin(c *gin.Context) {
+	recruiterID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	adminID := c.Keys["recruiter_id"].(uint)
+	if recruiterID == adminID {
+		c.JSON(400, gin.H{"error": "You are already an admin of the company"})
+		return
+	}
+
+	companyId := c.Keys["company_id"]
+	tx := config.DB.Begin()
+	// The new admin is promoted first so that the company keeps an admin
+	promoted, err := s.recruiterRepository.ChangeRole(recruiterID, companyId.(uint), models.AdminRole, tx)
+	if err != nil {
+		tx.Rollback()
+		teamError(c, err)
+		return
+	}
+	demoted, err := s.recruiterRepository.ChangeRole(adminID, companyId.(uint), models.RecruiterRole, tx)
+	if err == nil {
+		err = s.sessionRepository.RevokeUserSessions(promoted.UserID, tx)
+	}
+	if err == nil {
+		err = s.sessionRepository.RevokeUserSessions(demoted.UserID, tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		teamError(c, err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	notifyMemberChange(promoted, demoted.UserID)
+
+	c.JSON(200, gin.H{"admin": promoted, "recruiter": demoted})
+}
+
+// RemoveRecruiter removes a recruiter who left the company, the job posts are reassigned
+// to the recruiter given in reassign_to or to the admin, and the sessions are revoked
+func (s *companyService) RemoveRecruiter(c *gin.Context) {
+	recruiterID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	query := companyDto.RemoveRecruiterQuery{}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if query.ReassignTo == 0 {
+		query.ReassignTo = c.Keys["recruiter_id"].(uint)
+	}
+
+	companyId := c.Keys["company_id"]
+	tx := config.DB.Begin()
+	removed, reassigned, err := s.recruiterRepository.RemoveRecruiter(recruiterID, companyId.(uint), query.ReassignTo, tx)
+	if err == nil {
+		err = s.sessionRepository.RevokeUserSessions(removed.UserID, tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		teamError(c, err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	notifyMemberChange(removed, c.Keys["user_id"].(uint))
+
+	c.JSON(200, gin.H{"recruiter": removed, "reassigned_job_posts": reassigned})
+}
+
+// InviteRecruiter sends an invitation by email to register as an active recruiter of the company
+func (s *companyService) InviteRecruiter(c *gin.Context) {
+	dto := companyDto.InviteRecruiterDTO{}
+	if err := c.BindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyId := c.Keys["company_id"]
+	company, err := s.companyRepository.GetByID(companyId.(uint), nil)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Company not found"})
+		return
+	}
+
+	var invitation models.CompanyInvitation
+	var token string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		invitation, token, err = s.invitationRepository.CreateInvitation(models.CompanyInvitation{
+			CompanyID:   company.ID,
+			Email:       dto.Email,
+			Role:        dto.Role,
+			InvitedByID: c.Keys["recruiter_id"].(uint),
+		}, tx)
+		return err
+	})
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+
+	sendInvitation(invitation, token, company, fmt.Sprintf("%s %s", c.Keys["user_first_name"], c.Keys["user_last_name"]))
+
+	c.JSON(201, invitation)
+}
+
+// GetInvitations lists the invitations of the company waiting for a registration
+func (s *companyService) GetInvitations(c *gin.Context) {
+	companyId := c.Keys["company_id"]
+	invitations, err := s.invitationRepository.GetPendingInvitations(companyId.(uint))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, invitations)
+}
+
+// RevokeInvitation cancels a pending invitation of the company
+func (s *companyService) RevokeInvitation(c *gin.Context) {
+	invitationID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	companyId := c.Keys["company_id"]
+	if err := s.invitationRepository.RevokeInvitation(invitationID, companyId.(uint), config.DB); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "Invitation not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(204)
+}
+
+// teamError answers the error of a change of the members of a company
+func teamError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(404, gin.H{"error": "Recruiter not found"})
+	case errors.Is(err, recruiter.ErrLastAdmin), errors.Is(err, companyInvitation.ErrAlreadyRegistered):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, recruiter.ErrInvalidReassignment):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,124 @@
+package companyInvitation
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+)
+
+// Lifetime of an invitation, the admin can send a new one once it expired
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrAlreadyRegistered = errors.New("A user is already registered with this email")
+	ErrInvalidInvitation = errors.New("Invalid or expired invitation")
+)
+
+type CompanyInvitationRepository interface {
+	models.Repository[models.CompanyInvitation]
+	CreateInvitation(invitation models.CompanyInvitation, tx *gorm.DB) (models.CompanyInvitation, string, error)
+	GetPendingInvitations(companyID uint) ([]models.CompanyInvitation, error)
+	RevokeInvitation(id uint, companyID uint, tx *gorm.DB) error
+	AcceptInvitation(token string, email string, tx *gorm.DB) (models.CompanyInvitation, error)
+}
+
+type companyInvitationRepository struct {
+	models.Repository[models.CompanyInvitation]
+	db *gorm.DB
+}
+
+func NewCompanyInvitationRepository(db *gorm.DB) CompanyInvitationRepository {
+	return &companyInvitationRepository{
+		Repository: models.NewRepository[models.CompanyInvitation](db),
+		db:         db,
+	}
+}
+
+// CreateInvitation replaces the pending invitations of the email to the company
+// and returns a new clear token, only its hash is stored
+func (r *companyInvitationRepository) CreateInvitation(invitation models.CompanyInvitation, tx *gorm.DB) (models.CompanyInvitation, string, error) {
+	invitation.Email = strings.ToLower(strings.TrimSpace(invitation.Email))
+	if invitation.Role == "" {
+		invitation.Role = models.RecruiterRole
+	}
+
+	var users int64
+	if err := tx.Model(&models.User{}).Where("LOWER(email) = ?", invitation.Email).Count(&users).Error; err != nil {
+		return models.CompanyInvitation{}, "", err
+	}
+	if users > 0 {
+		return models.CompanyInvitation{}, "", ErrAlreadyRegistered
+	}
+
+	err := tx.Where("company_id = ? AND email = ? AND accepted_at IS NULL", invitation.CompanyID, invitation.Email).
+		Delete(&models.CompanyInvitation{}).Error
+	if err != nil {
+		return models.CompanyInvitation{}, "", err
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return models.CompanyInvitation{}, "", err
+	}
+	invitation.TokenHash = utils.HashToken(token)
+	invitation.ExpiresAt = time.Now().Add(InvitationTTL)
+
+	if err := tx.Create(&invitation).Error; err != nil {
+		return models.CompanyInvitation{}, "", err
+	}
+	return invitation, token, nil
+}
+
+// GetPendingInvitations lists the invitations of the company which were neither accepted nor expired
+func (r *companyInvitationRepository) GetPendingInvitations(companyID uint) ([]models.CompanyInvitation, error) {
+	var invitations []models.CompanyInvitation
+	err := r.db.Where("company_id = ? AND accepted_at IS NULL AND expires_at > ?", companyID, time.Now()).
+		Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// RevokeInvitation deletes a pending invitation of the company, its link can no longer be used
+func (r *companyInvitationRepository) RevokeInvitation(id uint, companyID uint, tx *gorm.DB) error {
+	result := tx.Where("id = ? AND company_id = ? AND accepted_at IS NULL", id, companyID).Delete(&models.CompanyInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation marks a valid invitation sent to the email as accepted and returns it
+func (r *companyInvitationRepository) AcceptInvitation(token string, email string, tx *gorm.DB) (models.CompanyInvitation, error) {
+	var invitation models.CompanyInvitation
+	result := tx.Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", utils.HashToken(token), time.Now()).
+		First(&invitation)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.CompanyInvitation{}, ErrInvalidInvitation
+	}
+	if result.Error != nil {
+		return models.CompanyInvitation{}, result.Error
+	}
+	// The invitation is personal, it can't be used with another email
+	if invitation.Email != strings.ToLower(strings.TrimSpace(email)) {
+		return models.CompanyInvitation{}, ErrInvalidInvitation
+	}
+
+	// The condition on accepted_at prevents two concurrent registrations with the same invitation
+	now := time.Now()
+	result = tx.Model(&invitation).Where("accepted_at IS NULL").Update("accepted_at", now)
+	if result.Error != nil {
+		return models.CompanyInvitation{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.CompanyInvitation{}, ErrInvalidInvitation
+	}
+	invitation.AcceptedAt = &now
+	return invitation, nil
+}
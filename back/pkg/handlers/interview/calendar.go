@@ -0,0 +1,48 @@
+package interview
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"skillly/pkg/ical"
+	"skillly/pkg/models"
+)
+
+// CalendarEvent describes a confirmed interview for the calendars, its match must be loaded
+func CalendarEvent(interview models.Interview) ical.Event {
+	summary := "Entretien"
+	description := []string{}
+	if interview.Match != nil {
+		jobPost := interview.Match.JobPost
+		user := interview.Match.Candidate.User
+		summary = fmt.Sprintf("Entretien %s - %s", jobPost.Title, jobPost.Company.CompanyName)
+		description = append(description, fmt.Sprintf("Candidat : %s %s", user.FirstName, user.LastName))
+	}
+	if interview.VideoURL != "" {
+		description = append(description, "Visioconférence : "+interview.VideoURL)
+	}
+	if interview.Note != "" {
+		description = append(description, interview.Note)
+	}
+
+	event := ical.Event{
+		UID:         fmt.Sprintf("interview-%d@skillly", interview.ID),
+		Summary:     summary,
+		Description: strings.Join(description, "\n"),
+		Location:    interview.Location,
+		URL:         interview.VideoURL,
+		Sequence:    interview.Sequence,
+		Stamp:       interview.UpdatedAt,
+	}
+	if interview.StartsAt != nil {
+		event.Start = *interview.StartsAt
+		event.End = interview.EndsAt()
+	}
+	return event
+}
+
+// feedURL is the address of the calendar feed of a user, to subscribe to in a calendar application
+func feedURL(token string) string {
+	return fmt.Sprintf("%s/interview/feed/%s.ics", os.Getenv("API_URL"), token)
+}
@@ -0,0 +1,155 @@
+package interview
+
+import (
+	"skillly/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Proposer un entretien
+// @Description Propose jusqu'à 5 créneaux aux autres participants d'un match, les créneaux sont en RFC 3339 ou en heure locale (2006-01-02T15:04) du fuseau horaire donné
+// @Tags interviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du match"
+// @Param interviewData body interviewDto.ProposeInterviewDTO true "Créneaux, fuseau horaire, durée en minutes, lien de visioconférence ou lieu"
+// @Success 201 {object} models.Interview "Entretien proposé"
+// @Failure 400 {object} map[string]string "Erreur de validation, fuseau horaire ou créneau invalide"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Failure 404 {object} map[string]string "Match non trouvé"
+// @Router /interview/match/{id} [post]
+func ProposeInterviewHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.ProposeInterview(c)
+}
+
+// @Summary Lister les entretiens d'un match
+// @Description Récupère les entretiens d'un match, du plus récent au plus ancien
+// @Tags interviews
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID du match"
+// @Success 200 {array} models.Interview "Liste des entretiens"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Failure 404 {object} map[string]string "Match non trouvé"
+// @Router /interview/match/{id} [get]
+func GetMatchInterviewsHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.GetMatchInterviews(c)
+}
+
+// @Summary Accepter un entretien
+// @Description Confirme l'un des créneaux proposés, seul un participant qui n'a pas proposé l'entretien peut l'accepter
+// @Tags interviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de l'entretien"
+// @Param acceptData body interviewDto.AcceptInterviewDTO true "Créneau choisi"
+// @Success 200 {object} models.Interview "Entretien confirmé"
+// @Failure 400 {object} map[string]string "Le créneau ne fait pas partie des créneaux proposés"
+// @Failure 403 {object} map[string]string "Accès refusé"
+// @Failure 404 {object} map[string]string "Entretien non trouvé"
+// @Failure 409 {object} map[string]string "L'entretien n'attend pas de réponse ou a changé entre-temps"
+// @Router /interview/{id}/accept [post]
+func AcceptInterviewHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.AcceptInterview(c)
+}
+
+// @Summary Refuser un entretien
+// @Description Refuse les créneaux proposés avec une raison facultative, seul un participant qui n'a pas proposé l'entretien peut le refuser
+// @Tags interviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de l'entretien"
+// @Param declineData body interviewDto.DeclineInterviewDTO true "Raison du refus"
+// @Success 200 {object} models.Interview "Entretien refusé"
+// @Failure 403 {object} map[string]string "Accès refusé"
+// @Failure 404 {object} map[string]string "Entretien non trouvé"
+// @Failure 409 {object} map[string]string "L'entretien n'attend pas de réponse ou a changé entre-temps"
+// @Router /interview/{id}/decline [post]
+func DeclineInterviewHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.DeclineInterview(c)
+}
+
+// @Summary Reprogrammer un entretien
+// @Description Propose de nouveaux créneaux pour un entretien, l'entretien attend à nouveau une réponse des autres participants
+// @Tags interviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de l'entretien"
+// @Param interviewData body interviewDto.ProposeInterviewDTO true "Nouveaux créneaux"
+// @Success 200 {object} models.Interview "Entretien reprogrammé"
+// @Failure 400 {object} map[string]string "Erreur de validation, fuseau horaire ou créneau invalide"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Failure 404 {object} map[string]string "Entretien non trouvé"
+// @Failure 409 {object} map[string]string "L'entretien a changé entre-temps"
+// @Router /interview/{id}/reschedule [post]
+func RescheduleInterviewHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.RescheduleInterview(c)
+}
+
+// @Summary Télécharger un entretien
+// @Description Télécharge le fichier iCalendar (.ics) d'un entretien confirmé
+// @Tags interviews
+// @Produce text/calendar
+// @Security BearerAuth
+// @Param id path int true "ID de l'entretien"
+// @Success 200 {file} file "Fichier .ics"
+// @Failure 403 {object} map[string]string "Accès refusé - participants du match uniquement"
+// @Failure 404 {object} map[string]string "Entretien non trouvé"
+// @Failure 409 {object} map[string]string "L'entretien n'est pas confirmé"
+// @Router /interview/{id}/ics [get]
+func DownloadICSHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.DownloadICS(c)
+}
+
+// @Summary Créer l'URL de son agenda
+// @Description Crée l'URL de l'agenda iCalendar des entretiens confirmés de l'utilisateur, à ajouter dans une application d'agenda. L'URL précédente ne fonctionne plus
+// @Tags interviews
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} map[string]string "URL de l'agenda"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Router /interview/feed [post]
+func CreateFeedURLHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.CreateFeedURL(c)
+}
+
+// @Summary Agenda des entretiens
+// @Description Agenda iCalendar des entretiens confirmés de l'utilisateur, authentifié par le token de l'URL
+// @Tags interviews
+// @Produce text/calendar
+// @Param token path string true "Token de l'agenda, suivi de .ics"
+// @Success 200 {file} file "Agenda .ics"
+// @Failure 404 {object} map[string]string "Agenda non trouvé"
+// @Router /interview/feed/{token} [get]
+func GetFeedHandler(c *gin.Context) {
+	interviewService := NewInterviewService()
+	interviewService.GetFeed(c)
+}
+
+func AddRoutes(r *gin.Engine) {
+	interview := r.Group("/interview")
+
+	interview.POST("/match/:id", middleware.AuthMiddleware(), ProposeInterviewHandler)
+	interview.GET("/match/:id", middleware.AuthMiddleware(), GetMatchInterviewsHandler)
+	interview.POST("/:id/accept", middleware.AuthMiddleware(), AcceptInterviewHandler)
+	interview.POST("/:id/decline", middleware.AuthMiddleware(), DeclineInterviewHandler)
+	interview.POST("/:id/reschedule", middleware.AuthMiddleware(), RescheduleInterviewHandler)
+	interview.GET("/:id/ics", middleware.AuthMiddleware(), DownloadICSHandler)
+	interview.POST("/feed", middleware.AuthMiddleware(), CreateFeedURLHandler)
+
+	// The calendar applications cannot send a bearer token, the feed is authenticated by its token
+	interview.GET("/feed/:token", GetFeedHandler)
+}
@@ -0,0 +1,10 @@
+package interviewDto
+
+// AcceptInterviewDTO confirms one of the proposed slots
+type AcceptInterviewDTO struct {
+	StartsAt string `json:"starts_at" binding:"required"`
+}
+
+type DeclineInterviewDTO struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
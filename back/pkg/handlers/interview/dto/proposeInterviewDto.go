@@ -0,0 +1,12 @@
+package interviewDto
+
+// ProposeInterviewDTO proposes slots to the other participant of a match, it also reschedules an interview
+// the slots are RFC 3339 times or local times (2006-01-02T15:04) in the timezone
+type ProposeInterviewDTO struct {
+	Slots    []string `json:"slots" binding:"required,min=1,max=5"`
+	Timezone string   `json:"timezone" binding:"required"`
+	Duration int      `json:"duration" binding:"required,min=15,max=480"`
+	VideoURL string   `json:"video_url" binding:"omitempty,url"`
+	Location string   `json:"location" binding:"max=255"`
+	Note     string   `json:"note" binding:"max=1000"`
+}
@@ -0,0 +1,112 @@
+package interview
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+)
+
+// ErrInterviewChanged is returned when the interview was answered or rescheduled since it was read
+var ErrInterviewChanged = errors.New("The interview was answered or rescheduled in the meantime")
+
+type InterviewRepository interface {
+	models.Repository[models.Interview]
+	GetInterview(id uint, tx *gorm.DB) (models.Interview, error)
+	GetMatchInterviews(matchID uint, tx *gorm.DB) ([]models.Interview, error)
+	GetUserConfirmedInterviews(userID uint, tx *gorm.DB) ([]models.Interview, error)
+	SaveInterview(interview *models.Interview, state utils.InterviewState, sequence int, tx *gorm.DB) error
+	GetConfirmedInterviewsBetween(from time.Time, to time.Time, tx *gorm.DB) ([]models.Interview, error)
+}
+
+type interviewRepository struct {
+	models.Repository[models.Interview]
+	db *gorm.DB
+}
+
+func NewInterviewRepository(db *gorm.DB) InterviewRepository {
+	return &interviewRepository{
+		Repository: models.NewRepository[models.Interview](db),
+		db:         db,
+	}
+}
+
+// withMatch loads what the calendars need to describe an interview
+func withMatch(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Match.JobPost.Company").Preload("Match.Candidate.User")
+}
+
+// GetInterview returns an interview with its match
+func (r *interviewRepository) GetInterview(id uint, tx *gorm.DB) (models.Interview, error) {
+	var interview models.Interview
+	if err := withMatch(tx).First(&interview, id).Error; err != nil {
+		return models.Interview{}, err
+	}
+
+	return interview, nil
+}
+
+// GetMatchInterviews returns the interviews of a match from the most recent
+func (r *interviewRepository) GetMatchInterviews(matchID uint, tx *gorm.DB) ([]models.Interview, error) {
+	var interviews []models.Interview
+	err := tx.Where("match_id = ?", matchID).Order("created_at DESC, id DESC").Find(&interviews).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
+
+// GetUserConfirmedInterviews returns the confirmed interviews of the matches of a user,
+// as the candidate or as an active recruiter of the company of the job post
+func (r *interviewRepository) GetUserConfirmedInterviews(userID uint, tx *gorm.DB) ([]models.Interview, error) {
+	var interviews []models.Interview
+	err := withMatch(tx).
+		Joins("JOIN matches ON matches.id = interviews.match_id").
+		Joins("JOIN profile_candidates ON profile_candidates.id = matches.candidate_id").
+		Joins("JOIN job_posts ON job_posts.id = matches.job_post_id").
+		Where("interviews.state = ?", models.ConfirmedInterview).
+		Where("profile_candidates.user_id = ? OR job_posts.company_id IN (?)", userID,
+			tx.Model(&models.ProfileRecruiter{}).Select("company_id").Where("user_id = ? AND state = ?", userID, models.ActiveState)).
+		Order("interviews.starts_at").
+		Find(&interviews).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
+
+// SaveInterview saves the interview without its match if it still has the state and the sequence it was read with,
+// so that two answers or reschedules at the same time don't overwrite each other
+func (r *interviewRepository) SaveInterview(interview *models.Interview, state utils.InterviewState, sequence int, tx *gorm.DB) error {
+	result := tx.Model(interview).
+		Where("state = ? AND sequence = ?", state, sequence).
+		Select("*").Omit("id", "created_at", clause.Associations).
+		Updates(interview)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInterviewChanged
+	}
+	return nil
+}
+
+// GetConfirmedInterviewsBetween returns the confirmed interviews starting in [from, to) with their match
+func (r *interviewRepository) GetConfirmedInterviewsBetween(from time.Time, to time.Time, tx *gorm.DB) ([]models.Interview, error) {
+	var interviews []models.Interview
+	err := withMatch(tx).
+		Where("state = ? AND starts_at >= ? AND starts_at < ?", models.ConfirmedInterview, from, to).
+		Order("starts_at").
+		Find(&interviews).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
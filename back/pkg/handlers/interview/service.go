@@ -0,0 +1,336 @@
+package interview
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"skillly/chat/notification"
+	"skillly/pkg/config"
+	interviewDto "skillly/pkg/handlers/interview/dto"
+	"skillly/pkg/handlers/match"
+	"skillly/pkg/handlers/userToken"
+	"skillly/pkg/ical"
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+)
+
+// FeedTokenTTL is the lifetime of a calendar feed URL, creating a new one revokes the previous one
+const FeedTokenTTL = 365 * 24 * time.Hour
+
+type InterviewService interface {
+	ProposeInterview(c *gin.Context)
+	GetMatchInterviews(c *gin.Context)
+	AcceptInterview(c *gin.Context)
+	DeclineInterview(c *gin.Context)
+	RescheduleInterview(c *gin.Context)
+	DownloadICS(c *gin.Context)
+	CreateFeedURL(c *gin.Context)
+	GetFeed(c *gin.Context)
+}
+
+type interviewService struct {
+	interviewRepository InterviewRepository
+	matchRepository     match.MatchRepository         // To check the participants of the match
+	userTokenRepository userToken.UserTokenRepository // To authenticate the calendar feeds
+}
+
+func NewInterviewService() InterviewService {
+	return &interviewService{
+		interviewRepository: NewInterviewRepository(config.DB),
+		matchRepository:     match.NewMatchRepository(config.DB),
+		userTokenRepository: userToken.NewUserTokenRepository(config.DB),
+	}
+}
+
+// ProposeInterview proposes slots to the other participants of a match
+func (s *interviewService) ProposeInterview(c *gin.Context) {
+	matchID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	participants, ok := s.participants(c, matchID)
+	if !ok {
+		return
+	}
+
+	var dto interviewDto.ProposeInterviewDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	slots, err := ParseSlots(dto.Slots, dto.Timezone, time.Now())
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	interview := models.Interview{
+		MatchID:    matchID,
+		ProposerID: c.Keys["user_id"].(uint),
+		State:      models.ProposedInterview,
+		Slots:      slots,
+		Duration:   dto.Duration,
+		Timezone:   dto.Timezone,
+		VideoURL:   dto.VideoURL,
+		Location:   dto.Location,
+		Note:       dto.Note,
+	}
+	if err := s.interviewRepository.Create(&interview); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to create interview: " + err.Error()})
+		return
+	}
+
+	notify(c, participants, interview)
+	c.JSON(201, interview)
+}
+
+// GetMatchInterviews lists the interviews of a match to its participants
+func (s *interviewService) GetMatchInterviews(c *gin.Context) {
+	matchID, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+
+	if _, ok := s.participants(c, matchID); !ok {
+		return
+	}
+
+	interviews, err := s.interviewRepository.GetMatchInterviews(matchID, config.DB)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to get interviews: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, interviews)
+}
+
+// AcceptInterview confirms one of the proposed slots, only the participants who did not propose them can
+func (s *interviewService) AcceptInterview(c *gin.Context) {
+	interview, participants, ok := s.answerable(c)
+	if !ok {
+		return
+	}
+
+	var dto interviewDto.AcceptInterviewDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	startsAt, err := MatchSlot(interview.Slots, dto.StartsAt, interview.Timezone)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	interview.State = models.ConfirmedInterview
+	interview.StartsAt = &startsAt
+	if err := s.interviewRepository.SaveInterview(&interview, models.ProposedInterview, interview.Sequence, config.DB); err != nil {
+		saveError(c, "Failed to accept interview: ", err)
+		return
+	}
+
+	notify(c, participants, interview)
+	c.JSON(200, interview)
+}
+
+// DeclineInterview declines the proposed slots, only the participants who did not propose them can
+func (s *interviewService) DeclineInterview(c *gin.Context) {
+	interview, participants, ok := s.answerable(c)
+	if !ok {
+		return
+	}
+
+	var dto interviewDto.DeclineInterviewDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	interview.State = models.DeclinedInterview
+	interview.DeclineReason = dto.Reason
+	if err := s.interviewRepository.SaveInterview(&interview, models.ProposedInterview, interview.Sequence, config.DB); err != nil {
+		saveError(c, "Failed to decline interview: ", err)
+		return
+	}
+
+	notify(c, participants, interview)
+	c.JSON(200, interview)
+}
+
+// RescheduleInterview proposes new slots for an interview, the participant who reschedules it becomes its proposer
+func (s *interviewService) RescheduleInterview(c *gin.Context) {
+	interview, participants, ok := s.interview(c)
+	if !ok {
+		return
+	}
+
+	var dto interviewDto.ProposeInterviewDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	slots, err := ParseSlots(dto.Slots, dto.Timezone, time.Now())
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	state, sequence := interview.State, interview.Sequence
+	interview.ProposerID = c.Keys["user_id"].(uint)
+	interview.State = models.ProposedInterview
+	interview.Slots = slots
+	interview.StartsAt = nil
+	interview.Duration = dto.Duration
+	interview.Timezone = dto.Timezone
+	interview.VideoURL = dto.VideoURL
+	interview.Location = dto.Location
+	interview.Note = dto.Note
+	interview.DeclineReason = ""
+	interview.Sequence++
+	if err := s.interviewRepository.SaveInterview(&interview, state, sequence, config.DB); err != nil {
+		saveError(c, "Failed to reschedule interview: ", err)
+		return
+	}
+
+	notify(c, participants, interview)
+	c.JSON(200, interview)
+}
+
+// DownloadICS returns the calendar file of a confirmed interview
+func (s *interviewService) DownloadICS(c *gin.Context) {
+	interview, _, ok := s.interview(c)
+	if !ok {
+		return
+	}
+
+	if interview.State != models.ConfirmedInterview {
+		c.JSON(409, gin.H{"error": "The interview is not confirmed"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=interview-%d.ics", interview.ID))
+	c.Data(200, ical.ContentType, ical.Calendar("Entretien Skillly", []ical.Event{CalendarEvent(interview)}))
+}
+
+// CreateFeedURL creates the calendar feed URL of the user, the previous one stops working
+func (s *interviewService) CreateFeedURL(c *gin.Context) {
+	token, err := s.userTokenRepository.CreateToken(c.Keys["user_id"].(uint), models.CalendarFeedPurpose, FeedTokenTTL, config.DB)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to create the calendar feed: " + err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"url": feedURL(token)})
+}
+
+// GetFeed returns the confirmed interviews of the owner of the feed token, the calendar applications
+// cannot authenticate so the token of the URL is the authentication
+func (s *interviewService) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	feedToken, err := s.userTokenRepository.GetToken(token, models.CalendarFeedPurpose, config.DB)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	interviews, err := s.interviewRepository.GetUserConfirmedInterviews(feedToken.UserID, config.DB)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to get interviews: " + err.Error()})
+		return
+	}
+
+	events := make([]ical.Event, 0, len(interviews))
+	for _, interview := range interviews {
+		events = append(events, CalendarEvent(interview))
+	}
+	c.Data(200, ical.ContentType, ical.Calendar("Entretiens Skillly", events))
+}
+
+// participants returns the participants of the match, it answers 403 if the user is not one of them
+func (s *interviewService) participants(c *gin.Context, matchID uint) ([]uint, bool) {
+	participants, err := s.matchRepository.GetParticipantUserIDs(matchID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "Match not found"})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to get the participants of the match: " + err.Error()})
+		}
+		return nil, false
+	}
+	if !slices.Contains(participants, c.Keys["user_id"].(uint)) {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return nil, false
+	}
+	return participants, true
+}
+
+// interview returns the interview of the URL and the participants of its match
+func (s *interviewService) interview(c *gin.Context) (models.Interview, []uint, bool) {
+	interviewID, err := utils.GetId(c)
+	if err != nil {
+		return models.Interview{}, nil, false
+	}
+
+	interview, err := s.interviewRepository.GetInterview(interviewID, config.DB)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "Interview not found"})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to get interview: " + err.Error()})
+		}
+		return models.Interview{}, nil, false
+	}
+
+	participants, ok := s.participants(c, interview.MatchID)
+	return interview, participants, ok
+}
+
+// answerable returns the interview of the URL if the user can accept or decline it
+func (s *interviewService) answerable(c *gin.Context) (models.Interview, []uint, bool) {
+	interview, participants, ok := s.interview(c)
+	if !ok {
+		return models.Interview{}, nil, false
+	}
+
+	if interview.ProposerID == c.Keys["user_id"].(uint) {
+		c.JSON(403, gin.H{"error": "The proposer cannot answer their own proposal"})
+		return models.Interview{}, nil, false
+	}
+	if interview.State != models.ProposedInterview {
+		c.JSON(409, gin.H{"error": "The interview is not waiting for an answer"})
+		return models.Interview{}, nil, false
+	}
+	return interview, participants, true
+}
+
+// saveError answers 409 when the interview changed since it was read
+func saveError(c *gin.Context, message string, err error) {
+	if errors.Is(err, ErrInterviewChanged) {
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(500, gin.H{"error": message + err.Error()})
+}
+
+// notify tells the other participants of the match that the interview changed
+func notify(c *gin.Context, participants []uint, interview models.Interview) {
+	roomID := fmt.Sprint(interview.MatchID)
+	notification.Send(participants, notification.Event{
+		Type:        notification.InterviewEvent,
+		SenderID:    fmt.Sprint(c.Keys["user_id"]),
+		RoomID:      roomID,
+		MatchID:     interview.MatchID,
+		InterviewID: interview.ID,
+		State:       string(interview.State),
+	})
+}
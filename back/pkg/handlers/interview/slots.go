@@ -0,0 +1,82 @@
+package interview
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTimezone = errors.New("Invalid timezone")
+	ErrInvalidSlot     = errors.New("Invalid slot, expected a future time such as 2006-01-02T15:04")
+	ErrUnknownSlot     = errors.New("The slot is not one of the proposed slots")
+)
+
+// localLayouts are the layouts of the slots written without offset, in the timezone of the proposal
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// ParseSlots parses the proposed slots in the timezone and returns them in UTC,
+// the slots must be in the future and are returned without duplicates in chronological order
+func ParseSlots(values []string, timezone string, now time.Time) ([]time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		return nil, ErrInvalidTimezone
+	}
+
+	slots := []time.Time{}
+	for _, value := range values {
+		slot, err := parseSlot(value, location)
+		if err != nil || !slot.After(now) {
+			return nil, ErrInvalidSlot
+		}
+		if !containsSlot(slots, slot) {
+			slots = append(slots, slot.UTC())
+		}
+	}
+
+	for i := 1; i < len(slots); i++ {
+		for j := i; j > 0 && slots[j].Before(slots[j-1]); j-- {
+			slots[j], slots[j-1] = slots[j-1], slots[j]
+		}
+	}
+	return slots, nil
+}
+
+func parseSlot(value string, location *time.Location) (time.Time, error) {
+	if slot, err := time.Parse(time.RFC3339, value); err == nil {
+		return slot, nil
+	}
+	for _, layout := range localLayouts {
+		if slot, err := time.ParseInLocation(layout, value, location); err == nil {
+			return slot, nil
+		}
+	}
+	return time.Time{}, ErrInvalidSlot
+}
+
+// MatchSlot returns the proposed slot chosen by the participant who accepts the interview
+func MatchSlot(slots []time.Time, value string, timezone string) (time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimezone
+	}
+
+	chosen, err := parseSlot(value, location)
+	if err != nil {
+		return time.Time{}, ErrInvalidSlot
+	}
+	for _, slot := range slots {
+		if slot.Equal(chosen) {
+			return slot, nil
+		}
+	}
+	return time.Time{}, ErrUnknownSlot
+}
+
+func containsSlot(slots []time.Time, slot time.Time) bool {
+	for _, other := range slots {
+		if other.Equal(slot) {
+			return true
+		}
+	}
+	return false
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // @Summary Créer une offre d'emploi
-// @Description Crée une nouvelle offre d'emploi (recruteurs uniquement)
+// @Description Crée une nouvelle offre d'emploi (recruteurs uniquement), publiée par défaut ou en brouillon avec state=draft
 // @Tags jobs
 // @Accept json
 // @Produce json
@@ -25,19 +25,56 @@ func CreateJobPostHandler(c *gin.Context) {
 	jobPostService.CreateJobPost(c)
 }
 
-// @Summary Lister les offres d'emploi pour candidats
-// @Description Récupère toutes les offres d'emploi disponibles (candidats uniquement)
+// @Summary Fil d'offres d'emploi du candidat
+// @Description Récupère les offres ouvertes auxquelles le candidat n'a pas encore postulé, triées par pertinence (compétences, contrat, localisation et fraîcheur)
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} map[string]interface{} "Liste des offres d'emploi"
+// @Param cursor query string false "Curseur next_cursor de la page précédente"
+// @Param limit query int false "Nombre d'offres par page (20 par défaut, 50 au maximum)"
+// @Success 200 {object} models.JobFeedPage "Page du fil d'offres"
+// @Failure 400 {object} map[string]string "Curseur invalide"
 // @Failure 401 {object} map[string]string "Non autorisé"
 // @Failure 403 {object} map[string]string "Accès refusé - candidats uniquement"
 // @Router /jobpost/candidate [get]
-func GetAllJobPostsHandler(c *gin.Context) {
+func GetJobFeedHandler(c *gin.Context) {
 	jobPostService := NewJobPostService()
-	jobPostService.GetAll(c)
+	jobPostService.GetFeed(c)
+}
+
+// @Summary Rechercher des offres d'emploi
+// @Description Recherche plein texte (français et anglais) dans le titre et la description des offres ouvertes, avec filtres et nombre d'offres par type de contrat, compétence et entreprise (chaque facette ignore son propre filtre)
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Texte recherché, syntaxe web (mots exacts entre guillemets, -exclu, or)"
+// @Param location query string false "Localisation"
+// @Param near query string false "Ville au centre du rayon"
+// @Param lat query number false "Latitude du centre du rayon"
+// @Param lng query number false "Longitude du centre du rayon"
+// @Param radius_km query number false "Rayon de recherche en kilomètres, les offres en télétravail sont toujours incluses"
+// @Param work_mode query []string false "Modes de travail : onsite, hybrid, remote" collectionFormat(multi)
+// @Param contract query []string false "Types de contrat : CDI, CDD, STAGE, ALTERNANCE, FREELANCE, INTERIM" collectionFormat(multi)
+// @Param max_duration_months query int false "Durée maximale du contrat en mois (exclut les CDI)"
+// @Param salary_min query int false "Salaire minimum souhaité"
+// @Param salary_max query int false "Salaire maximum souhaité"
+// @Param salary_period query string false "Période des salaires souhaités : hourly, monthly ou yearly (par défaut)"
+// @Param salary_currency query string false "Devise des salaires (EUR par défaut dès qu'un salaire est filtré)"
+// @Param skills query []int false "IDs des compétences" collectionFormat(multi)
+// @Param skills_mode query string false "all (toutes les compétences, par défaut) ou any (au moins une)"
+// @Param company_id query int false "ID de l'entreprise"
+// @Param posted_since query string false "Publiées depuis (2006-01-02)"
+// @Param sort query string false "relevance (par défaut), date, salary (salaire annuel décroissant) ou distance (depuis le centre du rayon)"
+// @Param page query int false "Page (1 par défaut)"
+// @Param limit query int false "Nombre d'offres par page (20 par défaut, 50 au maximum)"
+// @Success 200 {object} models.JobSearchPage "Offres trouvées, total et facettes"
+// @Failure 400 {object} map[string]string "Filtres invalides"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Router /jobpost/search [get]
+func SearchJobPostsHandler(c *gin.Context) {
+	jobPostService := NewJobPostService()
+	jobPostService.SearchJobPosts(c)
 }
 
 // @Summary Lister les offres d'emploi de l'entreprise
@@ -56,7 +93,7 @@ func GetJobPostsByCompanyHandler(c *gin.Context) {
 }
 
 // @Summary Récupérer une offre d'emploi par ID
-// @Description Récupère les détails d'une offre d'emploi spécifique
+// @Description Récupère les détails d'une offre d'emploi spécifique, les brouillons ne sont pas publics
 // @Tags jobs
 // @Accept json
 // @Produce json
@@ -69,15 +106,89 @@ func GetJobPostByIdHandler(c *gin.Context) {
 	jobPostService := NewJobPostService()
 	params := utils.GetUrlParams(c)
 	jobPostId, _ := utils.GetId(c)
-	jobpost, _ := jobPostService.GetByID(uint(jobPostId), &params.Populate)
+	jobpost, err := jobPostService.GetByID(uint(jobPostId), &params.Populate)
+	if err != nil || jobpost.State == models.DraftJobPost {
+		c.JSON(404, gin.H{"error": "Job post not found"})
+		return
+	}
 	c.JSON(200, jobpost)
 }
 
+// @Summary Modifier une offre d'emploi
+// @Description Modifie les champs donnés d'une offre de l'entreprise. Les scores des candidatures sont recalculés si les exigences changent, une offre expirée dont la date d'expiration est repoussée est republiée
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de l'offre d'emploi"
+// @Param jobData body jobPostDto.UpdateJobPostDTO true "Champs à modifier"
+// @Success 200 {object} models.JobPost "Offre d'emploi modifiée"
+// @Failure 400 {object} map[string]string "Erreur de validation ou date d'expiration passée"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Offre d'emploi non trouvée"
+// @Router /jobpost/{id} [put]
+func UpdateJobPostHandler(c *gin.Context) {
+	jobPostService := NewJobPostService()
+	jobPostService.UpdateJobPost(c)
+}
+
+// @Summary Publier une offre d'emploi
+// @Description Publie un brouillon ou rouvre une offre fermée ou expirée, la date d'expiration doit être dans le futur
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de l'offre d'emploi"
+// @Success 200 {object} models.JobPost "Offre d'emploi publiée"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Offre d'emploi non trouvée"
+// @Failure 409 {object} map[string]string "Offre déjà publiée ou date d'expiration passée"
+// @Router /jobpost/{id}/publish [post]
+func PublishJobPostHandler(c *gin.Context) {
+	jobPostService := NewJobPostService()
+	jobPostService.PublishJobPost(c)
+}
+
+// @Summary Fermer une offre d'emploi
+// @Description Ferme une offre pourvue, ses candidatures en attente sont fermées et les candidats notifiés. Les candidatures matchées restent matchées
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de l'offre d'emploi"
+// @Success 200 {object} map[string]interface{} "Offre fermée et nombre de candidatures fermées"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Offre d'emploi non trouvée"
+// @Failure 409 {object} map[string]string "L'offre n'est pas publiée"
+// @Router /jobpost/{id}/close [post]
+func CloseJobPostHandler(c *gin.Context) {
+	jobPostService := NewJobPostService()
+	jobPostService.CloseJobPost(c)
+}
+
+// @Summary Supprimer une offre d'emploi
+// @Description Supprime une offre et ses candidatures et prévient leurs candidats, une offre publiée doit d'abord être fermée et une offre avec des matchs ne peut pas être supprimée
+// @Tags jobs
+// @Security BearerAuth
+// @Param id path int true "ID de l'offre d'emploi"
+// @Success 204 "Offre d'emploi supprimée"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs de l'entreprise uniquement"
+// @Failure 404 {object} map[string]string "Offre d'emploi non trouvée"
+// @Failure 409 {object} map[string]string "L'offre est encore publiée ou a des candidatures matchées"
+// @Router /jobpost/{id} [delete]
+func DeleteJobPostHandler(c *gin.Context) {
+	jobPostService := NewJobPostService()
+	jobPostService.DeleteJobPost(c)
+}
+
 func AddRoutes(r *gin.Engine) {
 	jp := r.Group("/jobpost")
 	jp.POST("", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), CreateJobPostHandler)
 	jp.POST("/", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), CreateJobPostHandler)
-	jp.GET("/candidate", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), GetAllJobPostsHandler)
+	jp.GET("/candidate", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), GetJobFeedHandler)
+	jp.GET("/search", middleware.AuthMiddleware(), SearchJobPostsHandler)
 	jp.GET("/company", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), GetJobPostsByCompanyHandler)
 	jp.GET("/:id", GetJobPostByIdHandler)
+	jp.PUT("/:id", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), UpdateJobPostHandler)
+	jp.POST("/:id/publish", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), PublishJobPostHandler)
+	jp.POST("/:id/close", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), CloseJobPostHandler)
+	jp.DELETE("/:id", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), DeleteJobPostHandler)
 }
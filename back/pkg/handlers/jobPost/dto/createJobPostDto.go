@@ -1,19 +1,26 @@
 package jobPostDto
 
 import (
+	"skillly/pkg/models"
 	"skillly/pkg/utils"
 	"time"
 )
 
 type CreateJobPostDTO struct {
-	Description     string             `json:"description" binding:"required"`
-	Title           string             `json:"title" binding:"required"`
-	Location        string             `json:"location" binding:"required"`
-	Contract_type   utils.ContractType `json:"contract_type" binding:"required"`
-	Salary_range    string             `json:"salary_range" binding:"required"`
-	Expiration_Date time.Time          `json:"expiration_date" binding:"required"`
-	FileID          *uint              `json:"file_id"`
-	CompanyID       uint               `json: company_id`
+	Description     string               `json:"description" binding:"required"`
+	Title           string               `json:"title" binding:"required"`
+	Location        string               `json:"location" binding:"required"`
+	WorkMode        utils.WorkMode       `json:"work_mode" binding:"omitempty,oneof=onsite hybrid remote"` // On site by default
+	Contract_type   utils.ContractType   `json:"contract_type" binding:"required,contract_type"`
+	ContractTerms   models.ContractTerms `json:"contract_terms"`                                 // Duration of the fixed-term contracts, rhythm of the ALTERNANCE
+	Salary_range    string               `json:"salary_range" binding:"required_without=Salary"` // Parsed when the salary is not given
+	Salary          *models.Salary       `json:"salary"`
+	ExperienceYear  int                  `json:"experience_year" binding:"min=0"`
+	Expiration_Date time.Time            `json:"expiration_date" binding:"required"`
+	FileID          *uint                `json:"file_id"`
+	CompanyID       uint                 `json:"company_id"`
+	RecruiterID     *uint                `json:"-"`
+	State           utils.JobPostState   `json:"state" binding:"omitempty,oneof=draft published"` // Published by default
 
 	Certifications []uint `json:"certifications"`
 	Skills         []uint `json:"skills"`
@@ -0,0 +1,13 @@
+package jobPostDto
+
+const (
+	DefaultFeedLimit = 20
+	MaxFeedLimit     = 50
+)
+
+// JobFeedQuery selects a page of the job feed of a candidate
+// the cursor is the next_cursor of the previous page
+type JobFeedQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,52 @@
+package jobPostDto
+
+import (
+	"skillly/pkg/utils"
+	"time"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+
+	// A job post must require every selected skill
+	MatchAll = "all"
+	// A job post must require at least one selected skill
+	MatchAny = "any"
+
+	// The job posts are sorted by relevance of the text, then from the newest
+	SortByRelevance = "relevance"
+	// The job posts are sorted from the newest
+	SortByDate = "date"
+	// The job posts are sorted from the best paid over a year, then from the newest
+	SortBySalary = "salary"
+	// The job posts are sorted from the nearest to the center of the radius, then from the newest
+	SortByDistance = "distance"
+)
+
+// SearchJobPostsDTO filters the open job posts, the text is searched in their title and description
+// in French and in English and the results are ranked by relevance when it is given. The salary bounds
+// are paid every salary_period, yearly by default, and are compared in the salary_currency, EUR by default.
+// The radius is searched around near, or around lat and lng, the remote job posts are found at any distance
+type SearchJobPostsDTO struct {
+	Query          string               `form:"q" binding:"max=200"`
+	Location       string               `form:"location"`
+	Near           string               `form:"near"`
+	Latitude       *float64             `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Longitude      *float64             `form:"lng" binding:"omitempty,min=-180,max=180"`
+	RadiusKm       float64              `form:"radius_km" binding:"omitempty,gt=0,max=1000"`
+	WorkModes      []utils.WorkMode     `form:"work_mode"`
+	Contracts      []utils.ContractType `form:"contract" binding:"dive,contract_type"`
+	MaxDuration    int                  `form:"max_duration_months" binding:"omitempty,min=1"` // Only the fixed-term contracts and missions
+	SalaryMin      int                  `form:"salary_min" binding:"omitempty,min=0"`
+	SalaryMax      int                  `form:"salary_max" binding:"omitempty,min=0"`
+	SalaryPeriod   utils.SalaryPeriod   `form:"salary_period" binding:"omitempty,oneof=hourly monthly yearly"`
+	SalaryCurrency string               `form:"salary_currency" binding:"omitempty,len=3,uppercase"`
+	Skills         []uint               `form:"skills"`
+	SkillsMode     string               `form:"skills_mode" binding:"omitempty,oneof=all any"`
+	CompanyID      uint                 `form:"company_id"`
+	PostedSince    *time.Time           `form:"posted_since" time_format:"2006-01-02"`
+	Sort           string               `form:"sort" binding:"omitempty,oneof=relevance date salary distance"`
+	Page           int                  `form:"page" binding:"omitempty,min=1"`
+	Limit          int                  `form:"limit" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,26 @@
+package jobPostDto
+
+import (
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+	"time"
+)
+
+// UpdateJobPostDTO updates the given fields of a job post, the skills and certifications replace the previous ones.
+// The contract terms are reset when the contract type changes without them
+type UpdateJobPostDTO struct {
+	Description     *string               `json:"description" binding:"omitempty,min=1"`
+	Title           *string               `json:"title" binding:"omitempty,min=1"`
+	Location        *string               `json:"location" binding:"omitempty,min=1"`
+	WorkMode        *utils.WorkMode       `json:"work_mode" binding:"omitempty,oneof=onsite hybrid remote"`
+	Contract_type   *utils.ContractType   `json:"contract_type" binding:"omitempty,contract_type"`
+	ContractTerms   *models.ContractTerms `json:"contract_terms"`
+	Salary_range    *string               `json:"salary_range" binding:"omitempty,min=1"` // Parsed when the salary is not given
+	Salary          *models.Salary        `json:"salary"`
+	ExperienceYear  *int                  `json:"experience_year" binding:"omitempty,min=0"`
+	Expiration_Date *time.Time            `json:"expiration_date"`
+	FileID          *uint                 `json:"file_id"`
+
+	Certifications *[]uint `json:"certifications"`
+	Skills         *[]uint `json:"skills"`
+}
@@ -1,26 +1,61 @@
 package jobPost
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"skillly/pkg/handlers/applicationState"
 	jobPostDto "skillly/pkg/handlers/jobPost/dto"
 	"skillly/pkg/models"
+	"skillly/pkg/scoring"
+	"skillly/pkg/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidCursor     = errors.New("Invalid cursor")
+	ErrInvalidTransition = errors.New("Invalid job post state transition")
+	ErrExpirationPassed  = errors.New("The expiration date must be in the future")
+	ErrJobPostMatched    = errors.New("The job post still has matched applications")
 )
 
 type JobPostRepository interface {
 	models.Repository[models.JobPost]
 	CreateJobPost(dto jobPostDto.CreateJobPostDTO, tx *gorm.DB) (models.JobPost, error)
+	GetFeed(candidateID uint, query jobPostDto.JobFeedQuery, tx *gorm.DB) (models.JobFeedPage, error)
+	UpdateJobPost(jobPostID uint, dto jobPostDto.UpdateJobPostDTO, tx *gorm.DB) (models.JobPost, error)
+	Transition(jobPostID uint, state utils.JobPostState, tx *gorm.DB) (models.JobPost, error)
+	CloseJobPost(jobPostID uint, actorID *uint, tx *gorm.DB) (models.JobPost, []models.Application, error)
+	DeleteJobPost(jobPostID uint, tx *gorm.DB) ([]uint, error)
+	ExpireJobPosts(now time.Time, tx *gorm.DB) (int64, error)
+	CloseStaleApplications(now time.Time, tx *gorm.DB) ([]models.Application, error)
+	SearchJobPosts(dto jobPostDto.SearchJobPostsDTO, tx *gorm.DB) (models.JobSearchPage, error)
+}
+
+// feedCursor is the position of the last job post of a page, the ranking
+// is computed at AsOf for every page so that it does not move between pages
+type feedCursor struct {
+	AsOf      int64   `json:"as_of"`
+	Relevance float64 `json:"relevance"`
+	ID        uint    `json:"id"`
 }
 
 type jobPostRepository struct {
 	models.Repository[models.JobPost]
-	db *gorm.DB
+	db                         *gorm.DB
+	applicationStateRepository applicationState.ApplicationStateRepository // To close the applications of the closed job posts
 }
 
 func NewJobPostRepository(db *gorm.DB) JobPostRepository {
 	return &jobPostRepository{
-		Repository: models.NewRepository[models.JobPost](db),
-		db:         db,
+		Repository:                 models.NewRepository[models.JobPost](db),
+		db:                         db,
+		applicationStateRepository: applicationState.NewApplicationStateRepository(db),
 	}
 }
 
@@ -30,11 +65,28 @@ func (r *jobPostRepository) CreateJobPost(dto jobPostDto.CreateJobPostDTO, tx *g
 		Description:     dto.Description,
 		Title:           dto.Title,
 		Location:        dto.Location,
+		Coordinates:     models.Locate(dto.Location),
+		WorkMode:        dto.WorkMode,
 		Contract_type:   dto.Contract_type,
-		Salary_range:    dto.Salary_range,
+		ContractTerms:   dto.ContractTerms,
+		ExperienceYear:  dto.ExperienceYear,
 		Expiration_Date: dto.Expiration_Date,
 		FileID:          dto.FileID,
 		CompanyID:       dto.CompanyID,
+		RecruiterID:     dto.RecruiterID,
+		State:           dto.State,
+	}
+	if jobPost.State == "" {
+		jobPost.State = models.PublishedJobPost
+	}
+	if jobPost.WorkMode == "" {
+		jobPost.WorkMode = models.OnSiteWork
+	}
+	if err := jobPost.ContractTerms.Validate(jobPost.Contract_type); err != nil {
+		return models.JobPost{}, err
+	}
+	if err := setSalary(&jobPost, dto.Salary, &dto.Salary_range); err != nil {
+		return models.JobPost{}, err
 	}
 
 	createdJobPost := tx.Create(&jobPost)
@@ -43,26 +95,341 @@ func (r *jobPostRepository) CreateJobPost(dto jobPostDto.CreateJobPostDTO, tx *g
 	}
 
 	if len(dto.Skills) > 0 {
-		var skills []models.Skill
-		if err := tx.Where("id IN ?", dto.Skills).Find(&skills).Error; err != nil {
+		if err := replaceSkills(&jobPost, dto.Skills, tx); err != nil {
 			return models.JobPost{}, err
 		}
+	}
 
-		if err := tx.Model(&jobPost).Association("Skills").Replace(skills); err != nil {
+	if len(dto.Certifications) > 0 {
+		if err := replaceCertifications(&jobPost, dto.Certifications, tx); err != nil {
 			return models.JobPost{}, err
 		}
 	}
 
-	if len(dto.Certifications) > 0 {
-		var certifications []models.Certification
-		if err := tx.Where("id IN ?", dto.Certifications).Find(&certifications).Error; err != nil {
+	return jobPost, nil
+}
+
+// UpdateJobPost updates the given fields of a job post, an expired job post whose
+// expiration date is pushed back is published again
+func (r *jobPostRepository) UpdateJobPost(jobPostID uint, dto jobPostDto.UpdateJobPostDTO, tx *gorm.DB) (models.JobPost, error) {
+	var jobPost models.JobPost
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&jobPost, jobPostID).Error; err != nil {
+		return models.JobPost{}, err
+	}
+
+	if dto.Description != nil {
+		jobPost.Description = *dto.Description
+	}
+	if dto.Title != nil {
+		jobPost.Title = *dto.Title
+	}
+	if dto.Location != nil {
+		jobPost.Location = *dto.Location
+		jobPost.Coordinates = models.Locate(jobPost.Location)
+	}
+	if dto.WorkMode != nil {
+		jobPost.WorkMode = *dto.WorkMode
+	}
+	if dto.Contract_type != nil && *dto.Contract_type != jobPost.Contract_type {
+		jobPost.Contract_type = *dto.Contract_type
+		jobPost.ContractTerms = models.ContractTerms{}
+	}
+	if dto.ContractTerms != nil {
+		jobPost.ContractTerms = *dto.ContractTerms
+	}
+	if dto.Contract_type != nil || dto.ContractTerms != nil {
+		if err := jobPost.ContractTerms.Validate(jobPost.Contract_type); err != nil {
 			return models.JobPost{}, err
 		}
+	}
+	if dto.Salary != nil || dto.Salary_range != nil {
+		if err := setSalary(&jobPost, dto.Salary, dto.Salary_range); err != nil {
+			return models.JobPost{}, err
+		}
+	}
+	if dto.ExperienceYear != nil {
+		jobPost.ExperienceYear = *dto.ExperienceYear
+	}
+	if dto.FileID != nil {
+		jobPost.FileID = dto.FileID
+	}
+	if dto.Expiration_Date != nil {
+		if !dto.Expiration_Date.After(time.Now()) {
+			return models.JobPost{}, ErrExpirationPassed
+		}
+		jobPost.Expiration_Date = *dto.Expiration_Date
+		if jobPost.State == models.ExpiredJobPost {
+			jobPost.State = models.PublishedJobPost
+		}
+	}
+
+	if err := tx.Omit(clause.Associations).Save(&jobPost).Error; err != nil {
+		return models.JobPost{}, err
+	}
 
-		if err := tx.Model(&jobPost).Association("Certifications").Replace(certifications); err != nil {
+	if dto.Skills != nil {
+		if err := replaceSkills(&jobPost, *dto.Skills, tx); err != nil {
+			return models.JobPost{}, err
+		}
+	}
+	if dto.Certifications != nil {
+		if err := replaceCertifications(&jobPost, *dto.Certifications, tx); err != nil {
 			return models.JobPost{}, err
 		}
 	}
 
 	return jobPost, nil
 }
+
+// setSalary sets the salary of the job post and its label, the label is parsed when the amounts are not given
+// and it describes the amounts when it is not given
+func setSalary(jobPost *models.JobPost, salary *models.Salary, label *string) error {
+	if label != nil {
+		jobPost.Salary_range = *label
+	}
+
+	if salary == nil {
+		parsed, _ := models.ParseSalary(jobPost.Salary_range)
+		jobPost.Salary = parsed
+		return nil
+	}
+
+	if err := salary.Normalize(); err != nil {
+		return err
+	}
+	jobPost.Salary = *salary
+	if label == nil || *label == "" {
+		jobPost.Salary_range = salary.String()
+	}
+	return nil
+}
+
+// Transition moves the job post to a new state if it is allowed from its current state,
+// it can only be published with an expiration date in the future
+func (r *jobPostRepository) Transition(jobPostID uint, state utils.JobPostState, tx *gorm.DB) (models.JobPost, error) {
+	var jobPost models.JobPost
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&jobPost, jobPostID).Error; err != nil {
+		return models.JobPost{}, err
+	}
+
+	if !models.CanTransitionJobPost(jobPost.State, state) {
+		return models.JobPost{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, jobPost.State, state)
+	}
+	if state == models.PublishedJobPost && !jobPost.Expiration_Date.After(time.Now()) {
+		return models.JobPost{}, ErrExpirationPassed
+	}
+
+	if err := tx.Model(&jobPost).Update("state", state).Error; err != nil {
+		return models.JobPost{}, err
+	}
+	jobPost.State = state
+
+	return jobPost, nil
+}
+
+// CloseJobPost closes a job post and its pending applications, the matched applications
+// stay matched so that the conversations can go on
+func (r *jobPostRepository) CloseJobPost(jobPostID uint, actorID *uint, tx *gorm.DB) (models.JobPost, []models.Application, error) {
+	jobPost, err := r.Transition(jobPostID, models.ClosedJobPost, tx)
+	if err != nil {
+		return models.JobPost{}, nil, err
+	}
+
+	var applicationIDs []uint
+	err = tx.Model(&models.Application{}).Where("job_post_id = ? AND state = ?", jobPostID, models.PendingApplication).
+		Pluck("id", &applicationIDs).Error
+	if err != nil {
+		return models.JobPost{}, nil, err
+	}
+
+	closed := make([]models.Application, 0, len(applicationIDs))
+	for _, applicationID := range applicationIDs {
+		application, err := r.applicationStateRepository.Transition(applicationID, models.ClosedApplication, actorID, "Job post closed", tx)
+		if err != nil {
+			return models.JobPost{}, nil, err
+		}
+		closed = append(closed, application)
+	}
+
+	return jobPost, closed, nil
+}
+
+// DeleteJobPost deletes a job post with its applications and returns the user IDs of their candidates,
+// a job post with matched applications is kept so that their conversations can go on
+func (r *jobPostRepository) DeleteJobPost(jobPostID uint, tx *gorm.DB) ([]uint, error) {
+	var jobPost models.JobPost
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&jobPost, jobPostID).Error; err != nil {
+		return nil, err
+	}
+
+	var matched int64
+	err := tx.Model(&models.Application{}).Where("job_post_id = ? AND state = ?", jobPostID, models.MatchedApplication).
+		Count(&matched).Error
+	if err != nil {
+		return nil, err
+	}
+	if matched > 0 {
+		return nil, ErrJobPostMatched
+	}
+
+	var userIDs []uint
+	err = tx.Model(&models.ProfileCandidate{}).
+		Where("id IN (?)", tx.Model(&models.Application{}).Select("candidate_id").Where("job_post_id = ?", jobPostID)).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(&jobPost).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// ExpireJobPosts moves the published job posts whose expiration date has passed to expired
+func (r *jobPostRepository) ExpireJobPosts(now time.Time, tx *gorm.DB) (int64, error) {
+	result := tx.Model(&models.JobPost{}).
+		Where("state = ? AND expiration_date <= ?", models.PublishedJobPost, now).
+		Update("state", models.ExpiredJobPost)
+	return result.RowsAffected, result.Error
+}
+
+// CloseStaleApplications closes the pending applications of the closed and expired job posts
+func (r *jobPostRepository) CloseStaleApplications(now time.Time, tx *gorm.DB) ([]models.Application, error) {
+	var applicationIDs []uint
+	err := tx.Model(&models.Application{}).
+		Joins("JOIN job_posts ON job_posts.id = applications.job_post_id").
+		Where("applications.state = ?", models.PendingApplication).
+		Where("job_posts.state IN ? OR job_posts.expiration_date <= ?", []utils.JobPostState{models.ClosedJobPost, models.ExpiredJobPost}, now).
+		Pluck("applications.id", &applicationIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	closed := make([]models.Application, 0, len(applicationIDs))
+	for _, applicationID := range applicationIDs {
+		application, err := r.applicationStateRepository.Transition(applicationID, models.ClosedApplication, nil, "Job post no longer open", tx)
+		if err != nil {
+			return nil, err
+		}
+		closed = append(closed, application)
+	}
+
+	return closed, nil
+}
+
+func replaceSkills(jobPost *models.JobPost, skillIDs []uint, tx *gorm.DB) error {
+	if len(skillIDs) == 0 {
+		return tx.Model(jobPost).Association("Skills").Clear()
+	}
+
+	var skills []models.Skill
+	if err := tx.Where("id IN ?", skillIDs).Find(&skills).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(jobPost).Association("Skills").Replace(skills)
+}
+
+func replaceCertifications(jobPost *models.JobPost, certificationIDs []uint, tx *gorm.DB) error {
+	if len(certificationIDs) == 0 {
+		return tx.Model(jobPost).Association("Certifications").Clear()
+	}
+
+	var certifications []models.Certification
+	if err := tx.Where("id IN ?", certificationIDs).Find(&certifications).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(jobPost).Association("Certifications").Replace(certifications)
+}
+
+// GetFeed returns a page of the open job posts the candidate did not apply to nor match with,
+// from the most relevant to the least relevant
+func (r *jobPostRepository) GetFeed(candidateID uint, query jobPostDto.JobFeedQuery, tx *gorm.DB) (models.JobFeedPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = jobPostDto.DefaultFeedLimit
+	}
+	if limit > jobPostDto.MaxFeedLimit {
+		limit = jobPostDto.MaxFeedLimit
+	}
+
+	var cursor *feedCursor
+	asOf := time.Now().Truncate(time.Second)
+	if query.Cursor != "" {
+		decoded, err := decodeFeedCursor(query.Cursor)
+		if err != nil {
+			return models.JobFeedPage{}, err
+		}
+		cursor = &decoded
+		asOf = time.Unix(decoded.AsOf, 0)
+	}
+
+	var candidate models.ProfileCandidate
+	if err := tx.Preload("Skills").First(&candidate, candidateID).Error; err != nil {
+		return models.JobFeedPage{}, err
+	}
+
+	// The database ranks the job posts, the cursor skips the ones of the previous pages
+	relevance, args := scoring.FeedRelevanceSQL(candidate, asOf)
+	ranked := tx.Model(&models.JobPost{}).
+		Select("job_posts.id, ("+relevance+")::float8 AS relevance", args...).
+		Where("state = ? AND expiration_date > ? AND created_at <= ?", models.PublishedJobPost, asOf, asOf).
+		Where("id NOT IN (?)", tx.Model(&models.Application{}).Select("job_post_id").Where("candidate_id = ?", candidateID)).
+		Where("id NOT IN (?)", tx.Model(&models.Match{}).Select("job_post_id").Where("candidate_id = ?", candidateID))
+
+	feed := tx.Table("(?) AS feed", ranked)
+	if cursor != nil {
+		feed = feed.Where("feed.relevance < ? OR (feed.relevance = ? AND feed.id < ?)", cursor.Relevance, cursor.Relevance, cursor.ID)
+	}
+	var ranks []struct {
+		ID        uint
+		Relevance float64
+	}
+	if err := feed.Order("feed.relevance DESC, feed.id DESC").Limit(limit + 1).Scan(&ranks).Error; err != nil {
+		return models.JobFeedPage{}, err
+	}
+
+	ids := make([]uint, 0, len(ranks))
+	for _, rank := range ranks {
+		ids = append(ids, rank.ID)
+	}
+	var jobPosts []models.JobPost
+	if err := tx.Preload("Skills").Preload("Certifications").Preload("Company").Where("id IN ?", ids).Find(&jobPosts).Error; err != nil {
+		return models.JobFeedPage{}, err
+	}
+	byID := make(map[uint]models.JobPost, len(jobPosts))
+	for _, jobPost := range jobPosts {
+		byID[jobPost.ID] = jobPost
+	}
+
+	page := models.JobFeedPage{JobPosts: make([]models.JobFeedItem, 0, len(ranks))}
+	for _, rank := range ranks {
+		page.JobPosts = append(page.JobPosts, models.JobFeedItem{JobPost: byID[rank.ID], Relevance: rank.Relevance})
+	}
+	if len(page.JobPosts) > limit {
+		page.JobPosts = page.JobPosts[:limit]
+		last := page.JobPosts[limit-1]
+		page.NextCursor = encodeFeedCursor(feedCursor{AsOf: asOf.Unix(), Relevance: last.Relevance, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+func encodeFeedCursor(cursor feedCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeFeedCursor(value string) (feedCursor, error) {
+	var cursor feedCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return feedCursor{}, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.AsOf <= 0 {
+		return feedCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
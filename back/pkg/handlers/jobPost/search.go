@@ -0,0 +1,256 @@
+package jobPost
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"skillly/pkg/geo"
+	jobPostDto "skillly/pkg/handlers/jobPost/dto"
+	"skillly/pkg/models"
+)
+
+// SearchConfigs are the text search configurations the job posts are searched with,
+// a GIN index is created for each of them when the database is migrated
+var SearchConfigs = []string{"french", "english"}
+
+// SearchDocumentSQL is the text of a job post which is searched
+const SearchDocumentSQL = "coalesce(job_posts.title, '') || ' ' || coalesce(job_posts.description, '')"
+
+// The bounds of the salaries over a year, a missing bound is the other one like models.Salary.YearlyMin
+var (
+	salaryMinSQL = "COALESCE(" + yearlySalarySQL("salary_min") + ", " + yearlySalarySQL("salary_max") + ")"
+	salaryMaxSQL = "COALESCE(" + yearlySalarySQL("salary_max") + ", " + yearlySalarySQL("salary_min") + ")"
+)
+
+// Filters of the search which have a facet, a facet ignores its own filter
+const (
+	contractFilter = "contract"
+	skillsFilter   = "skills"
+	companyFilter  = "company"
+)
+
+// SearchJobPosts returns a page of the open job posts matching the search with the facets of the results
+func (r *jobPostRepository) SearchJobPosts(dto jobPostDto.SearchJobPostsDTO, tx *gorm.DB) (models.JobSearchPage, error) {
+	now := time.Now()
+	limit := dto.Limit
+	if limit <= 0 {
+		limit = jobPostDto.DefaultSearchLimit
+	}
+	if limit > jobPostDto.MaxSearchLimit {
+		limit = jobPostDto.MaxSearchLimit
+	}
+	page := dto.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	// The center of the radius, also needed to sort by distance
+	var center *geo.Point
+	if dto.RadiusKm > 0 || dto.Sort == jobPostDto.SortByDistance {
+		point, err := geo.Center(dto.Near, dto.Latitude, dto.Longitude)
+		if err != nil {
+			return models.JobSearchPage{}, err
+		}
+		center = &point
+	}
+
+	result := models.JobSearchPage{JobPosts: []models.JobSearchItem{}}
+	if err := searchQuery(dto, "", center, now, tx).Count(&result.Total).Error; err != nil {
+		return models.JobSearchPage{}, err
+	}
+
+	// Rank and paginate the IDs, then load the job posts of the page
+	var ranks []struct {
+		ID   uint
+		Rank float64
+	}
+	query := searchQuery(dto, "", center, now, tx)
+	if dto.Query != "" {
+		query = query.Select("job_posts.id, "+rankSQL()+" AS rank", searchArgs(dto.Query)...)
+	} else {
+		query = query.Select("job_posts.id, 0 AS rank")
+	}
+	switch {
+	case dto.Sort == jobPostDto.SortByDistance:
+		query = query.Order(clause.Expr{SQL: geo.DistanceSQL("job_posts") + " ASC NULLS LAST", Vars: center.DistanceArgs()})
+	case dto.Sort == jobPostDto.SortBySalary:
+		query = query.Order(salaryMaxSQL + " DESC NULLS LAST")
+	case dto.Sort != jobPostDto.SortByDate && dto.Query != "":
+		query = query.Order("rank DESC")
+	}
+	err := query.Order("job_posts.created_at DESC, job_posts.id DESC").
+		Limit(limit).Offset((page - 1) * limit).
+		Scan(&ranks).Error
+	if err != nil {
+		return models.JobSearchPage{}, err
+	}
+
+	if len(ranks) > 0 {
+		ids := make([]uint, 0, len(ranks))
+		for _, rank := range ranks {
+			ids = append(ids, rank.ID)
+		}
+		var jobPosts []models.JobPost
+		if err := tx.Preload("Skills").Preload("Certifications").Preload("Company").Where("id IN ?", ids).Find(&jobPosts).Error; err != nil {
+			return models.JobSearchPage{}, err
+		}
+		byID := make(map[uint]models.JobPost, len(jobPosts))
+		for _, jobPost := range jobPosts {
+			byID[jobPost.ID] = jobPost
+		}
+		for _, rank := range ranks {
+			item := models.JobSearchItem{JobPost: byID[rank.ID], Rank: rank.Rank}
+			if point, ok := item.Coordinates.Point(); ok && center != nil {
+				distance := math.Round(geo.Distance(*center, point)*10) / 10
+				item.Distance = &distance
+			}
+			result.JobPosts = append(result.JobPosts, item)
+		}
+	}
+
+	facets, err := searchFacets(dto, center, now, tx)
+	if err != nil {
+		return models.JobSearchPage{}, err
+	}
+	result.Facets = facets
+
+	return result, nil
+}
+
+// searchFacets counts the job posts found per contract type, per skill and per company
+func searchFacets(dto jobPostDto.SearchJobPostsDTO, center *geo.Point, now time.Time, tx *gorm.DB) (models.JobSearchFacets, error) {
+	facets := models.JobSearchFacets{Contracts: []models.Facet{}, Skills: []models.Facet{}, Companies: []models.Facet{}}
+
+	err := searchQuery(dto, contractFilter, center, now, tx).
+		Select("job_posts.contract_type AS value, COUNT(*) AS count").
+		Group("job_posts.contract_type").
+		Order("count DESC, value").
+		Scan(&facets.Contracts).Error
+	if err != nil {
+		return models.JobSearchFacets{}, err
+	}
+
+	err = searchQuery(dto, skillsFilter, center, now, tx).
+		Joins("JOIN job_post_skills ON job_post_skills.job_post_id = job_posts.id").
+		Joins("JOIN skills ON skills.id = job_post_skills.skill_id").
+		Select("skills.id AS id, skills.name AS value, COUNT(*) AS count").
+		Group("skills.id, skills.name").
+		Order("count DESC, value").
+		Scan(&facets.Skills).Error
+	if err != nil {
+		return models.JobSearchFacets{}, err
+	}
+
+	err = searchQuery(dto, companyFilter, center, now, tx).
+		Joins("JOIN companies ON companies.id = job_posts.company_id").
+		Select("companies.id AS id, companies.company_name AS value, COUNT(*) AS count").
+		Group("companies.id, companies.company_name").
+		Order("count DESC, value").
+		Scan(&facets.Companies).Error
+	if err != nil {
+		return models.JobSearchFacets{}, err
+	}
+
+	return facets, nil
+}
+
+// searchQuery selects the open job posts matching every filter of the search but the ignored one
+func searchQuery(dto jobPostDto.SearchJobPostsDTO, ignored string, center *geo.Point, now time.Time, tx *gorm.DB) *gorm.DB {
+	query := tx.Model(&models.JobPost{}).
+		Where("job_posts.state = ? AND job_posts.expiration_date > ?", models.PublishedJobPost, now)
+
+	if dto.Query != "" {
+		query = query.Where(matchSQL(), searchArgs(dto.Query)...)
+	}
+	if dto.Location != "" {
+		query = query.Where("job_posts.location ILIKE ?", "%"+dto.Location+"%")
+	}
+	if center != nil && dto.RadiusKm > 0 {
+		query = query.Where("job_posts.work_mode = ? OR (job_posts.latitude IS NOT NULL AND "+geo.DistanceSQL("job_posts")+" <= ?)",
+			append(append([]interface{}{models.RemoteWork}, center.DistanceArgs()...), dto.RadiusKm)...)
+	}
+	if len(dto.WorkModes) > 0 {
+		query = query.Where("job_posts.work_mode IN ?", dto.WorkModes)
+	}
+	if len(dto.Contracts) > 0 && ignored != contractFilter {
+		query = query.Where("job_posts.contract_type IN ?", dto.Contracts)
+	}
+	if dto.MaxDuration > 0 {
+		query = query.Where("job_posts.contract_duration_months <= ?", dto.MaxDuration)
+	}
+	if dto.SalaryMin > 0 || dto.SalaryMax > 0 || dto.SalaryCurrency != "" {
+		// The amounts are only compared in the same currency
+		currency := dto.SalaryCurrency
+		if currency == "" {
+			currency = models.DefaultCurrency
+		}
+		query = query.Where("job_posts.salary_currency = ?", currency)
+	}
+	if dto.SalaryMin > 0 {
+		query = query.Where(salaryMaxSQL+" >= ?", models.Yearly(dto.SalaryMin, dto.SalaryPeriod))
+	}
+	if dto.SalaryMax > 0 {
+		query = query.Where(salaryMinSQL+" <= ?", models.Yearly(dto.SalaryMax, dto.SalaryPeriod))
+	}
+	if len(dto.Skills) > 0 && ignored != skillsFilter {
+		skills := tx.Table("job_post_skills").Select("job_post_id").Where("skill_id IN ?", dto.Skills).Group("job_post_id")
+		if dto.SkillsMode != jobPostDto.MatchAny {
+			skills = skills.Having("COUNT(DISTINCT skill_id) = ?", countDistinct(dto.Skills))
+		}
+		query = query.Where("job_posts.id IN (?)", skills)
+	}
+	if dto.CompanyID != 0 && ignored != companyFilter {
+		query = query.Where("job_posts.company_id = ?", dto.CompanyID)
+	}
+	if dto.PostedSince != nil {
+		query = query.Where("job_posts.created_at >= ?", *dto.PostedSince)
+	}
+
+	return query
+}
+
+// matchSQL matches the text in any of the search configurations
+func matchSQL() string {
+	conditions := make([]string, 0, len(SearchConfigs))
+	for _, config := range SearchConfigs {
+		conditions = append(conditions, fmt.Sprintf("to_tsvector('%s', %s) @@ websearch_to_tsquery('%s', ?)", config, SearchDocumentSQL, config))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// yearlySalarySQL converts a salary column of the job posts to an amount over a year like models.Yearly
+func yearlySalarySQL(column string) string {
+	return fmt.Sprintf("CASE job_posts.salary_period WHEN '%s' THEN job_posts.%s * %d WHEN '%s' THEN job_posts.%s * %d ELSE job_posts.%s END",
+		models.HourlySalary, column, models.HoursPerYear, models.MonthlySalary, column, models.MonthsPerYear, column)
+}
+
+// rankSQL ranks the job posts by the best relevance of the text among the search configurations
+func rankSQL() string {
+	ranks := make([]string, 0, len(SearchConfigs))
+	for _, config := range SearchConfigs {
+		ranks = append(ranks, fmt.Sprintf("ts_rank(to_tsvector('%s', %s), websearch_to_tsquery('%s', ?))", config, SearchDocumentSQL, config))
+	}
+	return "GREATEST(" + strings.Join(ranks, ", ") + ")"
+}
+
+// searchArgs repeats the text once per search configuration
+func searchArgs(text string) []interface{} {
+	args := make([]interface{}, 0, len(SearchConfigs))
+	for range SearchConfigs {
+		args = append(args, text)
+	}
+	return args
+}
+
+func countDistinct(ids []uint) int {
+	seen := map[uint]bool{}
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return len(seen)
+}
@@ -1,11 +1,17 @@
 package jobPost
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
+	"skillly/chat/notification"
 	"skillly/pkg/config"
+	"skillly/pkg/geo"
+	"skillly/pkg/handlers/application"
 	jobPostDto "skillly/pkg/handlers/jobPost/dto"
 	"skillly/pkg/models"
 	"skillly/pkg/utils"
@@ -13,19 +19,25 @@ import (
 
 type JobPostService interface {
 	CreateJobPost(c *gin.Context)
-	GetAll(c *gin.Context)
+	GetFeed(c *gin.Context)
+	SearchJobPosts(c *gin.Context)
 	GetByCompany(c *gin.Context)
 	GetByID(id uint, populate *[]string) (models.JobPost, error)
-	// Add other service methods as needed (e.g., UpdateJobPost, DeleteJobPost)
+	UpdateJobPost(c *gin.Context)
+	PublishJobPost(c *gin.Context)
+	CloseJobPost(c *gin.Context)
+	DeleteJobPost(c *gin.Context)
 }
 
 type jobPostService struct {
-	jobPostRepository JobPostRepository
+	jobPostRepository     JobPostRepository
+	applicationRepository application.ApplicationRepository // To rescore the applications of the updated job posts
 }
 
 func NewJobPostService() JobPostService {
 	return &jobPostService{
-		jobPostRepository: NewJobPostRepository(config.DB),
+		jobPostRepository:     NewJobPostRepository(config.DB),
+		applicationRepository: application.NewApplicationRepository(config.DB),
 	}
 }
 
@@ -42,6 +54,9 @@ func (s *jobPostService) CreateJobPost(c *gin.Context) {
 
 	// Create the job post
 	dto.CompanyID = companyId.(uint)
+	if recruiterID, ok := c.Keys["recruiter_id"].(uint); ok {
+		dto.RecruiterID = &recruiterID
+	}
 	jobPost, err := s.jobPostRepository.CreateJobPost(dto, config.DB)
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
@@ -51,15 +66,56 @@ func (s *jobPostService) CreateJobPost(c *gin.Context) {
 	c.JSON(200, jobPost)
 }
 
-func (s *jobPostService) GetAll(c *gin.Context) {
-	params := utils.GetUrlParams(c)
-	jobPosts, err := s.jobPostRepository.GetAll(params)
+// GetFeed returns the job posts recommended to the authenticated candidate
+func (s *jobPostService) GetFeed(c *gin.Context) {
+	query := jobPostDto.JobFeedQuery{}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
 
+	candidateID, ok := c.Keys["candidate_id"].(uint)
+	if !ok {
+		c.JSON(403, gin.H{"error": "Candidate ID not found in context"})
+		return
+	}
+
+	page, err := s.jobPostRepository.GetFeed(candidateID, query, config.DB)
 	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "Failed to retrieve the job feed: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, page)
+}
+
+// SearchJobPosts searches the open job posts by text and filters, with the facets of the results
+func (s *jobPostService) SearchJobPosts(c *gin.Context) {
+	dto := jobPostDto.SearchJobPostsDTO{}
+	if err := c.ShouldBindQuery(&dto); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if dto.SalaryMin > 0 && dto.SalaryMax > 0 && dto.SalaryMin > dto.SalaryMax {
+		c.JSON(400, gin.H{"error": "salary_min must not be greater than salary_max"})
+		return
 	}
 
-	c.JSON(200, jobPosts)
+	page, err := s.jobPostRepository.SearchJobPosts(dto, config.DB)
+	if err != nil {
+		if errors.Is(err, geo.ErrUnknownLocation) || errors.Is(err, geo.ErrMissingCenter) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "Failed to search job posts: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, page)
 }
 
 func (s *jobPostService) GetByCompany(c *gin.Context) {
@@ -83,6 +139,14 @@ func (s *jobPostService) GetByCompany(c *gin.Context) {
 	var jobPosts []models.JobPost
 	query.Find(&jobPosts)
 
+	// The scheduler expires the job posts periodically, the ones expired since its last run are shown expired
+	now := time.Now()
+	for i := range jobPosts {
+		if jobPosts[i].State == models.PublishedJobPost && !jobPosts[i].IsOpen(now) {
+			jobPosts[i].State = models.ExpiredJobPost
+		}
+	}
+
 	c.JSON(200, jobPosts)
 }
 
@@ -95,3 +159,197 @@ func (s *jobPostService) GetByID(id uint, populate *[]string) (models.JobPost, e
 
 	return jobPost, nil
 }
+
+// UpdateJobPost updates a job post of the recruiter's company, the scores of its applications
+// are computed again when its requirements change
+func (s *jobPostService) UpdateJobPost(c *gin.Context) {
+	jobPost, ok := s.ownedJobPost(c)
+	if !ok {
+		return
+	}
+
+	var dto jobPostDto.UpdateJobPostDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		c.JSON(500, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+
+	updated, err := s.jobPostRepository.UpdateJobPost(jobPost.ID, dto, tx)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, ErrExpirationPassed) || errors.Is(err, models.ErrInvalidSalary) || models.IsContractError(err) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "Failed to update job post: " + err.Error()})
+		return
+	}
+
+	requirementsChanged := dto.Skills != nil || dto.Certifications != nil || dto.ExperienceYear != nil ||
+		dto.Contract_type != nil || dto.ContractTerms != nil || dto.Location != nil || dto.WorkMode != nil || dto.Salary != nil || dto.Salary_range != nil
+	if requirementsChanged {
+		if err := s.applicationRepository.RescoreJobPostApplications(jobPost.ID, tx); err != nil {
+			tx.Rollback()
+			c.JSON(500, gin.H{"error": "Failed to rescore applications: " + err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, updated)
+}
+
+// PublishJobPost publishes a draft, or reopens a closed or expired job post
+func (s *jobPostService) PublishJobPost(c *gin.Context) {
+	jobPost, ok := s.ownedJobPost(c)
+	if !ok {
+		return
+	}
+
+	published, err := s.jobPostRepository.Transition(jobPost.ID, models.PublishedJobPost, config.DB)
+	if err != nil {
+		transitionError(c, err)
+		return
+	}
+
+	c.JSON(200, published)
+}
+
+// CloseJobPost closes a job post of the recruiter's company, its pending applications are closed
+// and their candidates notified
+func (s *jobPostService) CloseJobPost(c *gin.Context) {
+	jobPost, ok := s.ownedJobPost(c)
+	if !ok {
+		return
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		c.JSON(500, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+
+	actorID := c.Keys["user_id"].(uint)
+	closed, applications, err := s.jobPostRepository.CloseJobPost(jobPost.ID, &actorID, tx)
+	if err != nil {
+		tx.Rollback()
+		transitionError(c, err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	for _, app := range applications {
+		var userIDs []uint
+		err := config.DB.Model(&models.ProfileCandidate{}).Where("id = ?", app.CandidateID).Pluck("user_id", &userIDs).Error
+		if err != nil {
+			continue
+		}
+		notification.Send(userIDs, notification.Event{
+			Type:          notification.ApplicationStateEvent,
+			SenderID:      fmt.Sprint(actorID),
+			ApplicationID: app.ID,
+			JobPostID:     app.JobPostID,
+			State:         string(app.State),
+		})
+	}
+
+	c.JSON(200, gin.H{"job_post": closed, "closed_applications": len(applications)})
+}
+
+// DeleteJobPost deletes a job post of the recruiter's company with its applications and tells their candidates,
+// a published job post must be closed first and a job post with matches can't be deleted
+func (s *jobPostService) DeleteJobPost(c *gin.Context) {
+	jobPost, ok := s.ownedJobPost(c)
+	if !ok {
+		return
+	}
+
+	if jobPost.IsOpen(time.Now()) {
+		c.JSON(409, gin.H{"error": "Close the job post before deleting it"})
+		return
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		c.JSON(500, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+
+	userIDs, err := s.jobPostRepository.DeleteJobPost(jobPost.ID, tx)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, ErrJobPostMatched):
+			c.JSON(409, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(404, gin.H{"error": "Job post not found"})
+		default:
+			c.JSON(500, gin.H{"error": "Failed to delete job post: " + err.Error()})
+		}
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	notification.Send(userIDs, notification.Event{
+		Type:      notification.JobPostDeletedEvent,
+		SenderID:  fmt.Sprint(c.Keys["user_id"]),
+		JobPostID: jobPost.ID,
+	})
+
+	c.Status(204)
+}
+
+// ownedJobPost returns the job post of the URL if it belongs to the recruiter's company
+func (s *jobPostService) ownedJobPost(c *gin.Context) (models.JobPost, bool) {
+	jobPostID, err := utils.GetId(c)
+	if err != nil {
+		return models.JobPost{}, false
+	}
+
+	jobPost, err := s.jobPostRepository.GetByID(jobPostID, nil)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Job post not found"})
+		return models.JobPost{}, false
+	}
+
+	companyId := c.Keys["company_id"]
+	if jobPost.CompanyID != companyId.(uint) {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return models.JobPost{}, false
+	}
+
+	return jobPost, true
+}
+
+// transitionError answers the error of a state transition of a job post
+func transitionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrExpirationPassed):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(404, gin.H{"error": "Job post not found"})
+	default:
+		c.JSON(500, gin.H{"error": "Failed to change the job post state: " + err.Error()})
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 // @Summary Créer un match
-// @Description Permet à un recruteur de créer un match entre un candidat et une offre d'emploi
+// @Description Permet à un recruteur de créer un match à partir d'une candidature en attente de son entreprise, équivaut à un like sur le candidat
 // @Tags matches
 // @Accept json
 // @Produce json
@@ -19,12 +19,33 @@ import (
 // @Failure 401 {object} map[string]string "Non autorisé"
 // @Failure 403 {object} map[string]string "Accès refusé - recruteurs uniquement"
 // @Failure 404 {object} map[string]string "Candidat, offre d'emploi ou candidature non trouvé(e)"
+// @Failure 409 {object} map[string]string "La candidature n'est plus en attente"
 // @Router /match [post]
 func CreateMatchHandler(c *gin.Context) {
 	matchService := NewMatchService()
 	matchService.CreateMatch(c)
 }
 
+// @Summary Liker ou passer un candidat
+// @Description Enregistre le like ou le pass d'un recruteur sur un candidat pour une offre de son entreprise. Si le candidat a postulé, un like crée le match, la room de chat et notifie les deux parties, un pass refuse la candidature
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param swipeData body matchDto.SwipeDTO true "Décision du recruteur"
+// @Success 200 {object} map[string]interface{} "Décision enregistrée, pas encore de match"
+// @Success 201 {object} map[string]interface{} "Intérêt mutuel, match créé"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - recruteurs uniquement, ou like d'un candidat masqué qui n'a pas postulé dans l'entreprise"
+// @Failure 404 {object} map[string]string "Candidat ou offre d'emploi non trouvé(e)"
+// @Failure 409 {object} map[string]string "Like d'une candidature qui n'est plus en attente"
+// @Router /match/swipe [post]
+func SwipeHandler(c *gin.Context) {
+	matchService := NewMatchService()
+	matchService.Swipe(c)
+}
+
 // @Summary Récupérer mes matchs
 // @Description Permet à un utilisateur de voir tous ses matchs (candidats: leurs matches, recruteurs: matches de leurs offres)
 // @Tags matches
@@ -58,6 +79,7 @@ func AddRoutes(r *gin.Engine) {
 
 	// Protect the route: only authenticated recruiters can create matches
 	matchGroup.POST("", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), CreateMatchHandler)
+	matchGroup.POST("/swipe", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), SwipeHandler)
 
 	// Protect the route: authenticated users (both candidates and recruiters) can view their matches
 	matchGroup.GET("/me", middleware.AuthMiddleware(), GetMyMatchesHandler)
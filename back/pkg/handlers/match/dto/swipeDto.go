@@ -0,0 +1,12 @@
+package matchDto
+
+import (
+	"skillly/pkg/utils"
+)
+
+// SwipeDTO is the decision of a recruiter on a candidate for a job post of their company
+type SwipeDTO struct {
+	JobPostID   uint                `json:"job_post_id" binding:"required"`
+	CandidateID uint                `json:"candidate_id" binding:"required"`
+	Decision    utils.SwipeDecision `json:"decision" binding:"required,oneof=like pass"`
+}
@@ -1,11 +1,13 @@
 package match
 
 import (
+	"skillly/pkg/handlers/applicationState"
 	matchDto "skillly/pkg/handlers/match/dto"
 	"skillly/pkg/models"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // MatchRepository defines the interface for match data operations
@@ -14,18 +16,27 @@ type MatchRepository interface {
 	CreateMatch(dto matchDto.CreateMatchDTO, tx *gorm.DB) (models.Match, error)
 	GetCandidateMatches(candidateID uint) ([]models.Match, error)
 	GetRecruiterMatches(recruiterID uint) ([]models.Match, error)
+	GetParticipantUserIDs(matchID uint) ([]uint, error)
+	CreateMatchFromApplication(applicationID uint, actorID *uint, tx *gorm.DB) (models.Match, error)
+	SaveSwipe(swipe models.Swipe, tx *gorm.DB) (models.Swipe, error)
+	GetSwipe(jobPostID uint, candidateID uint, tx *gorm.DB) (models.Swipe, error)
+	DeleteApplicationMatches(applicationID uint, tx *gorm.DB) error
+	LockCandidacy(jobPostID uint, candidateID uint, tx *gorm.DB) error
+	CanReachCandidate(candidateID uint, companyID uint) (bool, error)
 }
 
 type matchRepository struct {
 	models.Repository[models.Match]
-	db *gorm.DB
+	db                         *gorm.DB
+	applicationStateRepository applicationState.ApplicationStateRepository
 }
 
 // NewMatchRepository creates a new instance of MatchRepository
 func NewMatchRepository(db *gorm.DB) MatchRepository {
 	return &matchRepository{
-		Repository: models.NewRepository[models.Match](db),
-		db:         db,
+		Repository:                 models.NewRepository[models.Match](db),
+		db:                         db,
+		applicationStateRepository: applicationState.NewApplicationStateRepository(db),
 	}
 }
 
@@ -84,7 +95,7 @@ func (r *matchRepository) GetRecruiterMatches(recruiterID uint) ([]models.Match,
 		Select("matches.*").
 		Joins("JOIN job_posts ON matches.job_post_id = job_posts.id").
 		Joins("JOIN profile_recruiters ON job_posts.company_id = profile_recruiters.company_id").
-		Where("profile_recruiters.id = ?", recruiterID).
+		Where("profile_recruiters.id = ? AND profile_recruiters.state = ?", recruiterID, models.ActiveState).
 		Preload("Candidate.User").
 		Preload("Candidate.Skills").
 		Preload("Candidate.Certifications").
@@ -100,3 +111,99 @@ func (r *matchRepository) GetRecruiterMatches(recruiterID uint) ([]models.Match,
 
 	return matches, nil
 }
+
+// GetParticipantUserIDs returns the user IDs of the candidate and of the active recruiters
+// of the company that owns the job post of the match (members of the chat room)
+func (r *matchRepository) GetParticipantUserIDs(matchID uint) ([]uint, error) {
+	var candidateIDs []uint
+	result := r.db.Table("matches").
+		Select("profile_candidates.user_id").
+		Joins("JOIN profile_candidates ON profile_candidates.id = matches.candidate_id").
+		Where("matches.id = ?", matchID).
+		Pluck("profile_candidates.user_id", &candidateIDs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(candidateIDs) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var recruiterIDs []uint
+	result = r.db.Table("matches").
+		Select("profile_recruiters.user_id").
+		Joins("JOIN job_posts ON job_posts.id = matches.job_post_id").
+		Joins("JOIN profile_recruiters ON profile_recruiters.company_id = job_posts.company_id").
+		Where("matches.id = ? AND profile_recruiters.state = ?", matchID, models.ActiveState).
+		Pluck("profile_recruiters.user_id", &recruiterIDs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return append(candidateIDs, recruiterIDs...), nil
+}
+
+// CreateMatchFromApplication moves a pending application to matched and matches its candidate with its job post
+func (r *matchRepository) CreateMatchFromApplication(applicationID uint, actorID *uint, tx *gorm.DB) (models.Match, error) {
+	application, err := r.applicationStateRepository.Transition(applicationID, models.MatchedApplication, actorID, "Mutual interest", tx)
+	if err != nil {
+		return models.Match{}, err
+	}
+
+	return r.CreateMatch(matchDto.CreateMatchDTO{
+		CandidateID:   application.CandidateID,
+		JobPostID:     application.JobPostID,
+		ApplicationID: application.ID,
+	}, tx)
+}
+
+// SaveSwipe records the decision of a recruiter, it replaces the previous one for the same candidate and job post
+func (r *matchRepository) SaveSwipe(swipe models.Swipe, tx *gorm.DB) (models.Swipe, error) {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_post_id"}, {Name: "candidate_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"recruiter_id", "decision", "updated_at"}),
+	}).Create(&swipe)
+	if result.Error != nil {
+		return models.Swipe{}, result.Error
+	}
+
+	return swipe, nil
+}
+
+// GetSwipe returns the decision of the recruiters on a candidate for a job post
+func (r *matchRepository) GetSwipe(jobPostID uint, candidateID uint, tx *gorm.DB) (models.Swipe, error) {
+	var swipe models.Swipe
+	err := tx.Where("job_post_id = ? AND candidate_id = ?", jobPostID, candidateID).First(&swipe).Error
+	if err != nil {
+		return models.Swipe{}, err
+	}
+
+	return swipe, nil
+}
+
+// DeleteApplicationMatches releases the matches of an application, their participants lose access to the chat room
+func (r *matchRepository) DeleteApplicationMatches(applicationID uint, tx *gorm.DB) error {
+	return tx.Where("application_id = ?", applicationID).Delete(&models.Match{}).Error
+}
+
+// LockCandidacy serializes the swipes and the applications of a candidate on a job post until the end of the transaction,
+// so that a like and an application at the same time see each other and create the match
+func (r *matchRepository) LockCandidacy(jobPostID uint, candidateID uint, tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", int32(jobPostID), int32(candidateID)).Error
+}
+
+// CanReachCandidate tells whether the recruiters of a company may show interest in a candidate,
+// the candidate is discoverable or applied to one of the job posts of the company
+func (r *matchRepository) CanReachCandidate(candidateID uint, companyID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ProfileCandidate{}).
+		Where("id = ? AND (discoverable OR EXISTS (?))", candidateID,
+			r.db.Model(&models.Application{}).Select("1").
+				Joins("JOIN job_posts ON job_posts.id = applications.job_post_id").
+				Where("applications.candidate_id = profile_candidates.id AND job_posts.company_id = ?", companyID)).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -2,16 +2,22 @@ package match
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"gorm.io/gorm"
 
 	chatConfig "skillly/chat/config"
+	"skillly/chat/handlers/receipt"
+	"skillly/chat/handlers/room"
 	"skillly/chat/models"
+	"skillly/chat/notification"
 	"skillly/pkg/config"
-	applicationHandler "skillly/pkg/handlers/application"
+	"skillly/pkg/handlers/applicationState"
 	matchDto "skillly/pkg/handlers/match/dto"
 	pkgModels "skillly/pkg/models"
 )
@@ -19,25 +25,28 @@ import (
 // MatchService defines the interface for match business logic
 type MatchService interface {
 	CreateMatch(c *gin.Context)
+	Swipe(c *gin.Context)
 	GetCandidateMatches(c *gin.Context)
 	GetMyMatches(c *gin.Context)
 	GetRoomsWithLastMessage(c *gin.Context)
 }
 
 type matchService struct {
-	matchRepository       MatchRepository
-	applicationRepository applicationHandler.ApplicationRepository // To update application state
+	matchRepository            MatchRepository
+	applicationStateRepository applicationState.ApplicationStateRepository // To reject the applications passed on
+	receiptRepository          receipt.ReceiptRepository                   // To count unread messages
 }
 
 // NewMatchService creates a new instance of MatchService
 func NewMatchService() MatchService {
 	return &matchService{
-		matchRepository:       NewMatchRepository(config.DB),
-		applicationRepository: applicationHandler.NewApplicationRepository(config.DB),
+		matchRepository:            NewMatchRepository(config.DB),
+		applicationStateRepository: applicationState.NewApplicationStateRepository(config.DB),
+		receiptRepository:          receipt.NewReceiptRepository(chatConfig.DBMongo),
 	}
 }
 
-// CreateMatch handles the creation of a new match
+// CreateMatch matches the candidate of an application, the recruiter likes the candidate
 func (s *matchService) CreateMatch(c *gin.Context) {
 	dto := matchDto.CreateMatchDTO{}
 	err := c.ShouldBindJSON(&dto)
@@ -46,41 +55,181 @@ func (s *matchService) CreateMatch(c *gin.Context) {
 		return
 	}
 
-	// Check if the recruiter is authorized to create a match for this job post
-	// (Implicitly checked by middleware ensuring they own the company associated with the job post,
-	// but could add explicit check here if needed)
+	var application pkgModels.Application
+	if err := config.DB.Preload("JobPost").First(&application, dto.ApplicationID).Error; err != nil {
+		c.JSON(404, gin.H{"error": "Application not found"})
+		return
+	}
+	if application.CandidateID != dto.CandidateID || application.JobPostID != dto.JobPostID {
+		c.JSON(400, gin.H{"error": "The candidate and the job post do not match the application"})
+		return
+	}
+	companyId := c.Keys["company_id"]
+	if application.JobPost.CompanyID != companyId.(uint) {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	s.swipe(c, matchDto.SwipeDTO{
+		JobPostID:   application.JobPostID,
+		CandidateID: application.CandidateID,
+		Decision:    pkgModels.LikeSwipe,
+	})
+}
+
+// Swipe records the like or pass of a recruiter on a candidate for a job post,
+// the match is created when the candidate applied to the job post and the recruiter likes them
+func (s *matchService) Swipe(c *gin.Context) {
+	dto := matchDto.SwipeDTO{}
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	var jobPost pkgModels.JobPost
+	if err := config.DB.First(&jobPost, dto.JobPostID).Error; err != nil {
+		c.JSON(404, gin.H{"error": "Job post not found"})
+		return
+	}
+	companyId := c.Keys["company_id"]
+	if jobPost.CompanyID != companyId.(uint) {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return
+	}
+	if err := config.DB.First(&pkgModels.ProfileCandidate{}, dto.CandidateID).Error; err != nil {
+		c.JSON(404, gin.H{"error": "Candidate not found"})
+		return
+	}
+	// A candidate who opted out of the search is only liked after applying to the company
+	if dto.Decision == pkgModels.LikeSwipe {
+		reachable, err := s.matchRepository.CanReachCandidate(dto.CandidateID, companyId.(uint))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if !reachable {
+			c.JSON(403, gin.H{"error": "The candidate is not discoverable"})
+			return
+		}
+	}
+
+	s.swipe(c, dto)
+}
+
+func (s *matchService) swipe(c *gin.Context, dto matchDto.SwipeDTO) {
+	recruiterId := c.Keys["recruiter_id"]
+	userId, _ := c.Keys["user_id"].(uint)
 
-	// Start a transaction
 	tx := config.DB.Begin()
 	if tx.Error != nil {
 		c.JSON(500, gin.H{"error": "Failed to start transaction"})
 		return
 	}
 
-	// 1. Create the match
-	match, err := s.matchRepository.CreateMatch(dto, tx)
-	if err != nil {
+	if err := s.matchRepository.LockCandidacy(dto.JobPostID, dto.CandidateID, tx); err != nil {
 		tx.Rollback()
-		c.JSON(400, gin.H{"error": "Failed to create match: " + err.Error()})
+		c.JSON(500, gin.H{"error": "Failed to lock the candidacy: " + err.Error()})
 		return
 	}
 
-	// 2. Update the application state to "matched"
-	err = s.applicationRepository.UpdateApplicationState(dto.ApplicationID, "matched", tx)
+	// The candidate shows interest by applying
+	var application pkgModels.Application
+	err := tx.Where("job_post_id = ? AND candidate_id = ?", dto.JobPostID, dto.CandidateID).First(&application).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": "Failed to get the application: " + err.Error()})
+		return
+	}
+	if application.ID != 0 && application.State != pkgModels.PendingApplication && dto.Decision == pkgModels.LikeSwipe {
+		tx.Rollback()
+		c.JSON(409, gin.H{"error": "The application is " + string(application.State) + ", it cannot be matched"})
+		return
+	}
+
+	swipe, err := s.matchRepository.SaveSwipe(pkgModels.Swipe{
+		JobPostID:   dto.JobPostID,
+		CandidateID: dto.CandidateID,
+		RecruiterID: recruiterId.(uint),
+		Decision:    dto.Decision,
+	}, tx)
 	if err != nil {
 		tx.Rollback()
-		c.JSON(400, gin.H{"error": "Failed to update application state: " + err.Error()})
+		c.JSON(500, gin.H{"error": "Failed to save the decision: " + err.Error()})
 		return
 	}
 
-	// Commit the transaction
+	var match *pkgModels.Match
+	rejected := false
+	if application.State == pkgModels.PendingApplication {
+		switch dto.Decision {
+		case pkgModels.LikeSwipe:
+			created, err := s.matchRepository.CreateMatchFromApplication(application.ID, &userId, tx)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(400, gin.H{"error": "Failed to create match: " + err.Error()})
+				return
+			}
+			match = &created
+		case pkgModels.PassSwipe:
+			_, err := s.applicationStateRepository.Transition(application.ID, pkgModels.RejectedApplication, &userId, "Passed by a recruiter", tx)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(500, gin.H{"error": "Failed to update application state: " + err.Error()})
+				return
+			}
+			rejected = true
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback() // Ensure rollback on commit error
 		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
 		return
 	}
 
-	c.JSON(201, match) // Return 201 Created status with the created match
+	if match != nil {
+		Announce(*match)
+		c.JSON(201, gin.H{"swipe": swipe, "match": match})
+		return
+	}
+
+	if rejected {
+		var userIDs []uint
+		err := config.DB.Model(&pkgModels.ProfileCandidate{}).Where("id = ?", application.CandidateID).Pluck("user_id", &userIDs).Error
+		if err == nil {
+			notification.Send(userIDs, notification.Event{
+				Type:          notification.ApplicationStateEvent,
+				SenderID:      fmt.Sprint(c.Keys["user_id"]),
+				ApplicationID: application.ID,
+				JobPostID:     application.JobPostID,
+				State:         string(pkgModels.RejectedApplication),
+			})
+		}
+	}
+
+	c.JSON(200, gin.H{"swipe": swipe, "match": nil})
+}
+
+// Announce opens the chat room of a new match and tells both sides, the room of the chat is the match
+func Announce(match pkgModels.Match) {
+	roomID := fmt.Sprint(match.ID)
+	if _, err := room.NewRoomRepository(chatConfig.DBMongo).CreateRoom(roomID); err != nil {
+		log.Printf("error creating the room of match %d: %v", match.ID, err)
+	}
+
+	members, err := NewMatchRepository(config.DB).GetParticipantUserIDs(match.ID)
+	if err != nil {
+		log.Printf("error getting the participants of match %d: %v", match.ID, err)
+		return
+	}
+	notification.Send(members, notification.Event{
+		Type:          notification.NewMatchEvent,
+		RoomID:        roomID,
+		MatchID:       match.ID,
+		ApplicationID: match.ApplicationID,
+		JobPostID:     match.JobPostID,
+		State:         string(pkgModels.MatchedApplication),
+	})
 }
 
 // GetMyMatches handles retrieving matches for the authenticated user (candidates or recruiters)
@@ -228,6 +377,8 @@ func (s *matchService) GetRoomsWithLastMessage(c *gin.Context) {
 		return
 	}
 
+	userID := fmt.Sprint(c.Keys["user_id"])
+
 	// Pour chaque match, récupérer le dernier message MongoDB
 	var rooms []map[string]interface{}
 	for _, match := range matches {
@@ -236,7 +387,7 @@ func (s *matchService) GetRoomsWithLastMessage(c *gin.Context) {
 		// Query MongoDB pour le dernier message de la room
 		collection := chatConfig.DBMongo.Collection("message")
 		filter := bson.M{"room": fmt.Sprintf("%v", roomID)} // Forcer string
-		opts := options.FindOne().SetSort(bson.D{{"created_at", -1}})
+		opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
 		var msg models.Message
 		err := collection.FindOne(context.TODO(), filter, opts).Decode(&msg)
 		if err == nil {
@@ -244,8 +395,15 @@ func (s *matchService) GetRoomsWithLastMessage(c *gin.Context) {
 				"content": msg.Content,
 				"sender":  msg.SenderID,
 				"sent_at": msg.CreatedAt,
+				"deleted": msg.DeletedAt != nil,
 			}
 		}
+		// Nombre de messages des autres participants après le curseur de lecture
+		unreadCount, err := s.receiptRepository.CountUnread(fmt.Sprintf("%v", roomID), userID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to count unread messages: " + err.Error()})
+			return
+		}
 		room := map[string]interface{}{
 			"id":         match.ID,
 			"name":       match.JobPost.Title,
@@ -254,8 +412,9 @@ func (s *matchService) GetRoomsWithLastMessage(c *gin.Context) {
 				"candidate": match.Candidate,
 				"recruiter": match.JobPost.Company,
 			},
-			"jobPost":     match.JobPost,
-			"lastMessage": lastMessage,
+			"jobPost":      match.JobPost,
+			"lastMessage":  lastMessage,
+			"unread_count": unreadCount,
 		}
 		rooms = append(rooms, room)
 	}
@@ -6,8 +6,9 @@ import (
 )
 
 type CreateRecruiterDTO struct {
-	Title     string            `json:"title"`
-	CompanyID uint              `json:"company"`
-	Role      utils.CompanyRole `json:"role"`
-	User      models.User       `json:"user"`
+	Title     string               `json:"title"`
+	CompanyID uint                 `json:"company"`
+	Role      utils.CompanyRole    `json:"role"`
+	State     utils.RecruiterState `json:"state"`
+	User      models.User          `json:"user"`
 }
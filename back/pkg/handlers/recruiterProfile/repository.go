@@ -1,15 +1,30 @@
 package recruiter
 
 import (
+	"errors"
+
 	recruiterDto "skillly/pkg/handlers/recruiterProfile/dto"
 	"skillly/pkg/models"
+	"skillly/pkg/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRequestAnswered     = errors.New("The join request has already been answered")
+	ErrLastAdmin           = errors.New("A company must keep at least one admin")
+	ErrInvalidReassignment = errors.New("The job posts must be reassigned to another active recruiter of the company")
 )
 
 type RecruiterRepository interface {
 	models.Repository[models.ProfileRecruiter]
 	CreateRecruiter(dto recruiterDto.CreateRecruiterDTO, tx *gorm.DB) (models.ProfileRecruiter, error)
+	GetState(id uint) (utils.RecruiterState, error)
+	GetCompanyRecruiters(companyID uint, state utils.RecruiterState) ([]models.ProfileRecruiter, error)
+	AnswerJoinRequest(id uint, companyID uint, state utils.RecruiterState, tx *gorm.DB) (models.ProfileRecruiter, error)
+	ChangeRole(id uint, companyID uint, role utils.CompanyRole, tx *gorm.DB) (models.ProfileRecruiter, error)
+	RemoveRecruiter(id uint, companyID uint, reassignTo uint, tx *gorm.DB) (models.ProfileRecruiter, int64, error)
 }
 
 type recruiterRepository struct {
@@ -32,6 +47,7 @@ func (r *recruiterRepository) CreateRecruiter(dto recruiterDto.CreateRecruiterDT
 		CompanyID: dto.CompanyID,
 		UserID:    dto.User.ID,
 		Role:      dto.Role,
+		State:     dto.State,
 	}
 
 	createdRecruiter := tx.Create(&recruiter)
@@ -42,3 +58,126 @@ func (r *recruiterRepository) CreateRecruiter(dto recruiterDto.CreateRecruiterDT
 
 	return recruiter, nil
 }
+
+// GetState returns the current state of a recruiter, it is read on each request
+// so that an approval applies without a new token
+func (r *recruiterRepository) GetState(id uint) (utils.RecruiterState, error) {
+	var states []utils.RecruiterState
+	if err := r.db.Model(&models.ProfileRecruiter{}).Where("id = ?", id).Pluck("state", &states).Error; err != nil {
+		return "", err
+	}
+	if len(states) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return states[0], nil
+}
+
+// GetCompanyRecruiters lists the recruiters of a company in a state, all of them when the state is empty
+func (r *recruiterRepository) GetCompanyRecruiters(companyID uint, state utils.RecruiterState) ([]models.ProfileRecruiter, error) {
+	query := r.db.Preload("User").Where("company_id = ?", companyID)
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	var recruiters []models.ProfileRecruiter
+	err := query.Order("id").Find(&recruiters).Error
+	return recruiters, err
+}
+
+// AnswerJoinRequest approves (active) or rejects a recruiter who asked to join the company,
+// a pending request can be answered and a rejected one can still be approved
+func (r *recruiterRepository) AnswerJoinRequest(id uint, companyID uint, state utils.RecruiterState, tx *gorm.DB) (models.ProfileRecruiter, error) {
+	var recruiter models.ProfileRecruiter
+	if err := tx.Preload("User").Preload("Company").Where("id = ? AND company_id = ?", id, companyID).First(&recruiter).Error; err != nil {
+		return models.ProfileRecruiter{}, err
+	}
+
+	if recruiter.State == state || (recruiter.State != models.PendingState && recruiter.State != models.RejectedState) {
+		return models.ProfileRecruiter{}, ErrRequestAnswered
+	}
+
+	if err := tx.Model(&recruiter).Update("state", state).Error; err != nil {
+		return models.ProfileRecruiter{}, err
+	}
+	recruiter.State = state
+	return recruiter, nil
+}
+
+// activeMember locks the active admins of the company and returns its active recruiter,
+// the lock keeps two admins from demoting or removing each other at the same time
+func activeMember(id uint, companyID uint, tx *gorm.DB) (models.ProfileRecruiter, int, error) {
+	var admins []models.ProfileRecruiter
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("company_id = ? AND role = ? AND state = ?", companyID, models.AdminRole, models.ActiveState).
+		Find(&admins).Error
+	if err != nil {
+		return models.ProfileRecruiter{}, 0, err
+	}
+
+	var recruiter models.ProfileRecruiter
+	err = tx.Preload("User").Preload("Company").
+		Where("id = ? AND company_id = ? AND state = ?", id, companyID, models.ActiveState).First(&recruiter).Error
+	if err != nil {
+		return models.ProfileRecruiter{}, 0, err
+	}
+	return recruiter, len(admins), nil
+}
+
+// ChangeRole changes the role of an active recruiter in the company, the last admin can't be demoted
+func (r *recruiterRepository) ChangeRole(id uint, companyID uint, role utils.CompanyRole, tx *gorm.DB) (models.ProfileRecruiter, error) {
+	recruiter, admins, err := activeMember(id, companyID, tx)
+	if err != nil {
+		return models.ProfileRecruiter{}, err
+	}
+	if recruiter.Role == role {
+		return recruiter, nil
+	}
+	if recruiter.Role == models.AdminRole && admins <= 1 {
+		return models.ProfileRecruiter{}, ErrLastAdmin
+	}
+
+	if err := tx.Model(&recruiter).Update("role", role).Error; err != nil {
+		return models.ProfileRecruiter{}, err
+	}
+	recruiter.Role = role
+	return recruiter, nil
+}
+
+// RemoveRecruiter removes an active recruiter from the company and reassigns the job posts
+// to another active recruiter of the company, the last admin can't be removed
+func (r *recruiterRepository) RemoveRecruiter(id uint, companyID uint, reassignTo uint, tx *gorm.DB) (models.ProfileRecruiter, int64, error) {
+	recruiter, admins, err := activeMember(id, companyID, tx)
+	if err != nil {
+		return models.ProfileRecruiter{}, 0, err
+	}
+	if recruiter.Role == models.AdminRole && admins <= 1 {
+		return models.ProfileRecruiter{}, 0, ErrLastAdmin
+	}
+
+	if reassignTo == id {
+		return models.ProfileRecruiter{}, 0, ErrInvalidReassignment
+	}
+	var successors int64
+	err = tx.Model(&models.ProfileRecruiter{}).
+		Where("id = ? AND company_id = ? AND state = ?", reassignTo, companyID, models.ActiveState).Count(&successors).Error
+	if err != nil {
+		return models.ProfileRecruiter{}, 0, err
+	}
+	if successors == 0 {
+		return models.ProfileRecruiter{}, 0, ErrInvalidReassignment
+	}
+
+	result := tx.Model(&models.JobPost{}).Where("recruiter_id = ?", id).Update("recruiter_id", reassignTo)
+	if result.Error != nil {
+		return models.ProfileRecruiter{}, 0, result.Error
+	}
+
+	// A removed admin loses the role so that nothing can give back the admin rights
+	err = tx.Model(&recruiter).Updates(map[string]interface{}{"state": models.RemovedState, "role": models.RecruiterRole}).Error
+	if err != nil {
+		return models.ProfileRecruiter{}, 0, err
+	}
+	recruiter.State = models.RemovedState
+	recruiter.Role = models.RecruiterRole
+	return recruiter, result.RowsAffected, nil
+}
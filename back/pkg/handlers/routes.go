@@ -5,8 +5,10 @@ import (
 
 	"skillly/pkg/handlers/application"
 	"skillly/pkg/handlers/auth"
+	candidate "skillly/pkg/handlers/candidateProfile"
 	"skillly/pkg/handlers/certification"
 	"skillly/pkg/handlers/company"
+	"skillly/pkg/handlers/interview"
 	"skillly/pkg/handlers/jobPost"
 	"skillly/pkg/handlers/match"
 	"skillly/pkg/handlers/skill"
@@ -22,4 +24,6 @@ func AddRoutes(r *gin.Engine) {
 	application.AddRoutes(r)
 	user.AddRoutes(r)
 	match.AddRoutes(r)
+	candidate.AddRoutes(r)
+	interview.AddRoutes(r)
 }
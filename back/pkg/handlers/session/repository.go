@@ -0,0 +1,150 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+)
+
+// Lifetime of a refresh session, each refresh extends it
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var ErrRefreshTokenReused = errors.New("Refresh token already used")
+
+type SessionRepository interface {
+	models.Repository[models.Session]
+	CreateSession(userID uint, userAgent string, tx *gorm.DB) (models.Session, string, error)
+	GetByRefreshToken(refreshToken string) (models.Session, error)
+	RotateRefreshToken(session *models.Session) (string, error)
+	RevokeSession(sessionID uint) error
+	RevokeUserSessions(userID uint, tx *gorm.DB) error
+	IsSessionActive(sessionID uint, userID uint) (bool, error)
+}
+
+type sessionRepository struct {
+	models.Repository[models.Session]
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		Repository: models.NewRepository[models.Session](db),
+		db:         db,
+	}
+}
+
+// CreateSession stores a new session and returns it with its clear refresh token
+func (r *sessionRepository) CreateSession(userID uint, userAgent string, tx *gorm.DB) (models.Session, string, error) {
+	refreshToken, err := utils.GenerateToken()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+
+	session := models.Session{
+		UserID:     userID,
+		TokenHash:  utils.HashToken(refreshToken),
+		UserAgent:  userAgent,
+		ExpiresAt:  time.Now().Add(RefreshTokenTTL),
+		LastUsedAt: time.Now(),
+	}
+
+	createdSession := tx.Create(&session)
+	if createdSession.Error != nil {
+		return models.Session{}, "", createdSession.Error
+	}
+
+	return session, refreshToken, nil
+}
+
+// GetByRefreshToken finds the session of a refresh token. A token retired by a rotation returns
+// its session with ErrRefreshTokenReused, it was replayed after the session moved on to a new token
+func (r *sessionRepository) GetByRefreshToken(refreshToken string) (models.Session, error) {
+	tokenHash := utils.HashToken(refreshToken)
+
+	var session models.Session
+	result := r.db.Where("token_hash = ?", tokenHash).First(&session)
+	if result.Error == nil {
+		return session, nil
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.Session{}, result.Error
+	}
+
+	retired := r.db.Model(&models.SessionRefreshToken{}).Select("session_id").Where("token_hash = ?", tokenHash)
+	if err := r.db.Where("id IN (?)", retired).First(&session).Error; err != nil {
+		return models.Session{}, err
+	}
+	return session, ErrRefreshTokenReused
+}
+
+// RotateRefreshToken replaces the refresh token of a session, the previous one is retired and can't be used anymore.
+// The token is only replaced if it is still the one read with the session, otherwise it was
+// already rotated by another request and ErrRefreshTokenReused is returned
+func (r *sessionRepository) RotateRefreshToken(session *models.Session) (string, error) {
+	refreshToken, err := utils.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Session{}).
+			Where("id = ? AND token_hash = ? AND revoked_at IS NULL", session.ID, session.TokenHash).
+			Updates(map[string]interface{}{
+				"token_hash":   utils.HashToken(refreshToken),
+				"expires_at":   now.Add(RefreshTokenTTL),
+				"last_used_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		return tx.Create(&models.SessionRefreshToken{SessionID: session.ID, TokenHash: session.TokenHash}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	session.TokenHash = utils.HashToken(refreshToken)
+	session.ExpiresAt = now.Add(RefreshTokenTTL)
+	session.LastUsedAt = now
+
+	return refreshToken, nil
+}
+
+func (r *sessionRepository) RevokeSession(sessionID uint) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user (logout from all devices)
+func (r *sessionRepository) RevokeUserSessions(userID uint, tx *gorm.DB) error {
+	return tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) IsSessionActive(sessionID uint, userID uint) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
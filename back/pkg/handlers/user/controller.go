@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"skillly/pkg/middleware"
+	"skillly/pkg/models"
 )
 
 // @Summary Créer un utilisateur
@@ -53,7 +54,7 @@ func GetUserByIdHandler(c *gin.Context) {
 }
 
 // @Summary Mettre à jour un utilisateur
-// @Description Met à jour les informations d'un utilisateur
+// @Description Met à jour les informations de l'utilisateur connecté, seul son propre compte peut être modifié. Le mot de passe actuel (currentPassword) est demandé pour changer l'email ou le mot de passe, un nouvel email doit être vérifié à nouveau et un nouveau mot de passe déconnecte toutes les sessions
 // @Tags users
 // @Accept json
 // @Produce json
@@ -63,6 +64,7 @@ func GetUserByIdHandler(c *gin.Context) {
 // @Success 200 {object} map[string]interface{} "Utilisateur mis à jour"
 // @Failure 400 {object} map[string]string "Erreur de validation"
 // @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Compte d'un autre utilisateur ou mot de passe actuel invalide"
 // @Failure 404 {object} map[string]string "Utilisateur non trouvé"
 // @Router /user/{id} [put]
 func UpdateUserHandler(c *gin.Context) {
@@ -118,6 +120,40 @@ func DeleteUserSkillsHandler(c *gin.Context) {
 	userService.DeleteUserSkill(c)
 }
 
+// @Summary Modifier la visibilité du candidat
+// @Description Permet au candidat connecté d'apparaître ou non dans la recherche de candidats des recruteurs, les candidats sont masqués par défaut
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param discoverableData body candidateDto.UpdateDiscoverableDTO true "Visibilité du profil"
+// @Success 200 {object} map[string]bool "Visibilité mise à jour"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - candidats uniquement"
+// @Router /user/me/discoverable [put]
+func UpdateDiscoverableHandler(c *gin.Context) {
+	userService := NewUserService()
+	userService.UpdateDiscoverable(c)
+}
+
+// @Summary Modifier le salaire attendu
+// @Description Définit le salaire attendu par le candidat connecté (montants, devise, période et brut ou net), un salaire sans montant le supprime. Les scores de ses candidatures sont recalculés
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param salaryData body models.Salary true "Salaire attendu"
+// @Success 200 {object} map[string]models.Salary "Salaire attendu mis à jour"
+// @Failure 400 {object} map[string]string "Erreur de validation"
+// @Failure 401 {object} map[string]string "Non autorisé"
+// @Failure 403 {object} map[string]string "Accès refusé - candidats uniquement"
+// @Router /user/me/salary [put]
+func UpdateExpectedSalaryHandler(c *gin.Context) {
+	userService := NewUserService()
+	userService.UpdateExpectedSalary(c)
+}
+
 func AddRoutes(r *gin.Engine) {
 	us := r.Group("/user")
 
@@ -128,4 +164,6 @@ func AddRoutes(r *gin.Engine) {
 	us.DELETE("/:id", middleware.AuthMiddleware(), DeleteUserHandler)
 	us.PATCH("/me/skills", middleware.AuthMiddleware(), AddUserSkillsHandler)
 	us.DELETE("/me/skills", middleware.AuthMiddleware(), DeleteUserSkillsHandler)
+	us.PUT("/me/discoverable", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), UpdateDiscoverableHandler)
+	us.PUT("/me/salary", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleCandidate), UpdateExpectedSalaryHandler)
 }
@@ -1,8 +1,9 @@
 package userDto
 
 type UpdateUserDTO struct {
-	FirstName string `json:"firstName""`
-	LastName  string `json:"lastName""`
-	Email     string `json:"email"`
-	Password  string `json:"password"`
+	FirstName       string `json:"firstName"`
+	LastName        string `json:"lastName"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	CurrentPassword string `json:"currentPassword" binding:"required_with=Email Password"` // Required to change the email or the password
 }
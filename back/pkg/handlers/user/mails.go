@@ -0,0 +1,29 @@
+package user
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"skillly/pkg/mailer"
+	"skillly/pkg/models"
+)
+
+const EmailVerificationTTL = 48 * time.Hour
+
+// SendVerificationEmail sends the email verification token to the user, the error is logged
+// instead of failing the request since the user can ask for a new mail
+func SendVerificationEmail(user models.User, token string) {
+	mail := mailer.Mail{
+		To:      user.Email,
+		Subject: "Skillly - Confirmez votre adresse email",
+		Body: fmt.Sprintf(
+			"Bonjour %s,\n\nConfirmez votre adresse email en ouvrant ce lien :\n%s/verify-email?token=%s\n\nCe lien expire dans 48 heures.",
+			user.FirstName, os.Getenv("APP_URL"), token,
+		),
+	}
+	if err := mailer.Default.Send(mail); err != nil {
+		log.Printf("error sending mail to %s: %v", mail.To, err)
+	}
+}
@@ -9,9 +9,12 @@ import (
 	"gorm.io/gorm"
 
 	"skillly/pkg/config"
+	"skillly/pkg/handlers/application"
 	candidate "skillly/pkg/handlers/candidateProfile"
 	candidateDto "skillly/pkg/handlers/candidateProfile/dto"
+	"skillly/pkg/handlers/session"
 	userDto "skillly/pkg/handlers/user/dto"
+	"skillly/pkg/handlers/userToken"
 	"skillly/pkg/models"
 	"skillly/pkg/utils"
 )
@@ -24,17 +27,26 @@ type UserService interface {
 	DeleteUser(c *gin.Context)
 	AddUserSkills(c *gin.Context)
 	DeleteUserSkill(c *gin.Context)
+	UpdateDiscoverable(c *gin.Context)
+	UpdateExpectedSalary(c *gin.Context)
 }
 
 type userService struct {
 	userRepository      UserRepository
 	candidateRepository candidate.CandidateRepository
+	sessionRepository   session.SessionRepository
+	userTokenRepository userToken.UserTokenRepository
+	// To rescore the applications when the skills change
+	applicationRepository application.ApplicationRepository
 }
 
 func NewUserService() UserService {
 	return &userService{
-		userRepository:      NewUserRepository(config.DB),
-		candidateRepository: candidate.NewCandidateRepository(config.DB),
+		userRepository:        NewUserRepository(config.DB),
+		candidateRepository:   candidate.NewCandidateRepository(config.DB),
+		sessionRepository:     session.NewSessionRepository(config.DB),
+		userTokenRepository:   userToken.NewUserTokenRepository(config.DB),
+		applicationRepository: application.NewApplicationRepository(config.DB),
 	}
 }
 
@@ -87,16 +99,36 @@ func (s *userService) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	id, _ := utils.GetId(c)
+	id, err := utils.GetId(c)
+	if err != nil {
+		return
+	}
+	// A user can only update their own account
+	if c.Keys["user_id"] != id {
+		c.JSON(403, gin.H{"error": "You can only update your own account"})
+		return
+	}
+
 	user := models.User{}
 	if err := config.DB.First(&user, id).Error; err != nil {
 		c.JSON(404, gin.H{"error": err.Error()})
 		return
 	}
 
+	// The email and the password are the credentials of the account, the current password is asked to change them
+	if dto.Email != "" || dto.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(dto.CurrentPassword)); err != nil {
+			c.JSON(403, gin.H{"error": "Invalid current password"})
+			return
+		}
+	}
+
 	// Only update fields that are provided in the DTO
-	if dto.Email != "" {
+	emailChanged := dto.Email != "" && dto.Email != user.Email
+	if emailChanged {
+		// The new email must be verified again
 		user.Email = dto.Email
+		user.EmailVerifiedAt = nil
 	}
 	if dto.FirstName != "" {
 		user.FirstName = dto.FirstName
@@ -105,6 +137,11 @@ func (s *userService) UpdateUser(c *gin.Context) {
 		user.LastName = dto.LastName
 	}
 	if dto.Password != "" {
+		if err := utils.ValidatePassword(dto.Password); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(dto.Password), bcrypt.DefaultCost)
 		if err != nil {
 			c.JSON(400, gin.H{"error": err.Error()})
@@ -113,16 +150,45 @@ func (s *userService) UpdateUser(c *gin.Context) {
 		user.Password = string(hashedPassword)
 	}
 
-	if err := s.userRepository.Update(&user); err != nil {
+	var verificationToken string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+
+		// A new password logs the user out from every device
+		if dto.Password != "" {
+			if err := s.sessionRepository.RevokeUserSessions(user.ID, tx); err != nil {
+				return err
+			}
+		}
+
+		if emailChanged {
+			verificationToken, err = s.userTokenRepository.CreateToken(user.ID, models.EmailVerificationPurpose, EmailVerificationTTL, tx)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
+	if emailChanged {
+		SendVerificationEmail(user, verificationToken)
+	}
+
 	c.JSON(200, user)
 }
 
 func (s *userService) DeleteUser(c *gin.Context) {
 	id, _ := utils.GetId(c)
+
+	if err := s.sessionRepository.RevokeUserSessions(uint(id), config.DB); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
 	err := s.userRepository.Delete(uint(id))
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
@@ -152,6 +218,11 @@ func (s *userService) AddUserSkills(c *gin.Context) {
 		return
 	}
 
+	if err := s.applicationRepository.RescoreCandidateApplications(candidateID.(uint), config.DB); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to update application scores: " + err.Error()})
+		return
+	}
+
 	c.JSON(200, gin.H{"message": "Skills and/or certifications added successfully"})
 }
 
@@ -178,5 +249,77 @@ func (s *userService) DeleteUserSkill(c *gin.Context) {
 		return
 	}
 
+	if err := s.applicationRepository.RescoreCandidateApplications(userID, config.DB); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to update application scores: " + err.Error()})
+		return
+	}
+
 	c.JSON(200, gin.H{"message": "Skills and/or certifications association deleted successfully"})
 }
+
+// UpdateDiscoverable lets a candidate opt in or out of the candidate search of the recruiters, candidates are hidden by default
+func (s *userService) UpdateDiscoverable(c *gin.Context) {
+	candidateID := c.Keys["candidate_id"]
+
+	var dto candidateDto.UpdateDiscoverableDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := s.candidateRepository.SetDiscoverable(candidateID.(uint), *dto.Discoverable, config.DB); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "Candidate profile not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"discoverable": *dto.Discoverable})
+}
+
+// UpdateExpectedSalary sets the salary expected by a candidate, the scores of their applications are recomputed
+func (s *userService) UpdateExpectedSalary(c *gin.Context) {
+	candidateID := c.Keys["candidate_id"]
+
+	var dto models.Salary
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+	if tx.Error != nil {
+		c.JSON(500, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+
+	salary, err := s.candidateRepository.SetExpectedSalary(candidateID.(uint), dto, tx)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, models.ErrInvalidSalary):
+			c.JSON(400, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(404, gin.H{"error": "Candidate profile not found"})
+		default:
+			c.JSON(500, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if err := s.applicationRepository.RescoreCandidateApplications(candidateID.(uint), tx); err != nil {
+		tx.Rollback()
+		c.JSON(500, gin.H{"error": "Failed to update application scores: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback() // Ensure rollback on commit error
+		c.JSON(500, gin.H{"error": "Failed to commit transaction: " + err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"expected_salary": salary})
+}
@@ -0,0 +1,93 @@
+package userToken
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+)
+
+type UserTokenRepository interface {
+	models.Repository[models.UserToken]
+	CreateToken(userID uint, purpose utils.TokenPurpose, ttl time.Duration, tx *gorm.DB) (string, error)
+	ConsumeToken(token string, purpose utils.TokenPurpose, tx *gorm.DB) (models.UserToken, error)
+	GetToken(token string, purpose utils.TokenPurpose, tx *gorm.DB) (models.UserToken, error)
+}
+
+type userTokenRepository struct {
+	models.Repository[models.UserToken]
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{
+		Repository: models.NewRepository[models.UserToken](db),
+		db:         db,
+	}
+}
+
+// CreateToken invalidates the previous tokens of the user for the same purpose
+// and returns a new clear token, only its hash is stored
+func (r *userTokenRepository) CreateToken(userID uint, purpose utils.TokenPurpose, ttl time.Duration, tx *gorm.DB) (string, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return "", err
+	}
+
+	userToken := models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := tx.Create(&userToken).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeToken marks a valid token as used and returns it
+// gorm.ErrRecordNotFound is returned for unknown, expired or already used tokens
+func (r *userTokenRepository) ConsumeToken(token string, purpose utils.TokenPurpose, tx *gorm.DB) (models.UserToken, error) {
+	var userToken models.UserToken
+	result := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+		utils.HashToken(token), purpose, time.Now()).First(&userToken)
+	if result.Error != nil {
+		return models.UserToken{}, result.Error
+	}
+
+	now := time.Now()
+	// The condition on used_at prevents two concurrent requests from using the same token
+	result = tx.Model(&userToken).Where("used_at IS NULL").Update("used_at", now)
+	if result.Error != nil {
+		return models.UserToken{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.UserToken{}, gorm.ErrRecordNotFound
+	}
+
+	return userToken, nil
+}
+
+// GetToken returns a valid token without using it, for the tokens used several times
+func (r *userTokenRepository) GetToken(token string, purpose utils.TokenPurpose, tx *gorm.DB) (models.UserToken, error) {
+	var userToken models.UserToken
+	result := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+		utils.HashToken(token), purpose, time.Now()).First(&userToken)
+	if result.Error != nil {
+		return models.UserToken{}, result.Error
+	}
+
+	return userToken, nil
+}
@@ -0,0 +1,103 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineLength is the maximum length of a line in octets, longer lines are folded
+const maxLineLength = 75
+
+const dateTimeLayout = "20060102T150405Z"
+
+// ContentType is the media type of the calendars
+const ContentType = "text/calendar; charset=utf-8"
+
+// Event is an event of a calendar, its times are written in UTC
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// Sequence is incremented each time the event is rescheduled
+	Sequence int
+	Stamp    time.Time
+}
+
+// Calendar encodes the events in the iCalendar format (RFC 5545)
+func Calendar(name string, events []Event) []byte {
+	var buffer bytes.Buffer
+	writeLine(&buffer, "BEGIN:VCALENDAR")
+	writeLine(&buffer, "VERSION:2.0")
+	writeLine(&buffer, "PRODID:-//Skillly//Interviews//FR")
+	writeLine(&buffer, "CALSCALE:GREGORIAN")
+	writeLine(&buffer, "METHOD:PUBLISH")
+	writeLine(&buffer, "X-WR-CALNAME:"+escape(name))
+
+	for _, event := range events {
+		writeLine(&buffer, "BEGIN:VEVENT")
+		writeLine(&buffer, "UID:"+escape(event.UID))
+		writeLine(&buffer, "DTSTAMP:"+formatTime(event.Stamp))
+		writeLine(&buffer, "DTSTART:"+formatTime(event.Start))
+		writeLine(&buffer, "DTEND:"+formatTime(event.End))
+		writeLine(&buffer, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeLine(&buffer, "SUMMARY:"+escape(event.Summary))
+		if event.Description != "" {
+			writeLine(&buffer, "DESCRIPTION:"+escape(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&buffer, "LOCATION:"+escape(event.Location))
+		}
+		if event.URL != "" {
+			writeLine(&buffer, "URL:"+event.URL)
+		}
+		writeLine(&buffer, "END:VEVENT")
+	}
+
+	writeLine(&buffer, "END:VCALENDAR")
+	return buffer.Bytes()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format(dateTimeLayout)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape escapes a text value
+func escape(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// writeLine writes a content line ended by CRLF, folded without splitting a character
+func writeLine(buffer *bytes.Buffer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buffer.WriteString(line[:cut])
+		buffer.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of the continuation lines counts in their length
+		limit = maxLineLength - 1
+	}
+	buffer.WriteString(line)
+	buffer.WriteString("\r\n")
+}
@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes each mail in a .eml file instead of sending it
+type fileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) Mailer {
+	return &fileMailer{dir: dir}
+}
+
+func (m *fileMailer) Send(mail Mail) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), mail.To)
+	return os.WriteFile(filepath.Join(m.dir, name), formatMessage("no-reply@skillly", mail), 0o644)
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"log"
+	"os"
+)
+
+// Mail is a plain text email
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails, the implementation is chosen at startup
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+// Default is the mailer used by the services
+var Default Mailer = NewMemoryMailer()
+
+// SetupMailer uses SMTP when SMTP_HOST is set
+// otherwise the mails are written in MAIL_DIR (development)
+func SetupMailer() {
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		Default = NewSMTPMailer(
+			host,
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USER"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+		log.Printf("Mailer: SMTP %s", host)
+		return
+	}
+
+	dir := os.Getenv("MAIL_DIR")
+	if dir == "" {
+		dir = "tmp/mails"
+	}
+	Default = NewFileMailer(dir)
+	log.Printf("Mailer: writing mails to %s", dir)
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"sync"
+)
+
+// MemoryMailer keeps the sent mails in memory, used by the tests
+type MemoryMailer struct {
+	mutex sync.RWMutex
+	mails []Mail
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(mail Mail) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+// Sent returns every mail sent so far
+func (m *MemoryMailer) Sent() []Mail {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]Mail{}, m.mails...)
+}
+
+// LastTo returns the last mail sent to an address
+func (m *MemoryMailer) LastTo(to string) (Mail, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for i := len(m.mails) - 1; i >= 0; i-- {
+		if m.mails[i].To == to {
+			return m.mails[i], true
+		}
+	}
+	return Mail{}, false
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -8,10 +9,48 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
+	"skillly/pkg/config"
+	recruiter "skillly/pkg/handlers/recruiterProfile"
+	"skillly/pkg/handlers/session"
 	"skillly/pkg/models"
 	"skillly/pkg/utils"
 )
 
+var ErrSessionRevoked = errors.New("Session revoked or expired")
+
+// ParseToken checks the signature of an access token
+// and that the session it was issued for is still active
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	user := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, &user, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	// Check the session has not been revoked (logout, password change, deleted account)
+	sessionID, _ := user["sid"].(float64)
+	userID, _ := user["id"].(float64)
+	active, err := session.NewSessionRepository(config.DB).IsSessionActive(uint(sessionID), uint(userID))
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrSessionRevoked
+	}
+
+	return user, nil
+}
+
 // AuthMiddleware is a middleware that checks if the user is authenticated
 // and if the user has the correct role to access the route
 
@@ -28,66 +67,91 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Remove the Bearer prefix
 		authHeader = strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Check if the token is valid
-		user := &jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(authHeader, user, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		authenticate(c, authHeader)
+	}
+}
 
-		// Check if there is an error
-		if err != nil {
-			fmt.Println("err", err)
-			c.JSON(401, gin.H{"error": "Unauthorized"})
-			c.Abort()
-			return
+// WsAuthMiddleware authenticates a WebSocket upgrade request
+// clients can't always set headers on a WebSocket so the token can also be sent in the "token" query param
+func WsAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.Query("token")
+		if tokenString == "" {
+			tokenString = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
 		}
 
-		// Check if the token is valid
-		if !token.Valid {
-			fmt.Println("token.Valid", token.Valid)
+		if tokenString == "" {
 			c.JSON(401, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
-		// Check if the user has the correct role
-		userRole, ok := (*user)["role"].(string)
-		if !ok {
-			fmt.Println("userRole", userRole)
-			c.JSON(403, gin.H{"error": "Forbidden"})
-			c.Abort()
-			return
-		}
+		authenticate(c, tokenString)
+	}
+}
+
+// authenticate validates the token and sets the user in the context
+func authenticate(c *gin.Context, tokenString string) {
+	// Check if the token is valid
+	user, err := ParseToken(tokenString)
 
-		// Set the user in the context
-		userID, _ := (*user)["id"].(float64)
+	// Check if there is an error
+	if err != nil {
+		fmt.Println("err", err)
+		c.JSON(401, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+
+	// Check if the user has the correct role
+	userRole, ok := user["role"].(string)
+	if !ok {
+		fmt.Println("userRole", userRole)
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		c.Abort()
+		return
+	}
 
-		c.Set("user_id", uint(userID))
-		c.Set("user_role", (*user)["role"])
-		c.Set("user_first_name", (*user)["firstName"])
-		c.Set("user_last_name", (*user)["lastName"])
+	// Set the user in the context
+	userID, _ := user["id"].(float64)
+	sessionID, _ := user["sid"].(float64)
 
-		if utils.RoleType(userRole) == models.RoleRecruiter {
-			companyID, _ := (*user)["companyID"].(float64)
-			recruiterID, _ := (*user)["recruiterID"].(float64)
+	c.Set("user_id", uint(userID))
+	c.Set("session_id", uint(sessionID))
+	c.Set("user_role", user["role"])
+	c.Set("user_first_name", user["firstName"])
+	c.Set("user_last_name", user["lastName"])
 
-			c.Set("company_id", uint(companyID))
-			c.Set("recruiter_id", uint(recruiterID))
-			c.Set("company_role", (*user)["companyRole"])
-		} else if utils.RoleType(userRole) == models.RoleCandidate {
-			candidateID, _ := (*user)["candidateID"].(float64)
+	if utils.RoleType(userRole) == models.RoleRecruiter {
+		companyID, _ := user["companyID"].(float64)
+		recruiterID, _ := user["recruiterID"].(float64)
 
-			c.Set("candidate_id", uint(candidateID))
-		} else {
-			c.JSON(403, gin.H{"error": "Invalid Role"})
+		// The state is not in the token so that an approval applies right away
+		state, err := recruiter.NewRecruiterRepository(config.DB).GetState(uint(recruiterID))
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
+		// A recruiter who is not approved has no access to the data of the company
+		if state != models.ActiveState {
+			companyID = 0
+		}
 
-		// Continue to the next middleware or handler
-		c.Next()
+		c.Set("company_id", uint(companyID))
+		c.Set("recruiter_id", uint(recruiterID))
+		c.Set("company_role", user["companyRole"])
+		c.Set("recruiter_state", state)
+	} else if utils.RoleType(userRole) == models.RoleCandidate {
+		candidateID, _ := user["candidateID"].(float64)
+
+		c.Set("candidate_id", uint(candidateID))
+	} else {
+		c.JSON(403, gin.H{"error": "Invalid Role"})
+		c.Abort()
+		return
 	}
+
+	// Continue to the next middleware or handler
+	c.Next()
 }
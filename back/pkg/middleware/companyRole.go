@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"skillly/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompanyRoleMiddleware checks the role of the recruiter in the company from the token,
+// it follows RoleMiddleware(models.RoleRecruiter) which checks the recruiter is active.
+// The sessions of a recruiter are revoked when the role changes so that the next token has the new one
+func CompanyRoleMiddleware(role utils.CompanyRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// Check if the recruiter has the correct role in the company
+		if c.Keys["company_role"] != string(role) {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"skillly/pkg/models"
 	"skillly/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +17,17 @@ func RoleMiddleware(role utils.RoleType) gin.HandlerFunc {
 			return
 		}
 
+		// A recruiter acts for the company once an admin approved the join request
+		if role == models.RoleRecruiter && c.Keys["recruiter_state"] != models.ActiveState {
+			message := "Recruiter account pending approval"
+			if c.Keys["recruiter_state"] == models.RejectedState {
+				message = "Recruiter join request rejected"
+			}
+			c.JSON(403, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -19,12 +19,18 @@ type Application struct {
 	Score     int                    `json:"score"`
 	CreatedAt time.Time              `json:"created_at"`
 
-	JobPostID uint    `json:"job_post_id"`
+	// Computed by the scoring engine, the factors explain the score to the recruiters
+	ScoreFactors []ScoreFactor `json:"score_factors" gorm:"type:jsonb;serializer:json"`
+
+	// A candidate applies once to a job post
+	JobPostID uint    `json:"job_post_id" gorm:"uniqueIndex:idx_application_candidate_job_post"`
 	JobPost   JobPost `json:"job_post" gorm:"foreignKey:JobPostID;references:ID"`
 
-	CandidateID uint             `json:"candidate_id"`
+	CandidateID uint             `json:"candidate_id" gorm:"uniqueIndex:idx_application_candidate_job_post"`
 	Candidate   ProfileCandidate `json:"candidate" gorm:"foreignKey:CandidateID;references:ID"`
 
 	CoverLetterID *uint `json:"cover_id"  gorm:"default:null"`
 	CoverLetter   File  `json:"cover" gorm:"foreignKey:CoverLetterID;references:ID"`
+
+	History []ApplicationStateHistory `json:"history,omitempty" gorm:"foreignKey:ApplicationID;references:ID;constraint:OnDelete:CASCADE;"`
 }
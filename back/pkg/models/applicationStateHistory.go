@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"skillly/pkg/utils"
+)
+
+// applicationTransitions lists the states an application can move to from each state,
+// the candidate closes a pending or matched application by withdrawing it
+var applicationTransitions = map[utils.ApplicationState][]utils.ApplicationState{
+	PendingApplication: {MatchedApplication, RejectedApplication, ClosedApplication},
+	MatchedApplication: {ClosedApplication},
+}
+
+// CanTransitionApplication tells whether an application can move from a state to another
+func CanTransitionApplication(from utils.ApplicationState, to utils.ApplicationState) bool {
+	for _, state := range applicationTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplicationStateHistory records a transition of an application, the actor is empty
+// when the transition was made by the server itself
+type ApplicationStateHistory struct {
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	ApplicationID uint                   `json:"application_id" gorm:"index"`
+	FromState     utils.ApplicationState `json:"from_state"`
+	ToState       utils.ApplicationState `json:"to_state"`
+	ActorID       *uint                  `json:"actor_id" gorm:"default:null"`
+	Actor         *User                  `json:"actor,omitempty" gorm:"foreignKey:ActorID;references:ID"`
+	Reason        string                 `json:"reason"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
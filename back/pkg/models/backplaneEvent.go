@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// BackplaneEvent is a chat event too large for a Postgres NOTIFY, it is stored and only its ID is notified.
+// The instances read it as soon as they are notified, it is deleted after a while
+type BackplaneEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Payload   string    `json:"payload" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
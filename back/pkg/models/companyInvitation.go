@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"skillly/pkg/utils"
+)
+
+// CompanyInvitation is sent by email by an admin to a colleague, who registers as an active recruiter
+// of the company with the role of the invitation. Only the hash of the token is stored
+type CompanyInvitation struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	CompanyID   uint              `json:"company_id" gorm:"index"`
+	Company     Company           `json:"-" gorm:"foreignKey:CompanyID;references:ID;constraint:OnDelete:CASCADE;"`
+	Email       string            `json:"email"`
+	Role        utils.CompanyRole `json:"role"`
+	TokenHash   string            `json:"-" gorm:"uniqueIndex"`
+	InvitedByID uint              `json:"invited_by_id"` // Recruiter who sent the invitation
+	ExpiresAt   time.Time         `json:"expires_at"`
+	AcceptedAt  *time.Time        `json:"accepted_at" gorm:"default:null"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
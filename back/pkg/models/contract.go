@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"skillly/pkg/utils"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	CDIContract        utils.ContractType = "CDI"
+	CDDContract        utils.ContractType = "CDD"
+	InternshipContract utils.ContractType = "STAGE"
+	// Apprenticeship and professionalisation contracts, the time is shared between the company and a school
+	WorkStudyContract utils.ContractType = "ALTERNANCE"
+	FreelanceContract utils.ContractType = "FREELANCE"
+	InterimContract   utils.ContractType = "INTERIM"
+)
+
+// ContractTypes are the valid contract types, the DTOs validate them with the contract_type tag
+var ContractTypes = []utils.ContractType{
+	CDIContract, CDDContract, InternshipContract, WorkStudyContract, FreelanceContract, InterimContract,
+}
+
+// IsContractType tells whether a value is one of the valid contract types
+func IsContractType(value utils.ContractType) bool {
+	for _, contract := range ContractTypes {
+		if contract == value {
+			return true
+		}
+	}
+	return false
+}
+
+// The contract_type tag of the DTOs is built from ContractTypes so that a new contract type is accepted everywhere
+func init() {
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterValidation("contract_type", func(fl validator.FieldLevel) bool {
+			return IsContractType(utils.ContractType(fl.Field().String()))
+		})
+	}
+}
+
+// Legal maximum durations in France, in months
+const (
+	MaxCDDDuration        = 36
+	MaxInternshipDuration = 6
+	MaxWorkStudyDuration  = 36
+)
+
+var (
+	ErrDurationRequired  = errors.New("The duration is required for this contract type")
+	ErrDurationForbidden = errors.New("A CDI has no duration")
+	ErrDurationTooLong   = errors.New("The duration is longer than allowed for this contract type")
+	ErrRhythmRequired    = errors.New("The rhythm is required for an ALTERNANCE contract")
+	ErrRhythmForbidden   = errors.New("Only an ALTERNANCE contract has a rhythm")
+)
+
+// ContractTerms are the terms depending on the contract type of a job post
+type ContractTerms struct {
+	DurationMonths *int       `json:"duration_months" gorm:"default:null" binding:"omitempty,min=1"` // Length of the fixed-term contracts and missions
+	StartDate      *time.Time `json:"start_date" gorm:"default:null"`
+	Rhythm         string     `json:"rhythm"` // Time shared between the company and the school, such as "3 semaines / 1 semaine"
+}
+
+// Validate checks that the terms fit the contract type, a freelance mission may have a duration
+func (t ContractTerms) Validate(contract utils.ContractType) error {
+	maxDuration := map[utils.ContractType]int{
+		CDDContract:        MaxCDDDuration,
+		InternshipContract: MaxInternshipDuration,
+		WorkStudyContract:  MaxWorkStudyDuration,
+	}
+
+	switch contract {
+	case CDIContract:
+		if t.DurationMonths != nil {
+			return ErrDurationForbidden
+		}
+	case CDDContract, InternshipContract, WorkStudyContract, InterimContract:
+		if t.DurationMonths == nil {
+			return ErrDurationRequired
+		}
+	}
+	if max, ok := maxDuration[contract]; ok && t.DurationMonths != nil && *t.DurationMonths > max {
+		return ErrDurationTooLong
+	}
+
+	if contract == WorkStudyContract && strings.TrimSpace(t.Rhythm) == "" {
+		return ErrRhythmRequired
+	}
+	if contract != WorkStudyContract && t.Rhythm != "" {
+		return ErrRhythmForbidden
+	}
+	return nil
+}
+
+// IsContractError tells whether an error comes from the validation of contract terms
+func IsContractError(err error) bool {
+	for _, contractErr := range []error{ErrDurationRequired, ErrDurationForbidden, ErrDurationTooLong, ErrRhythmRequired, ErrRhythmForbidden} {
+		if errors.Is(err, contractErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// contractAliases are the ways the contract types were written before they were validated
+var contractAliases = map[string]utils.ContractType{
+	"cdi":            CDIContract,
+	"cdd":            CDDContract,
+	"stage":          InternshipContract,
+	"internship":     InternshipContract,
+	"intern":         InternshipContract,
+	"alternance":     WorkStudyContract,
+	"apprentissage":  WorkStudyContract,
+	"apprenticeship": WorkStudyContract,
+	"freelance":      FreelanceContract,
+	"independant":    FreelanceContract,
+	"indépendant":    FreelanceContract,
+	"interim":        InterimContract,
+	"intérim":        InterimContract,
+	"temporary":      InterimContract,
+}
+
+// ParseContractType finds the contract type of a free text value
+func ParseContractType(value string) (utils.ContractType, bool) {
+	contract, ok := contractAliases[strings.ToLower(strings.TrimSpace(value))]
+	return contract, ok
+}
+
+// contractAffinities are the contract types close enough to partly fit a candidate preferring the other one
+var contractAffinities = map[utils.ContractType][]utils.ContractType{
+	CDDContract:        {InterimContract},
+	InterimContract:    {CDDContract},
+	InternshipContract: {WorkStudyContract},
+	WorkStudyContract:  {InternshipContract},
+}
+
+// AreContractsClose tells whether two different contract types are close
+func AreContractsClose(a utils.ContractType, b utils.ContractType) bool {
+	for _, contract := range contractAffinities[a] {
+		if contract == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package models
+
+import "skillly/pkg/geo"
+
+// Coordinates are geocoded from a text location, they are missing when the location is unknown
+type Coordinates struct {
+	Latitude  *float64 `json:"latitude" gorm:"default:null;index:,composite:coordinates"`
+	Longitude *float64 `json:"longitude" gorm:"default:null;index:,composite:coordinates"`
+}
+
+// Locate geocodes a text location
+func Locate(location string) Coordinates {
+	point, ok := geo.Geocode(location)
+	if !ok {
+		return Coordinates{}
+	}
+	return Coordinates{Latitude: &point.Latitude, Longitude: &point.Longitude}
+}
+
+// Point returns the position, if it is known
+func (c Coordinates) Point() (geo.Point, bool) {
+	if c.Latitude == nil || c.Longitude == nil {
+		return geo.Point{}, false
+	}
+	return geo.Point{Latitude: *c.Latitude, Longitude: *c.Longitude}, true
+}
@@ -0,0 +1,11 @@
+package models
+
+import (
+	"time"
+)
+
+// DataMigration records a one-off rewrite of the data, so that it never runs again at the next startups
+type DataMigration struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	AppliedAt time.Time `json:"applied_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"skillly/pkg/utils"
+)
+
+const (
+	ProposedInterview  utils.InterviewState = "proposed"
+	ConfirmedInterview utils.InterviewState = "confirmed"
+	DeclinedInterview  utils.InterviewState = "declined"
+)
+
+// MaxInterviewSlots is the maximum number of slots of a proposal
+const MaxInterviewSlots = 5
+
+// Interview is an interview between the participants of a match, one of them proposes
+// slots and the other one accepts one of them or declines, either can reschedule it
+type Interview struct {
+	ID         uint                 `json:"id" gorm:"primaryKey"`
+	MatchID    uint                 `json:"match_id" gorm:"index"`
+	Match      *Match               `json:"match,omitempty" gorm:"foreignKey:MatchID;references:ID;constraint:OnDelete:CASCADE;"`
+	ProposerID uint                 `json:"proposer_id"` // User who proposed the slots
+	State      utils.InterviewState `json:"state" gorm:"default:'proposed'"`
+
+	// The slots and the start are stored in UTC, the timezone is the one of the proposer
+	Slots    []time.Time `json:"slots" gorm:"type:jsonb;serializer:json"`
+	StartsAt *time.Time  `json:"starts_at" gorm:"default:null"`
+	Duration int         `json:"duration"` // Minutes
+	Timezone string      `json:"timezone"`
+
+	VideoURL      string `json:"video_url"`
+	Location      string `json:"location"`
+	Note          string `json:"note"`
+	DeclineReason string `json:"decline_reason"`
+	Sequence      int    `json:"sequence"` // Incremented on each reschedule, for the calendars
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EndsAt returns the end of a confirmed interview
+func (i *Interview) EndsAt() time.Time {
+	if i.StartsAt == nil {
+		return time.Time{}
+	}
+	return i.StartsAt.Add(time.Duration(i.Duration) * time.Minute)
+}
@@ -7,27 +7,108 @@ import (
 )
 
 const (
-	CDIContract utils.ContractType = "CDI"
-	CDDContract utils.ContractType = "CDD"
+	OnSiteWork utils.WorkMode = "onsite"
+	HybridWork utils.WorkMode = "hybrid"
+	RemoteWork utils.WorkMode = "remote"
 )
 
+const (
+	DraftJobPost     utils.JobPostState = "draft"
+	PublishedJobPost utils.JobPostState = "published"
+	ClosedJobPost    utils.JobPostState = "closed"
+	ExpiredJobPost   utils.JobPostState = "expired"
+)
+
+// jobPostTransitions lists the states a job post can move to from each state,
+// a closed or expired job post is reopened by publishing it again
+var jobPostTransitions = map[utils.JobPostState][]utils.JobPostState{
+	DraftJobPost:     {PublishedJobPost},
+	PublishedJobPost: {ClosedJobPost, ExpiredJobPost},
+	ClosedJobPost:    {PublishedJobPost},
+	ExpiredJobPost:   {PublishedJobPost, ClosedJobPost},
+}
+
+// CanTransitionJobPost tells whether a job post can move from a state to another
+func CanTransitionJobPost(from utils.JobPostState, to utils.JobPostState) bool {
+	for _, state := range jobPostTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
 // JobPost is a struct that represents a job post
 type JobPost struct {
 	ID              uint               `json:"id" gorm:"primaryKey"`
 	Description     string             `json:"description"`
 	Title           string             `json:"title"`
 	Location        string             `json:"location"`
+	Coordinates     Coordinates        `json:"coordinates" gorm:"embedded"` // Geocoded from the location
+	WorkMode        utils.WorkMode     `json:"work_mode" gorm:"default:'onsite'"`
 	Contract_type   utils.ContractType `json:"contract_type"`
-	Salary_range    string             `json:"salary_range"`
+	ContractTerms   ContractTerms      `json:"contract_terms" gorm:"embedded;embeddedPrefix:contract_"`
+	Salary_range    string             `json:"salary_range"` // Label of the salary, the amounts are in Salary
+	Salary          Salary             `json:"salary" gorm:"embedded;embeddedPrefix:salary_"`
+	ExperienceYear  int                `json:"experience_year"` // Years of experience required
 	Expiration_Date time.Time          `json:"expiration_date"`
+	State           utils.JobPostState `json:"state" gorm:"default:'published';index"` // Only the published job posts receive applications
 	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
 	FileID          *uint              `json:"file_id" gorm:"default:null"`
 	File            File               `json:"file" gorm:"foreignKey:FileID;references:ID"`
 	CompanyID       uint               `json:"company_id"`
 	Company         Company            `json:"company" gorm:"foreignKey:CompanyID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	RecruiterID     *uint              `json:"recruiter_id" gorm:"index;default:null"` // Recruiter in charge of the job post, its author by default
 
 	Certifications []Certification `json:"certifications" gorm:"many2many:JobPost_Certifications;constraint:OnDelete:CASCADE;"`
 	Skills         []Skill         `json:"skills" gorm:"many2many:JobPost_Skills;constraint:OnDelete:CASCADE;"`
 	Applications   []Application   `json:"applications" gorm:"foreignKey:JobPostID;references:ID;constraint:OnDelete:CASCADE;"`
 	Matches        []Match         `json:"matches" gorm:"foreignKey:JobPostID;references:ID;constraint:OnDelete:CASCADE;"`
 }
+
+// IsOpen tells whether the job post receives applications
+func (j *JobPost) IsOpen(now time.Time) bool {
+	return j.State == PublishedJobPost && j.Expiration_Date.After(now)
+}
+
+// JobFeedItem is a job post of the feed of a candidate with its relevance
+type JobFeedItem struct {
+	JobPost
+	Relevance float64 `json:"relevance"`
+}
+
+// JobFeedPage is a page of the feed, next_cursor is empty on the last page
+type JobFeedPage struct {
+	JobPosts   []JobFeedItem `json:"job_posts"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// JobSearchItem is a job post found by a search with the relevance of its text, 0 without text,
+// and its distance to the center of the radius when it is located
+type JobSearchItem struct {
+	JobPost
+	Rank     float64  `json:"rank"`
+	Distance *float64 `json:"distance_km,omitempty"`
+}
+
+// Facet is the number of job posts found for a value of a filter, ID is set for the skills and companies
+type Facet struct {
+	Value string `json:"value"`
+	ID    uint   `json:"id,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// JobSearchFacets are counted over the job posts found, ignoring the filter of the facet itself
+type JobSearchFacets struct {
+	Contracts []Facet `json:"contracts"`
+	Skills    []Facet `json:"skills"`
+	Companies []Facet `json:"companies"`
+}
+
+// JobSearchPage is a page of the job posts found, total counts every job post found
+type JobSearchPage struct {
+	JobPosts []JobSearchItem `json:"job_posts"`
+	Total    int64           `json:"total"`
+	Facets   JobSearchFacets `json:"facets"`
+}
@@ -14,10 +14,41 @@ type ProfileCandidate struct {
 	PreferedContract utils.ContractType `json:"prefered_contract"`
 	PreferedJob      string             `json:"prefered_job"`
 	Location         string             `json:"location"`
+	Coordinates      Coordinates        `json:"coordinates" gorm:"embedded"` // Geocoded from the location
+	PreferedWorkMode utils.WorkMode     `json:"prefered_work_mode"`          // Any work mode when empty
 	Availability     string             `json:"availability"`
 	ResumeID         uint               `json:"resume_id" gorm:"default:null"` // Ajout de la clé étrangère
 	Resume           File               `json:"resume" gorm:"foreignKey:ResumeID;references:ID"`
+	Discoverable     bool               `json:"discoverable" gorm:"default:false"` // Visible in the candidate search of the recruiters once the candidate opts in
+	ExpectedSalary   Salary             `json:"expected_salary" gorm:"embedded;embeddedPrefix:expected_salary_"`
 
 	Certifications []Certification `json:"certifications" gorm:"many2many:User_Certifications;constraint:OnDelete:CASCADE;"`
 	Skills         []Skill         `json:"skills" gorm:"many2many:User_Skills;constraint:OnDelete:CASCADE;"`
 }
+
+// CandidateSearchResult is a candidate found by a recruiter, scored when a job post is selected
+// and at a distance from the center of the radius when one is searched
+type CandidateSearchResult struct {
+	ProfileCandidate
+	User         CandidateSearchUser `json:"user"`
+	Score        *int                `json:"score,omitempty"`
+	ScoreFactors []ScoreFactor       `json:"score_factors,omitempty"`
+	Distance     *float64            `json:"distance_km,omitempty"`
+}
+
+// CandidateSearchPage is a page of the candidate search
+type CandidateSearchPage struct {
+	Candidates []CandidateSearchResult `json:"candidates"`
+	Total      int                     `json:"total"`
+}
+
+// CandidateSearchUser is the user of a candidate found by a recruiter, without the contact details
+type CandidateSearchUser struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func NewCandidateSearchUser(user User) CandidateSearchUser {
+	return CandidateSearchUser{ID: user.ID, FirstName: user.FirstName, LastName: user.LastName}
+}
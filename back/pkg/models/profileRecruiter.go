@@ -4,9 +4,14 @@ import (
 	"skillly/pkg/utils"
 )
 
+// A recruiter joining an existing company is pending until an admin of the company approves or rejects the request,
+// the recruiter creating a company is its first admin and is active. A removed recruiter left the company,
+// the profile is kept for the history of its matches and interviews
 const (
-	PendingState utils.RecruiterState = "pending"
-	ActiveState  utils.RecruiterState = "active"
+	PendingState  utils.RecruiterState = "pending"
+	ActiveState   utils.RecruiterState = "active"
+	RejectedState utils.RecruiterState = "rejected"
+	RemovedState  utils.RecruiterState = "removed"
 )
 
 const (
@@ -25,3 +30,8 @@ type ProfileRecruiter struct {
 	CompanyID uint                 `json:"company_id"`
 	Company   Company              `json:"company" gorm:"foreignKey:CompanyID;references:ID"`
 }
+
+// IsActive tells if the recruiter can act for the company
+func (r ProfileRecruiter) IsActive() bool {
+	return r.State == ActiveState
+}
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"skillly/pkg/utils"
+)
+
+const (
+	HourlySalary  utils.SalaryPeriod = "hourly"
+	MonthlySalary utils.SalaryPeriod = "monthly"
+	YearlySalary  utils.SalaryPeriod = "yearly"
+
+	GrossSalary utils.SalaryBasis = "gross"
+	NetSalary   utils.SalaryBasis = "net"
+
+	DefaultCurrency = "EUR"
+
+	// HoursPerYear is the legal working time of a full-time employee in France, used to compare hourly pay
+	HoursPerYear  = 1607
+	MonthsPerYear = 12
+)
+
+var ErrInvalidSalary = errors.New("The minimum salary must not be greater than the maximum salary")
+
+// Salary is a range of pay, either bound can be missing. The salaries are compared on their yearly
+// amounts in the same currency, the gross and net amounts are not converted
+type Salary struct {
+	Min      *int               `json:"min" gorm:"default:null" binding:"omitempty,min=0"`
+	Max      *int               `json:"max" gorm:"default:null" binding:"omitempty,min=0"`
+	Currency string             `json:"currency" binding:"omitempty,len=3,uppercase"`
+	Period   utils.SalaryPeriod `json:"period" binding:"omitempty,oneof=hourly monthly yearly"`
+	Basis    utils.SalaryBasis  `json:"basis" binding:"omitempty,oneof=gross net"`
+}
+
+// IsSet reports whether the salary has an amount
+func (s Salary) IsSet() bool {
+	return s.Min != nil || s.Max != nil
+}
+
+// Normalize checks the bounds and fills the currency, period and basis which are not given
+func (s *Salary) Normalize() error {
+	if s.Min != nil && s.Max != nil && *s.Min > *s.Max {
+		return ErrInvalidSalary
+	}
+	if !s.IsSet() {
+		*s = Salary{}
+		return nil
+	}
+	if s.Currency == "" {
+		s.Currency = DefaultCurrency
+	}
+	if s.Period == "" {
+		s.Period = YearlySalary
+	}
+	if s.Basis == "" {
+		s.Basis = GrossSalary
+	}
+	return nil
+}
+
+// YearlyMin returns the lower bound over a year, or the upper bound when there is no lower bound
+func (s Salary) YearlyMin() (float64, bool) {
+	if s.Min != nil {
+		return Yearly(*s.Min, s.Period), true
+	}
+	if s.Max != nil {
+		return Yearly(*s.Max, s.Period), true
+	}
+	return 0, false
+}
+
+// YearlyMax returns the upper bound over a year, or the lower bound when there is no upper bound
+func (s Salary) YearlyMax() (float64, bool) {
+	if s.Max != nil {
+		return Yearly(*s.Max, s.Period), true
+	}
+	return s.YearlyMin()
+}
+
+// Yearly converts an amount paid every period to an amount over a year
+func Yearly(amount int, period utils.SalaryPeriod) float64 {
+	switch period {
+	case HourlySalary:
+		return float64(amount) * HoursPerYear
+	case MonthlySalary:
+		return float64(amount) * MonthsPerYear
+	default:
+		return float64(amount)
+	}
+}
+
+var (
+	salaryPeriodLabels = map[utils.SalaryPeriod]string{HourlySalary: "heure", MonthlySalary: "mois", YearlySalary: "an"}
+	salaryBasisLabels  = map[utils.SalaryBasis]string{GrossSalary: "brut", NetSalary: "net"}
+)
+
+// String describes the salary in French, such as "45000 - 55000 EUR brut/an"
+func (s Salary) String() string {
+	var amounts string
+	switch {
+	case s.Min != nil && s.Max != nil && *s.Min != *s.Max:
+		amounts = fmt.Sprintf("%d - %d", *s.Min, *s.Max)
+	case s.Min != nil:
+		amounts = strconv.Itoa(*s.Min)
+	case s.Max != nil:
+		amounts = fmt.Sprintf("jusqu'à %d", *s.Max)
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s %s %s/%s", amounts, s.Currency, salaryBasisLabels[s.Basis], salaryPeriodLabels[s.Period])
+}
+
+var (
+	salaryAmount     = regexp.MustCompile(`\d+(?:[ .,\x{a0}\x{202f}]\d{3})*(?:[.,]\d+)?\s*k?`)
+	thousandsAmount  = regexp.MustCompile(`^\d{1,3}(?:[ .,\x{a0}\x{202f}]\d{3})+$`)
+	hourlyWords      = regexp.MustCompile(`/\s*h\b|\bheures?\b|\bhours?\b|\bhourly\b|\bhoraires?\b`)
+	monthlyWords     = regexp.MustCompile(`/\s*m\b|\bmois\b|\bmonths?\b|\bmonthly\b|\bmensuel(?:le)?s?\b`)
+	yearlyWords      = regexp.MustCompile(`/\s*an?\b|\bans?\b|\bannuel(?:le)?s?\b|\byears?\b|\byearly\b|\bannual\b|\bpa\b`)
+	netWords         = regexp.MustCompile(`\bnet\b|\bnette?s?\b`)
+	salaryCurrencies = []struct {
+		code    string
+		symbols []string
+	}{
+		{"EUR", []string{"€", "eur"}},
+		{"USD", []string{"$", "usd"}},
+		{"GBP", []string{"£", "gbp"}},
+		{"CHF", []string{"chf"}},
+	}
+)
+
+// ParseSalary reads a salary written as free text, such as "40,000 - 50,000 EUR", "45k€ brut/an"
+// or "2 500 € net par mois". Without period the amounts tell whether they are hourly, monthly or yearly
+func ParseSalary(text string) (Salary, bool) {
+	text = strings.ToLower(text)
+
+	amounts := []int{}
+	matches := []string{}
+	for _, match := range salaryAmount.FindAllString(text, -1) {
+		if amount, ok := parseAmount(match); ok {
+			amounts = append(amounts, amount)
+			matches = append(matches, strings.TrimSpace(match))
+		}
+		if len(amounts) == 2 {
+			break
+		}
+	}
+	if len(amounts) == 0 {
+		return Salary{}, false
+	}
+	// "40-50k" gives the k of the upper bound to the lower one
+	if len(amounts) == 2 && strings.HasSuffix(matches[1], "k") && !strings.HasSuffix(matches[0], "k") && amounts[0] < 1000 {
+		amounts[0], _ = parseAmount(matches[0] + "k")
+	}
+
+	min, max := amounts[0], amounts[len(amounts)-1]
+	if min > max {
+		min, max = max, min
+	}
+	salary := Salary{Min: &min, Max: &max, Currency: DefaultCurrency, Basis: GrossSalary}
+
+	for _, currency := range salaryCurrencies {
+		for _, symbol := range currency.symbols {
+			if strings.Contains(text, symbol) {
+				salary.Currency = currency.code
+			}
+		}
+	}
+
+	switch {
+	case hourlyWords.MatchString(text):
+		salary.Period = HourlySalary
+	case monthlyWords.MatchString(text):
+		salary.Period = MonthlySalary
+	case yearlyWords.MatchString(text):
+		salary.Period = YearlySalary
+	case max >= 10000:
+		salary.Period = YearlySalary
+	case max >= 500:
+		salary.Period = MonthlySalary
+	default:
+		salary.Period = HourlySalary
+	}
+
+	if netWords.MatchString(text) {
+		salary.Basis = NetSalary
+	}
+
+	return salary, true
+}
+
+// parseAmount reads an amount with thousands separators, decimals or a "k" suffix
+func parseAmount(text string) (int, bool) {
+	text = strings.TrimSpace(text)
+	multiplier := 1.0
+	if strings.HasSuffix(text, "k") {
+		multiplier = 1000
+		text = strings.TrimSpace(strings.TrimSuffix(text, "k"))
+	}
+
+	if thousandsAmount.MatchString(text) {
+		text = strings.NewReplacer(" ", "", ".", "", ",", "", "\u00a0", "", "\u202f", "").Replace(text)
+	} else {
+		text = strings.ReplaceAll(text, ",", ".")
+	}
+
+	amount, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(math.Round(amount * multiplier)), true
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// ScheduledJob is the last run of a periodic job, it is kept in the database so that
+// the schedule survives the restarts and is shared by the instances of the backend
+type ScheduledJob struct {
+	Name      string     `json:"name" gorm:"primaryKey"`
+	LastRunAt *time.Time `json:"last_run_at" gorm:"default:null"`
+	LastError string     `json:"last_error"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsDue reports whether the job must run again at now
+func (j *ScheduledJob) IsDue(interval time.Duration, now time.Time) bool {
+	return j.LastRunAt == nil || !now.Before(j.LastRunAt.Add(interval))
+}
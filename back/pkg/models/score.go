@@ -0,0 +1,11 @@
+package models
+
+// ScoreFactor is the contribution of one criterion to the score of an application
+// Ratio is how well the candidate meets the criterion (0 to 1), Points is Ratio * Weight
+type ScoreFactor struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Ratio  float64 `json:"ratio"`
+	Points float64 `json:"points"`
+	Detail string  `json:"detail"`
+}
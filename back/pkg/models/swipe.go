@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"skillly/pkg/utils"
+)
+
+const (
+	LikeSwipe utils.SwipeDecision = "like"
+	PassSwipe utils.SwipeDecision = "pass"
+)
+
+// Swipe is the decision of a recruiter on a candidate for a job post,
+// a like and an application of the candidate make a match
+type Swipe struct {
+	ID          uint                `json:"id" gorm:"primaryKey"`
+	JobPostID   uint                `json:"job_post_id" gorm:"uniqueIndex:idx_swipe_job_post_candidate"`
+	JobPost     JobPost             `json:"job_post" gorm:"foreignKey:JobPostID;references:ID;constraint:OnDelete:CASCADE;"`
+	CandidateID uint                `json:"candidate_id" gorm:"uniqueIndex:idx_swipe_job_post_candidate"`
+	Candidate   ProfileCandidate    `json:"candidate" gorm:"foreignKey:CandidateID;references:ID;constraint:OnDelete:CASCADE;"`
+	RecruiterID uint                `json:"recruiter_id"`
+	Decision    utils.SwipeDecision `json:"decision"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
@@ -23,6 +23,8 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 
+	EmailVerifiedAt *time.Time `json:"email_verified_at" gorm:"default:null"`
+
 	ProfileCandidate *ProfileCandidate `json:"profile_candidate" gorm:"foreignKey:UserID;references:ID"`
 	ProfileRecruiter *ProfileRecruiter `json:"profile_recruiter" gorm:"foreignKey:UserID;references:ID"`
 }
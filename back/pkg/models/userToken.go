@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"skillly/pkg/utils"
+)
+
+const (
+	EmailVerificationPurpose utils.TokenPurpose = "email_verification"
+	PasswordResetPurpose     utils.TokenPurpose = "password_reset"
+	CalendarFeedPurpose      utils.TokenPurpose = "calendar_feed"
+)
+
+// UserToken is a struct that represents a single-use token sent by email,
+// or the token of the calendar feed URL of a user which is not consumed
+type UserToken struct {
+	ID        uint               `json:"id" gorm:"primaryKey"`
+	UserID    uint               `json:"user_id" gorm:"index"`
+	User      User               `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE;"`
+	Purpose   utils.TokenPurpose `json:"purpose"`
+	TokenHash string             `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    *time.Time         `json:"used_at" gorm:"default:null"`
+	CreatedAt time.Time          `json:"created_at"`
+}
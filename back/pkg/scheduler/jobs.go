@@ -0,0 +1,265 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	chatConfig "skillly/chat/config"
+	"skillly/chat/handlers/attachment"
+	"skillly/chat/handlers/room"
+	chatModels "skillly/chat/models"
+	"skillly/chat/notification"
+	"skillly/chat/storage"
+	"skillly/pkg/config"
+	"skillly/pkg/handlers/interview"
+	"skillly/pkg/handlers/jobPost"
+	"skillly/pkg/handlers/match"
+	"skillly/pkg/mailer"
+	"skillly/pkg/models"
+)
+
+const (
+	// PendingAttachmentTTL is how long an uploaded file waits for its message before it is deleted
+	PendingAttachmentTTL = 24 * time.Hour
+	// AbandonedRoomGrace is how long a room is kept after its match was found deleted,
+	// a young room is also kept since its match may be being created
+	AbandonedRoomGrace = 24 * time.Hour
+	// DigestInterval is the period of the reminder digests, the interviews of the next period are reminded
+	DigestInterval = 24 * time.Hour
+)
+
+// DefaultJobs returns the periodic jobs of the backend
+func DefaultJobs() []Job {
+	return []Job{
+		{Name: "expire_job_posts", Interval: 15 * time.Minute, Run: ExpireJobPosts},
+		{Name: "close_stale_applications", Interval: time.Hour, Run: CloseStaleApplications},
+		{Name: "purge_pending_attachments", Interval: time.Hour, Run: PurgePendingAttachments},
+		{Name: "purge_abandoned_rooms", Interval: 24 * time.Hour, Run: PurgeAbandonedRooms},
+		{Name: "send_reminder_digests", Interval: DigestInterval, Run: SendReminderDigests},
+	}
+}
+
+// ExpireJobPosts moves the published job posts past their expiration date to expired
+func ExpireJobPosts(ctx context.Context, now time.Time) error {
+	count, err := jobPost.NewJobPostRepository(config.DB).ExpireJobPosts(now, config.DB.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("expired %d job posts", count)
+	}
+	return nil
+}
+
+// CloseStaleApplications closes the pending applications of the job posts which are no longer open
+// and tells their candidates
+func CloseStaleApplications(ctx context.Context, now time.Time) error {
+	tx := config.DB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	applications, err := jobPost.NewJobPostRepository(config.DB).CloseStaleApplications(now, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	for _, application := range applications {
+		var userIDs []uint
+		err := config.DB.Model(&models.ProfileCandidate{}).Where("id = ?", application.CandidateID).Pluck("user_id", &userIDs).Error
+		if err != nil {
+			continue
+		}
+		notification.Send(userIDs, notification.Event{
+			Type:          notification.ApplicationStateEvent,
+			ApplicationID: application.ID,
+			JobPostID:     application.JobPostID,
+			State:         string(application.State),
+		})
+	}
+	return nil
+}
+
+// PurgePendingAttachments deletes the files uploaded in the rooms and never sent
+func PurgePendingAttachments(ctx context.Context, now time.Time) error {
+	attachments, err := attachment.NewAttachmentRepository(chatConfig.DBMongo).DeletePendingAttachments(now.Add(-PendingAttachmentTTL))
+	if err != nil {
+		return err
+	}
+
+	deleteFiles(attachments)
+	return nil
+}
+
+// PurgeAbandonedRooms deletes the chat rooms whose match was deleted for longer than the grace period,
+// with their messages and files. The rooms are first marked as orphaned, the grace period runs from then
+func PurgeAbandonedRooms(ctx context.Context, now time.Time) error {
+	roomRepository := room.NewRoomRepository(chatConfig.DBMongo)
+	attachmentRepository := attachment.NewAttachmentRepository(chatConfig.DBMongo)
+
+	rooms, err := roomRepository.GetRoomsCreatedBefore(now.Add(-AbandonedRoomGrace))
+	if err != nil {
+		return err
+	}
+
+	orphans, err := orphanedRoomNames(ctx, rooms)
+	if err != nil {
+		return err
+	}
+
+	var newOrphans []string
+	for _, abandoned := range rooms {
+		if !orphans[abandoned.Name] {
+			continue
+		}
+		if abandoned.OrphanedAt == nil {
+			newOrphans = append(newOrphans, abandoned.Name)
+			continue
+		}
+		if abandoned.OrphanedAt.After(now.Add(-AbandonedRoomGrace)) {
+			continue
+		}
+
+		attachments, err := attachmentRepository.DeleteRoomAttachments(abandoned.Name)
+		if err != nil {
+			return err
+		}
+		deleteFiles(attachments)
+
+		if err := roomRepository.DeleteRoom(abandoned.Name); err != nil {
+			return err
+		}
+		log.Printf("deleted abandoned room %s", abandoned.Name)
+	}
+
+	return roomRepository.MarkOrphanedRooms(newOrphans, now)
+}
+
+// orphanedRoomNames returns the names of the rooms whose match doesn't exist, a room is named after its match
+func orphanedRoomNames(ctx context.Context, rooms []chatModels.Room) (map[string]bool, error) {
+	orphans := map[string]bool{}
+	matchIDs := []string{}
+	for _, candidate := range rooms {
+		if _, err := strconv.ParseUint(candidate.Name, 10, 63); err != nil {
+			orphans[candidate.Name] = true
+			continue
+		}
+		matchIDs = append(matchIDs, candidate.Name)
+	}
+	if len(matchIDs) == 0 {
+		return orphans, nil
+	}
+
+	var missing []string
+	err := config.DB.WithContext(ctx).Raw(`SELECT room.name FROM unnest(?::text[]) AS room(name)
+		WHERE NOT EXISTS (SELECT 1 FROM matches WHERE matches.id = room.name::bigint)`,
+		"{"+strings.Join(matchIDs, ",")+"}").
+		Scan(&missing).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range missing {
+		orphans[name] = true
+	}
+	return orphans, nil
+}
+
+// SendReminderDigests emails the recruiters the number of applications waiting for them,
+// and the participants of the interviews of the next day
+func SendReminderDigests(ctx context.Context, now time.Time) error {
+	db := config.DB.WithContext(ctx)
+	reminders := map[uint][]string{}
+
+	var pending []struct {
+		CompanyID uint
+		Count     int
+	}
+	err := db.Table("applications").
+		Select("job_posts.company_id, COUNT(*) AS count").
+		Joins("JOIN job_posts ON job_posts.id = applications.job_post_id").
+		Where("applications.state = ? AND job_posts.state = ?", models.PendingApplication, models.PublishedJobPost).
+		Group("job_posts.company_id").
+		Scan(&pending).Error
+	if err != nil {
+		return err
+	}
+	for _, company := range pending {
+		var recruiterIDs []uint
+		if err := db.Model(&models.ProfileRecruiter{}).Where("company_id = ? AND state = ?", company.CompanyID, models.ActiveState).Pluck("user_id", &recruiterIDs).Error; err != nil {
+			return err
+		}
+		for _, recruiterID := range recruiterIDs {
+			reminders[recruiterID] = append(reminders[recruiterID], fmt.Sprintf("%d candidature(s) en attente de réponse", company.Count))
+		}
+	}
+
+	interviews, err := interview.NewInterviewRepository(config.DB).GetConfirmedInterviewsBetween(now, now.Add(DigestInterval), db)
+	if err != nil {
+		return err
+	}
+	matchRepository := match.NewMatchRepository(config.DB)
+	for _, upcoming := range interviews {
+		participants, err := matchRepository.GetParticipantUserIDs(upcoming.MatchID)
+		if err != nil {
+			continue
+		}
+		for _, userID := range participants {
+			reminders[userID] = append(reminders[userID], interviewReminder(upcoming))
+		}
+	}
+
+	userIDs := make([]uint, 0, len(reminders))
+	for userID := range reminders {
+		userIDs = append(userIDs, userID)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	var users []models.User
+	if err := db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return err
+	}
+	for _, user := range users {
+		mail := mailer.Mail{
+			To:      user.Email,
+			Subject: "Skillly - Votre récapitulatif",
+			Body: fmt.Sprintf("Bonjour %s,\n\nVoici ce qui vous attend sur Skillly :\n\n- %s\n",
+				user.FirstName, strings.Join(reminders[user.ID], "\n- ")),
+		}
+		if err := mailer.Default.Send(mail); err != nil {
+			log.Printf("error sending mail to %s: %v", mail.To, err)
+		}
+	}
+	return nil
+}
+
+// interviewReminder describes an interview in the timezone it was proposed in
+func interviewReminder(upcoming models.Interview) string {
+	startsAt := *upcoming.StartsAt
+	if location, err := time.LoadLocation(upcoming.Timezone); err == nil {
+		startsAt = startsAt.In(location)
+	}
+
+	event := interview.CalendarEvent(upcoming)
+	return fmt.Sprintf("%s le %s (%s)", event.Summary, startsAt.Format("02/01/2006 à 15:04"), startsAt.Location())
+}
+
+// deleteFiles removes the files of deleted attachments from the storage
+func deleteFiles(attachments []chatModels.Attachment) {
+	for _, deleted := range attachments {
+		if err := storage.Default.Delete(deleted.StorageKey); err != nil {
+			log.Printf("error deleting file %s: %v", deleted.StorageKey, err)
+		}
+	}
+}
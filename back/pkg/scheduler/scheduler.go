@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"skillly/pkg/config"
+	"skillly/pkg/models"
+)
+
+// Tick is how often the scheduler looks for the jobs to run
+const Tick = time.Minute
+
+// Job is a periodic job, it runs at most once per interval across all the instances of the backend
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) error
+}
+
+// Scheduler runs the periodic jobs in the process, the last run of each job is kept in the
+// database and a Postgres advisory lock stops two instances from running the same job at once
+type Scheduler struct {
+	db   *gorm.DB
+	jobs []Job
+}
+
+func New(db *gorm.DB, jobs ...Job) *Scheduler {
+	return &Scheduler{db: db, jobs: jobs}
+}
+
+// SetupScheduler starts the periodic jobs unless SCHEDULER=off (tests, one-off commands)
+func SetupScheduler() {
+	if os.Getenv("SCHEDULER") == "off" {
+		log.Printf("Scheduler: disabled")
+		return
+	}
+
+	jobs := DefaultJobs()
+	go New(config.DB, jobs...).Start(context.Background())
+	log.Printf("Scheduler: %d jobs", len(jobs))
+}
+
+// Start runs the due jobs every tick until the context is done
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(Tick)
+	defer ticker.Stop()
+
+	for {
+		s.RunDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs the jobs due at now one after the other and returns the names of the jobs it ran
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) []string {
+	ran := []string{}
+	for _, job := range s.jobs {
+		done, err := s.runJob(ctx, job, now)
+		if err != nil {
+			log.Printf("error running job %s: %v", job.Name, err)
+		}
+		if done {
+			ran = append(ran, job.Name)
+		}
+	}
+	return ran
+}
+
+// runJob runs the job if it is due and no other instance is running it, the advisory lock
+// is held by the transaction until the run is recorded
+func (s *Scheduler) runJob(ctx context.Context, job Job, now time.Time) (bool, error) {
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockKey(job.Name)).Scan(&locked).Error; err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+
+	// Read the last run once locked, another instance may have just run the job
+	state := models.ScheduledJob{Name: job.Name}
+	if err := tx.FirstOrCreate(&state, models.ScheduledJob{Name: job.Name}).Error; err != nil {
+		return false, err
+	}
+	if !state.IsDue(job.Interval, now) {
+		return false, nil
+	}
+
+	runErr := job.Run(ctx, now)
+	state.LastRunAt = &now
+	state.LastError = ""
+	if runErr != nil {
+		state.LastError = runErr.Error()
+	}
+	if err := tx.Save(&state).Error; err != nil {
+		return false, err
+	}
+
+	return true, errors.Join(runErr, tx.Commit().Error)
+}
+
+// lockKey is the key of the advisory lock of a job
+func lockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("scheduler:" + name))
+	return int64(hash.Sum64())
+}
@@ -0,0 +1,139 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"skillly/pkg/geo"
+	"skillly/pkg/models"
+)
+
+// Weights of the criteria ranking the job feed of a candidate, the relevance is out of 100
+const (
+	FeedSkillsWeight   = 50
+	FeedContractWeight = 15
+	FeedLocationWeight = 15
+	FeedRecencyWeight  = 20
+)
+
+// RecencyHalfLife is the age at which a job post loses half of its recency points
+const RecencyHalfLife = 7 * 24 * time.Hour
+
+// Relevance ranks a job post in the feed of a candidate at the given time,
+// the skills of both must be loaded
+func Relevance(candidate models.ProfileCandidate, jobPost models.JobPost, now time.Time) float64 {
+	relevance := skillsFactor(candidate, jobPost).Ratio*FeedSkillsWeight +
+		contractFactor(candidate, jobPost).Ratio*FeedContractWeight +
+		locationFactor(candidate, jobPost).Ratio*FeedLocationWeight +
+		recency(jobPost.CreatedAt, now)*FeedRecencyWeight
+
+	return math.Round(relevance*100) / 100
+}
+
+// recency halves every RecencyHalfLife, from 1 for a job post published now
+func recency(createdAt time.Time, now time.Time) float64 {
+	age := now.Sub(createdAt)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(RecencyHalfLife))
+}
+
+// FeedRelevanceSQL is the relevance of Relevance written on the columns of job_posts, so that the database
+// ranks and paginates the feed of the candidate. The skills of the candidate must be loaded
+func FeedRelevanceSQL(candidate models.ProfileCandidate, now time.Time) (string, []interface{}) {
+	owned := []uint{}
+	for _, skill := range candidate.Skills {
+		owned = append(owned, skill.ID)
+	}
+
+	factors := []sqlExpr{
+		feedPoints(feedSkillsSQL(owned), FeedSkillsWeight),
+		feedPoints(feedContractSQL(candidate), FeedContractWeight),
+		feedPoints(feedLocationSQL(candidate), FeedLocationWeight),
+		{
+			sql:  fmt.Sprintf("POWER(0.5, GREATEST(0, EXTRACT(EPOCH FROM (?::timestamptz - job_posts.created_at))) / %v)::numeric * %v", RecencyHalfLife.Seconds(), FeedRecencyWeight),
+			args: []interface{}{now},
+		},
+	}
+
+	sqls := []string{}
+	args := []interface{}{}
+	for _, factor := range factors {
+		sqls = append(sqls, factor.sql)
+		args = append(args, factor.args...)
+	}
+	return "ROUND(" + strings.Join(sqls, " + ") + ", 2)", args
+}
+
+// feedPoints weighs the rounded ratio of a factor like Relevance
+func feedPoints(ratio sqlExpr, weight float64) sqlExpr {
+	return sqlExpr{sql: fmt.Sprintf("ROUND((%s)::numeric, 2) * %v", ratio.sql, weight), args: ratio.args}
+}
+
+// feedSkillsSQL is the share of the skills of the job post owned by the candidate, like skillsFactor
+func feedSkillsSQL(owned []uint) sqlExpr {
+	required := "(SELECT COUNT(*) FROM job_post_skills WHERE job_post_skills.job_post_id = job_posts.id)"
+	if len(owned) == 0 {
+		return sqlExpr{sql: fmt.Sprintf("CASE WHEN %s = 0 THEN 1 ELSE 0 END", required)}
+	}
+	return sqlExpr{
+		sql: fmt.Sprintf("CASE WHEN %[1]s = 0 THEN 1 ELSE (SELECT COUNT(*) FROM job_post_skills WHERE job_post_skills.job_post_id = job_posts.id "+
+			"AND job_post_skills.skill_id IN ?)::numeric / %[1]s END", required),
+		args: []interface{}{owned},
+	}
+}
+
+// feedContractSQL compares the contract of the job post with the preferred contract, like contractFactor
+func feedContractSQL(candidate models.ProfileCandidate) sqlExpr {
+	if candidate.PreferedContract == "" {
+		return constant(1)
+	}
+
+	closeContracts := []string{}
+	for _, contract := range models.ContractTypes {
+		if models.AreContractsClose(candidate.PreferedContract, contract) {
+			closeContracts = append(closeContracts, string(contract))
+		}
+	}
+
+	expr := sqlExpr{
+		sql:  "CASE WHEN job_posts.contract_type = ? THEN 1",
+		args: []interface{}{string(candidate.PreferedContract)},
+	}
+	if len(closeContracts) > 0 {
+		expr.sql += " WHEN job_posts.contract_type IN ? THEN 0.5"
+		expr.args = append(expr.args, closeContracts)
+	}
+	expr.sql += " ELSE 0 END"
+	return expr
+}
+
+// feedLocationSQL compares the location of the job post with the candidate's, like locationFactor
+func feedLocationSQL(candidate models.ProfileCandidate) sqlExpr {
+	jobLocation := normalizeLocationSQL("job_posts.location")
+	expr := sqlExpr{
+		sql:  fmt.Sprintf("CASE WHEN job_posts.work_mode = ? OR %[1]s = '' OR %[1]s IN ? THEN 1", jobLocation),
+		args: []interface{}{string(models.RemoteWork), remoteLocations},
+	}
+	if candidate.PreferedWorkMode == models.RemoteWork {
+		expr.sql += " WHEN COALESCE(job_posts.work_mode, '') <> ? THEN 0"
+		expr.args = append(expr.args, string(models.HybridWork))
+	}
+	if candidatePoint, ok := candidate.Coordinates.Point(); ok {
+		expr.sql += fmt.Sprintf(" WHEN job_posts.latitude IS NOT NULL THEN LEAST(1, GREATEST(0, (%d - %s) / %d))",
+			FarDistance, geo.DistanceSQL("job_posts"), FarDistance-NearbyDistance)
+		expr.args = append(expr.args, candidatePoint.DistanceArgs()...)
+	}
+
+	candidateLocation := normalizeLocation(candidate.Location)
+	if candidateLocation == "" {
+		expr.sql += " ELSE 0.5 END"
+		return expr
+	}
+	expr.sql += fmt.Sprintf(" WHEN btrim(split_part(%s, ',', 1)) = ? THEN 1 ELSE 0 END", jobLocation)
+	expr.args = append(expr.args, city(candidateLocation))
+	return expr
+}
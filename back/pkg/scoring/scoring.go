@@ -0,0 +1,211 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"skillly/pkg/geo"
+	"skillly/pkg/models"
+)
+
+// Weights of the criteria, the score of an application is out of 100
+const (
+	SkillsWeight         = 40
+	CertificationsWeight = 20
+	ExperienceWeight     = 15
+	ContractWeight       = 10
+	LocationWeight       = 10
+	SalaryWeight         = 5
+)
+
+// Factor names, sent to the clients with the score
+const (
+	SkillsFactor         = "skills"
+	CertificationsFactor = "certifications"
+	ExperienceFactor     = "experience"
+	ContractFactor       = "contract"
+	LocationFactor       = "location"
+	SalaryFactor         = "salary"
+)
+
+// Remote job posts accept candidates from anywhere
+var remoteLocations = []string{"remote", "teletravail", "full remote"}
+
+// The located candidates get every location point up to NearbyDistance from the job post,
+// and no point from FarDistance, in kilometers
+const (
+	NearbyDistance = 30
+	FarDistance    = 100
+)
+
+// Compute returns the compatibility score (0 to 100) of a candidate for a job post
+// and the contribution of each criterion, the skills and certifications of both must be loaded
+func Compute(candidate models.ProfileCandidate, jobPost models.JobPost) (int, []models.ScoreFactor) {
+	factors := []models.ScoreFactor{
+		skillsFactor(candidate, jobPost),
+		certificationsFactor(candidate, jobPost),
+		experienceFactor(candidate, jobPost),
+		contractFactor(candidate, jobPost),
+		locationFactor(candidate, jobPost),
+		salaryFactor(candidate, jobPost),
+	}
+
+	total := 0.0
+	for _, factor := range factors {
+		total += factor.Points
+	}
+
+	return int(math.Round(total)), factors
+}
+
+func newFactor(name string, weight float64, ratio float64, detail string) models.ScoreFactor {
+	return models.ScoreFactor{
+		Name:   name,
+		Weight: weight,
+		Ratio:  math.Round(ratio*100) / 100,
+		Points: math.Round(ratio*weight*100) / 100,
+		Detail: detail,
+	}
+}
+
+func skillsFactor(candidate models.ProfileCandidate, jobPost models.JobPost) models.ScoreFactor {
+	required := []uint{}
+	for _, skill := range jobPost.Skills {
+		required = append(required, skill.ID)
+	}
+	owned := map[uint]bool{}
+	for _, skill := range candidate.Skills {
+		owned[skill.ID] = true
+	}
+
+	ratio, matched := coverage(required, owned)
+	return newFactor(SkillsFactor, SkillsWeight, ratio, fmt.Sprintf("%d/%d required skills", matched, len(required)))
+}
+
+func certificationsFactor(candidate models.ProfileCandidate, jobPost models.JobPost) models.ScoreFactor {
+	required := []uint{}
+	for _, certification := range jobPost.Certifications {
+		required = append(required, certification.ID)
+	}
+	owned := map[uint]bool{}
+	for _, certification := range candidate.Certifications {
+		owned[certification.ID] = true
+	}
+
+	ratio, matched := coverage(required, owned)
+	return newFactor(CertificationsFactor, CertificationsWeight, ratio, fmt.Sprintf("%d/%d required certifications", matched, len(required)))
+}
+
+// coverage is the share of the required IDs owned by the candidate, nothing required is a full match
+func coverage(required []uint, owned map[uint]bool) (float64, int) {
+	if len(required) == 0 {
+		return 1, 0
+	}
+
+	matched := 0
+	for _, id := range required {
+		if owned[id] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(required)), matched
+}
+
+func experienceFactor(candidate models.ProfileCandidate, jobPost models.JobPost) models.ScoreFactor {
+	detail := fmt.Sprintf("%d/%d years of experience", candidate.ExperienceYear, jobPost.ExperienceYear)
+	if jobPost.ExperienceYear <= 0 {
+		return newFactor(ExperienceFactor, ExperienceWeight, 1, "No experience required")
+	}
+
+	ratio := math.Min(1, math.Max(0, float64(candidate.ExperienceYear)/float64(jobPost.ExperienceYear)))
+	return newFactor(ExperienceFactor, ExperienceWeight, ratio, detail)
+}
+
+func contractFactor(candidate models.ProfileCandidate, jobPost models.JobPost) models.ScoreFactor {
+	switch {
+	case candidate.PreferedContract == "":
+		return newFactor(ContractFactor, ContractWeight, 1, "No preferred contract")
+	case candidate.PreferedContract == jobPost.Contract_type:
+		return newFactor(ContractFactor, ContractWeight, 1, "Preferred contract")
+	case models.AreContractsClose(candidate.PreferedContract, jobPost.Contract_type):
+		return newFactor(ContractFactor, ContractWeight, 0.5, fmt.Sprintf("Prefers %s, close to %s", candidate.PreferedContract, jobPost.Contract_type))
+	default:
+		return newFactor(ContractFactor, ContractWeight, 0, fmt.Sprintf("Prefers %s", candidate.PreferedContract))
+	}
+}
+
+func locationFactor(candidate models.ProfileCandidate, jobPost models.JobPost) models.ScoreFactor {
+	jobLocation := normalizeLocation(jobPost.Location)
+	candidateLocation := normalizeLocation(candidate.Location)
+
+	jobPoint, jobLocated := jobPost.Coordinates.Point()
+	candidatePoint, candidateLocated := candidate.Coordinates.Point()
+
+	switch {
+	case jobPost.WorkMode == models.RemoteWork || jobLocation == "" || isRemote(jobLocation):
+		return newFactor(LocationFactor, LocationWeight, 1, "Remote or no location")
+	case candidate.PreferedWorkMode == models.RemoteWork && jobPost.WorkMode != models.HybridWork:
+		return newFactor(LocationFactor, LocationWeight, 0, "Prefers remote work")
+	case jobLocated && candidateLocated:
+		distance := geo.Distance(jobPoint, candidatePoint)
+		ratio := math.Min(1, math.Max(0, (FarDistance-distance)/(FarDistance-NearbyDistance)))
+		return newFactor(LocationFactor, LocationWeight, ratio, fmt.Sprintf("%.0f km away", distance))
+	case candidateLocation == "":
+		return newFactor(LocationFactor, LocationWeight, 0.5, "Candidate location unknown")
+	case city(jobLocation) == city(candidateLocation):
+		return newFactor(LocationFactor, LocationWeight, 1, "Same city")
+	default:
+		return newFactor(LocationFactor, LocationWeight, 0, "Different city")
+	}
+}
+
+// salaryFactor compares the best yearly pay of the job post with the lowest salary expected by the candidate,
+// the salaries in different currencies are not converted
+func salaryFactor(candidate models.ProfileCandidate, jobPost models.JobPost) models.ScoreFactor {
+	expected, hasExpectation := candidate.ExpectedSalary.YearlyMin()
+	offered, hasOffer := jobPost.Salary.YearlyMax()
+
+	switch {
+	case !hasExpectation:
+		return newFactor(SalaryFactor, SalaryWeight, 1, "No expected salary")
+	case !hasOffer:
+		return newFactor(SalaryFactor, SalaryWeight, 1, "Salary not given")
+	case candidate.ExpectedSalary.Currency != jobPost.Salary.Currency:
+		return newFactor(SalaryFactor, SalaryWeight, 0.5, "Salary in another currency")
+	case offered >= expected || expected == 0:
+		return newFactor(SalaryFactor, SalaryWeight, 1, "Expected salary offered")
+	default:
+		return newFactor(SalaryFactor, SalaryWeight, offered/expected, fmt.Sprintf("%.0f/%.0f %s expected per year", offered, expected, jobPost.Salary.Currency))
+	}
+}
+
+// accentPairs replace one character by another, the database normalizes the locations the same way
+var accentPairs = []string{
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"à", "a", "â", "a", "ä", "a",
+	"î", "i", "ï", "i",
+	"ô", "o", "ö", "o",
+	"ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "-", " ",
+}
+
+var accents = strings.NewReplacer(accentPairs...)
+
+func normalizeLocation(location string) string {
+	return strings.Join(strings.Fields(accents.Replace(strings.ToLower(location))), " ")
+}
+
+// city keeps the first part of a location such as "Paris, France"
+func city(location string) string {
+	return strings.TrimSpace(strings.Split(location, ",")[0])
+}
+
+func isRemote(location string) bool {
+	for _, remote := range remoteLocations {
+		if location == remote {
+			return true
+		}
+	}
+	return false
+}
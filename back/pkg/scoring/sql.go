@@ -0,0 +1,149 @@
+package scoring
+
+import (
+	"fmt"
+	"strings"
+
+	"skillly/pkg/geo"
+	"skillly/pkg/models"
+)
+
+// sqlExpr is a SQL expression and its arguments
+type sqlExpr struct {
+	sql  string
+	args []interface{}
+}
+
+func constant(value float64) sqlExpr {
+	return sqlExpr{sql: fmt.Sprint(value)}
+}
+
+// ScoreSQL is the score of Compute written on the columns of profile_candidates, so that the database
+// ranks and paginates the candidates for the job post. The skills and certifications of the job post must be loaded
+func ScoreSQL(jobPost models.JobPost) (string, []interface{}) {
+	skills := []uint{}
+	for _, skill := range jobPost.Skills {
+		skills = append(skills, skill.ID)
+	}
+	certifications := []uint{}
+	for _, certification := range jobPost.Certifications {
+		certifications = append(certifications, certification.ID)
+	}
+
+	return sumPoints(
+		points(coverageSQL("user_skills", "skill_id", skills), SkillsWeight),
+		points(coverageSQL("user_certifications", "certification_id", certifications), CertificationsWeight),
+		points(experienceSQL(jobPost), ExperienceWeight),
+		points(contractSQL(jobPost), ContractWeight),
+		points(locationSQL(jobPost), LocationWeight),
+		points(salarySQL(jobPost), SalaryWeight),
+	)
+}
+
+// points rounds the points of a factor like newFactor
+func points(ratio sqlExpr, weight float64) sqlExpr {
+	return sqlExpr{sql: fmt.Sprintf("ROUND((%s)::numeric * %v, 2)", ratio.sql, weight), args: ratio.args}
+}
+
+// sumPoints rounds the total of the points like Compute
+func sumPoints(factors ...sqlExpr) (string, []interface{}) {
+	sqls := []string{}
+	args := []interface{}{}
+	for _, factor := range factors {
+		sqls = append(sqls, factor.sql)
+		args = append(args, factor.args...)
+	}
+	return "ROUND(" + strings.Join(sqls, " + ") + ")", args
+}
+
+// coverageSQL is the share of the required IDs in the join table of the candidate, like coverage
+func coverageSQL(table string, column string, required []uint) sqlExpr {
+	if len(required) == 0 {
+		return constant(1)
+	}
+	return sqlExpr{
+		sql:  fmt.Sprintf("(SELECT COUNT(*) FROM %[1]s WHERE %[1]s.profile_candidate_id = profile_candidates.id AND %[1]s.%[2]s IN ?)::numeric / ?", table, column),
+		args: []interface{}{required, len(required)},
+	}
+}
+
+func experienceSQL(jobPost models.JobPost) sqlExpr {
+	if jobPost.ExperienceYear <= 0 {
+		return constant(1)
+	}
+	return sqlExpr{
+		sql:  "LEAST(1, GREATEST(0, profile_candidates.experience_year::numeric / ?))",
+		args: []interface{}{jobPost.ExperienceYear},
+	}
+}
+
+func contractSQL(jobPost models.JobPost) sqlExpr {
+	closeContracts := []string{}
+	for _, contract := range models.ContractTypes {
+		if models.AreContractsClose(contract, jobPost.Contract_type) {
+			closeContracts = append(closeContracts, string(contract))
+		}
+	}
+
+	expr := sqlExpr{
+		sql:  "CASE WHEN COALESCE(profile_candidates.prefered_contract, '') IN ('', ?) THEN 1",
+		args: []interface{}{string(jobPost.Contract_type)},
+	}
+	if len(closeContracts) > 0 {
+		expr.sql += " WHEN profile_candidates.prefered_contract IN ? THEN 0.5"
+		expr.args = append(expr.args, closeContracts)
+	}
+	expr.sql += " ELSE 0 END"
+	return expr
+}
+
+func locationSQL(jobPost models.JobPost) sqlExpr {
+	jobLocation := normalizeLocation(jobPost.Location)
+	if jobPost.WorkMode == models.RemoteWork || jobLocation == "" || isRemote(jobLocation) {
+		return constant(1)
+	}
+
+	expr := sqlExpr{sql: "CASE"}
+	if jobPost.WorkMode != models.HybridWork {
+		expr.sql += " WHEN profile_candidates.prefered_work_mode = ? THEN 0"
+		expr.args = append(expr.args, string(models.RemoteWork))
+	}
+	if jobPoint, ok := jobPost.Coordinates.Point(); ok {
+		expr.sql += fmt.Sprintf(" WHEN profile_candidates.latitude IS NOT NULL THEN LEAST(1, GREATEST(0, (%d - %s) / %d))",
+			FarDistance, geo.DistanceSQL("profile_candidates"), FarDistance-NearbyDistance)
+		expr.args = append(expr.args, jobPoint.DistanceArgs()...)
+	}
+
+	candidateLocation := normalizeLocationSQL("profile_candidates.location")
+	expr.sql += fmt.Sprintf(" WHEN %[1]s = '' THEN 0.5 WHEN btrim(split_part(%[1]s, ',', 1)) = ? THEN 1 ELSE 0 END", candidateLocation)
+	expr.args = append(expr.args, city(jobLocation))
+	return expr
+}
+
+// normalizeLocationSQL normalizes a location column like normalizeLocation
+func normalizeLocationSQL(column string) string {
+	from, to := "", ""
+	for i := 0; i < len(accentPairs); i += 2 {
+		from += accentPairs[i]
+		to += accentPairs[i+1]
+	}
+	return fmt.Sprintf(`btrim(regexp_replace(translate(lower(COALESCE(%s, '')), '%s', '%s'), '\s+', ' ', 'g'))`, column, from, to)
+}
+
+func salarySQL(jobPost models.JobPost) sqlExpr {
+	offered, hasOffer := jobPost.Salary.YearlyMax()
+	if !hasOffer {
+		return constant(1)
+	}
+
+	expected := fmt.Sprintf("COALESCE(profile_candidates.expected_salary_min, profile_candidates.expected_salary_max)::numeric * "+
+		"CASE profile_candidates.expected_salary_period WHEN '%s' THEN %d WHEN '%s' THEN %d ELSE 1 END",
+		models.HourlySalary, models.HoursPerYear, models.MonthlySalary, models.MonthsPerYear)
+	return sqlExpr{
+		sql: fmt.Sprintf("CASE WHEN profile_candidates.expected_salary_min IS NULL AND profile_candidates.expected_salary_max IS NULL THEN 1 "+
+			"WHEN COALESCE(profile_candidates.expected_salary_currency, '') <> ? THEN 0.5 "+
+			"WHEN %[1]s <= ? OR %[1]s = 0 THEN 1 "+
+			"ELSE ? / (%[1]s) END", expected),
+		args: []interface{}{jobPost.Salary.Currency, offered, offered},
+	}
+}
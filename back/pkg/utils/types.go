@@ -6,6 +6,13 @@ type ContractType string
 type CompanyRole string
 type RecruiterState string
 type ApplicationState string
+type TokenPurpose string
+type SwipeDecision string
+type InterviewState string
+type JobPostState string
+type SalaryPeriod string
+type SalaryBasis string
+type WorkMode string
 
 type QueryParams struct {
 	Page     int
@@ -2,9 +2,14 @@ package application__test
 
 import (
 	"testing"
+	"time"
 
 	"skillly/pkg/config"
+	"skillly/pkg/handlers/application"
 	applicationDto "skillly/pkg/handlers/application/dto"
+	"skillly/pkg/handlers/applicationState"
+	jobPostDto "skillly/pkg/handlers/jobPost/dto"
+	"skillly/pkg/models"
 	"skillly/pkg/utils"
 	testUtils "skillly/test/utils"
 
@@ -17,7 +22,6 @@ func CreateApplication(t *testing.T) {
 	newApplication := applicationDto.CreateApplicationDTO{
 		JobPostID:   1, // Assuming job post with ID 1 exists
 		CandidateID: 1, // Assuming candidate with ID 1 exists
-		Score:       85,
 	}
 
 	application, err := testUtils.ApplicationRepo.CreateApplication(newApplication, config.DB)
@@ -26,7 +30,10 @@ func CreateApplication(t *testing.T) {
 	assert.NotNil(t, application, "Expected application to be created")
 	assert.Equal(t, newApplication.JobPostID, application.JobPostID, "Expected job post ID to match")
 	assert.Equal(t, newApplication.CandidateID, application.CandidateID, "Expected candidate ID to match")
-	assert.Equal(t, newApplication.Score, application.Score, "Expected score to match")
+	// The score is computed by the server
+	assert.GreaterOrEqual(t, application.Score, 0, "Expected a score between 0 and 100")
+	assert.LessOrEqual(t, application.Score, 100, "Expected a score between 0 and 100")
+	assert.Len(t, application.ScoreFactors, 5, "Expected the contribution of each criterion")
 }
 
 func GetApplicationById(t *testing.T) {
@@ -75,3 +82,104 @@ func DeleteApplication(t *testing.T) {
 	_, err = testUtils.ApplicationRepo.GetByID(applications[0].ID, &params.Populate)
 	assert.Error(t, err, "Expected error when getting deleted application")
 }
+
+func GetApplicationUserIDs(t *testing.T) {
+	candidateUserID, recruiterUserIDs, err := testUtils.ApplicationRepo.GetApplicationUserIDs(1)
+	require.NoError(t, err, "Failed to get the users of the application")
+	assert.NotZero(t, candidateUserID, "Expected the candidate user ID")
+	assert.NotContains(t, recruiterUserIDs, candidateUserID, "Expected the candidate not to be a recruiter")
+
+	_, _, err = testUtils.ApplicationRepo.GetApplicationUserIDs(999999)
+	assert.Error(t, err, "Expected an error for an unknown application")
+}
+
+func ApplicationTransitions(t *testing.T) {
+	assert.True(t, models.CanTransitionApplication(models.PendingApplication, models.MatchedApplication))
+	assert.True(t, models.CanTransitionApplication(models.PendingApplication, models.RejectedApplication))
+	assert.True(t, models.CanTransitionApplication(models.MatchedApplication, models.ClosedApplication))
+	assert.True(t, models.CanTransitionApplication(models.PendingApplication, models.ClosedApplication))
+	assert.False(t, models.CanTransitionApplication(models.RejectedApplication, models.MatchedApplication))
+	assert.False(t, models.CanTransitionApplication(models.ClosedApplication, models.PendingApplication))
+}
+
+func ApplicationStateHistory(t *testing.T) {
+	jobPost, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "History Engineer",
+		Description:     "Job post of the state history.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+
+	_, err = testUtils.ApplicationStateRepo.Transition(application.ID, models.PendingApplication, nil, "", config.DB)
+	assert.ErrorIs(t, err, applicationState.ErrInvalidTransition, "Expected a pending application not to move to pending")
+
+	actorID := uint(1)
+	rejected, err := testUtils.ApplicationStateRepo.Transition(application.ID, models.RejectedApplication, &actorID, "Not enough experience", config.DB)
+	require.NoError(t, err, "Failed to reject the application")
+	assert.Equal(t, models.RejectedApplication, rejected.State)
+
+	_, err = testUtils.ApplicationStateRepo.Transition(application.ID, models.MatchedApplication, &actorID, "", config.DB)
+	assert.ErrorIs(t, err, applicationState.ErrInvalidTransition, "Expected a rejected application to stay rejected")
+
+	history, err := testUtils.ApplicationStateRepo.GetHistory(application.ID, config.DB)
+	require.NoError(t, err, "Failed to get the history")
+	require.Len(t, history, 1, "Expected the transitions only")
+	assert.Equal(t, models.PendingApplication, history[0].FromState)
+	assert.Equal(t, models.RejectedApplication, history[0].ToState)
+	assert.Equal(t, &actorID, history[0].ActorID)
+	assert.Equal(t, "Not enough experience", history[0].Reason)
+}
+
+func newOpenJobPost(t *testing.T, title string, expiration time.Time) models.JobPost {
+	jobPost, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           title,
+		Description:     "Job post of the application tests.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: expiration,
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+	return jobPost
+}
+
+func ApplyOnce(t *testing.T) {
+	jobPost := newOpenJobPost(t, "Apply Once Engineer", time.Now().AddDate(0, 1, 0))
+
+	_, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+
+	_, err = testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	assert.ErrorIs(t, err, application.ErrAlreadyApplied)
+
+	expired := newOpenJobPost(t, "Expired Engineer", time.Now().AddDate(0, 0, -1))
+	_, err = testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: expired.ID, CandidateID: 1}, config.DB)
+	assert.ErrorIs(t, err, application.ErrJobPostExpired)
+}
+
+func WithdrawMatchedApplication(t *testing.T) {
+	jobPost := newOpenJobPost(t, "Withdraw Engineer", time.Now().AddDate(0, 1, 0))
+	created, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+
+	actorID := uint(1)
+	match, err := testUtils.MatchRepo.CreateMatchFromApplication(created.ID, &actorID, config.DB)
+	require.NoError(t, err, "Failed to create the match")
+
+	closed, err := testUtils.ApplicationStateRepo.Transition(created.ID, models.ClosedApplication, &actorID, "Found another job", config.DB)
+	require.NoError(t, err, "Failed to withdraw the application")
+	assert.Equal(t, models.ClosedApplication, closed.State)
+
+	err = testUtils.MatchRepo.DeleteApplicationMatches(created.ID, config.DB)
+	require.NoError(t, err, "Failed to release the match")
+	_, err = testUtils.MatchRepo.GetByID(match.ID, nil)
+	assert.Error(t, err, "Expected the match to be released")
+}
@@ -0,0 +1,92 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	authDto "skillly/pkg/handlers/auth/dto"
+	testUtils "skillly/test/utils"
+)
+
+var tokenRegex = regexp.MustCompile(`token=([a-f0-9]+)`)
+
+// post calls a service method with a JSON body
+func post(t *testing.T, handler func(c *gin.Context), body interface{}) *httptest.ResponseRecorder {
+	jsonData, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", "/auth", bytes.NewBuffer(jsonData))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler(c)
+	return w
+}
+
+// lastMailToken returns the token of the last mail sent to an address
+func lastMailToken(t *testing.T, email string) string {
+	mail, ok := testUtils.Mailer.LastTo(email)
+	require.True(t, ok, "Expected a mail to be sent")
+
+	match := tokenRegex.FindStringSubmatch(mail.Body)
+	require.Len(t, match, 2, "Expected the mail to contain a token")
+	return match[1]
+}
+
+func VerifyEmail(t *testing.T) {
+	// A verification mail is sent at registration
+	token := lastMailToken(t, testUtils.TestCandidate.Email)
+
+	w := post(t, authService.VerifyEmail, authDto.VerifyEmailDTO{Token: token})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	user, err := testUtils.UserRepo.GetByEmail(testUtils.TestCandidate.Email)
+	require.NoError(t, err)
+	assert.NotNil(t, user.EmailVerifiedAt, "Expected the email to be verified")
+
+	// The token is single-use
+	w = post(t, authService.VerifyEmail, authDto.VerifyEmailDTO{Token: token})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func ResetPassword(t *testing.T) {
+	w := post(t, authService.ForgotPassword, authDto.ForgotPasswordDTO{Email: testUtils.TestLogin.Email})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	token := lastMailToken(t, testUtils.TestLogin.Email)
+	_, refreshToken := login(t)
+
+	// The new password must follow the password policy
+	w = post(t, authService.ResetPassword, authDto.ResetPasswordDTO{Token: token, Password: "weak"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post(t, authService.ResetPassword, authDto.ResetPasswordDTO{Token: token, Password: testUtils.TestLogin.Password})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The sessions opened before the reset are revoked
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, refreshToken).Code)
+
+	// The token is single-use
+	w = post(t, authService.ResetPassword, authDto.ResetPasswordDTO{Token: token, Password: testUtils.TestLogin.Password})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func ForgotPasswordUnknownEmail(t *testing.T) {
+	w := post(t, authService.ForgotPassword, authDto.ForgotPasswordDTO{Email: "unknown@test.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, ok := testUtils.Mailer.LastTo("unknown@test.com")
+	assert.False(t, ok, "Expected no mail for an unknown email")
+}
@@ -10,7 +10,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"skillly/pkg/config"
 	"skillly/pkg/handlers/auth"
+	authDto "skillly/pkg/handlers/auth/dto"
+	recruiter "skillly/pkg/handlers/recruiterProfile"
+	"skillly/pkg/middleware"
 	"skillly/pkg/models"
 	testUtils "skillly/test/utils"
 
@@ -58,6 +62,7 @@ func RegisterCandidate(t *testing.T) {
 
 	token := response["token"].(string)
 	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response["refreshToken"])
 }
 
 func RegisterRecruiter(t *testing.T) {
@@ -96,8 +101,84 @@ func RegisterRecruiter(t *testing.T) {
 	assert.Equal(t, testUtils.TestRecruiter.LastName, user["last_name"])
 	assert.Equal(t, string(models.RoleRecruiter), user["role"])
 
+	// The recruiter creating the company is its admin
+	profile := user["profile_recruiter"].(map[string]interface{})
+	assert.Equal(t, string(models.AdminRole), profile["role"])
+	assert.Equal(t, string(models.ActiveState), profile["state"])
+
 	token := response["token"].(string)
 	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response["refreshToken"])
+}
+
+func RecruiterJoinRequest(t *testing.T) {
+	jsonData, err := json.Marshal(authDto.RecruterRegisterDTO{
+		FirstName: "Joining",
+		LastName:  "Recruiter",
+		Email:     "JoiningRecruiter@test.com",
+		Password:  "Password123!",
+		Title:     "Talent Acquisition",
+		Company:   1,
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", "/auth/register/recruiter", bytes.NewBuffer(jsonData))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	authService.RegisterRecruiter(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		User  models.User `json:"user"`
+		Token string      `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.User.ProfileRecruiter)
+	profile := *response.User.ProfileRecruiter
+	assert.Equal(t, models.PendingState, profile.State, "Expected the join request to wait for an admin")
+	assert.Equal(t, models.RecruiterRole, profile.Role)
+
+	// The recruiter routes are blocked until an admin approves the request
+	r := gin.Default()
+	r.GET("/test", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"company_id": c.Keys["company_id"]})
+	})
+	request := func() int {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+response.Token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, request())
+
+	// Only the admins of the company answer the request
+	_, err = testUtils.RecruiterRepo.AnswerJoinRequest(profile.ID, profile.CompanyID+1, models.ActiveState, config.DB)
+	assert.Error(t, err)
+
+	rejected, err := testUtils.RecruiterRepo.AnswerJoinRequest(profile.ID, profile.CompanyID, models.RejectedState, config.DB)
+	require.NoError(t, err, "Failed to reject the request")
+	assert.Equal(t, models.RejectedState, rejected.State)
+	assert.Equal(t, http.StatusForbidden, request())
+
+	// A rejected request can still be approved, then the answer is final
+	approved, err := testUtils.RecruiterRepo.AnswerJoinRequest(profile.ID, profile.CompanyID, models.ActiveState, config.DB)
+	require.NoError(t, err, "Failed to approve the request")
+	assert.Equal(t, models.ActiveState, approved.State)
+	assert.Equal(t, http.StatusOK, request(), "Expected the approval to apply without a new token")
+
+	_, err = testUtils.RecruiterRepo.AnswerJoinRequest(profile.ID, profile.CompanyID, models.RejectedState, config.DB)
+	assert.ErrorIs(t, err, recruiter.ErrRequestAnswered)
+
+	pending, err := testUtils.RecruiterRepo.GetCompanyRecruiters(profile.CompanyID, models.PendingState)
+	require.NoError(t, err)
+	for _, recruiter := range pending {
+		assert.NotEqual(t, profile.ID, recruiter.ID)
+	}
 }
 
 func Login(t *testing.T) {
@@ -138,4 +219,5 @@ func Login(t *testing.T) {
 
 	token := response["token"].(string)
 	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response["refreshToken"])
 }
@@ -0,0 +1,146 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/pkg/handlers/auth"
+	authDto "skillly/pkg/handlers/auth/dto"
+	"skillly/pkg/handlers/session"
+	"skillly/pkg/middleware"
+	testUtils "skillly/test/utils"
+)
+
+// login logs the test candidate in and returns the access and refresh tokens
+func login(t *testing.T) (string, string) {
+	jsonData, err := json.Marshal(testUtils.TestLogin)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	authService.Login(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	return response["token"].(string), response["refreshToken"].(string)
+}
+
+func refresh(t *testing.T, refreshToken string) *httptest.ResponseRecorder {
+	jsonData, err := json.Marshal(authDto.RefreshTokenDTO{RefreshToken: refreshToken})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(jsonData))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	authService.RefreshToken(c)
+	return w
+}
+
+func RefreshToken(t *testing.T) {
+	_, refreshToken := login(t)
+
+	w := refresh(t, refreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, response["token"])
+	assert.NotEmpty(t, response["refreshToken"])
+	assert.NotEqual(t, refreshToken, response["refreshToken"], "Expected the refresh token to be rotated")
+
+	// The previous refresh token can't be reused
+	w = refresh(t, refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func RefreshTokenReuse(t *testing.T) {
+	_, refreshToken := login(t)
+
+	// Two refreshes read the session with the same token
+	first, err := testUtils.SessionRepo.GetByRefreshToken(refreshToken)
+	require.NoError(t, err)
+	second, err := testUtils.SessionRepo.GetByRefreshToken(refreshToken)
+	require.NoError(t, err)
+
+	_, err = testUtils.SessionRepo.RotateRefreshToken(&first)
+	require.NoError(t, err, "Failed to rotate the refresh token")
+	_, err = testUtils.SessionRepo.RotateRefreshToken(&second)
+	assert.ErrorIs(t, err, session.ErrRefreshTokenReused, "Expected the token to be rotated once")
+}
+
+func RefreshTokenReplay(t *testing.T) {
+	_, refreshToken := login(t)
+
+	w := refresh(t, refreshToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	rotatedToken := response["refreshToken"].(string)
+
+	// The retired token is replayed later, the session is revoked with the token it was rotated to
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, refreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, rotatedToken).Code, "Expected the rotated token to be revoked")
+}
+
+func Logout(t *testing.T) {
+	token, refreshToken := login(t)
+
+	_, err := middleware.ParseToken(token)
+	require.NoError(t, err)
+
+	r := gin.Default()
+	auth.AddRoutes(r)
+
+	req, _ := http.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Both tokens of the session are revoked
+	_, err = middleware.ParseToken(token)
+	assert.ErrorIs(t, err, middleware.ErrSessionRevoked)
+	assert.Equal(t, http.StatusUnauthorized, refresh(t, refreshToken).Code)
+}
+
+func LogoutAll(t *testing.T) {
+	firstToken, _ := login(t)
+	secondToken, _ := login(t)
+
+	r := gin.Default()
+	auth.AddRoutes(r)
+
+	req, _ := http.NewRequest("POST", "/auth/logout/all", nil)
+	req.Header.Set("Authorization", "Bearer "+firstToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, err := middleware.ParseToken(secondToken)
+	assert.ErrorIs(t, err, middleware.ErrSessionRevoked)
+}
@@ -0,0 +1,136 @@
+package candidate_test
+
+import (
+	"testing"
+
+	"skillly/pkg/config"
+	"skillly/pkg/geo"
+	candidateDto "skillly/pkg/handlers/candidateProfile/dto"
+	userDto "skillly/pkg/handlers/user/dto"
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+	testUtils "skillly/test/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func found(page models.CandidateSearchPage, candidateID uint) bool {
+	for _, candidate := range page.Candidates {
+		if candidate.ID == candidateID {
+			return true
+		}
+	}
+	return false
+}
+
+func SearchCandidates(t *testing.T) {
+	page, err := testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{Limit: candidateDto.MaxSearchLimit}, nil, config.DB)
+	require.NoError(t, err, "Failed to search candidates")
+	require.False(t, found(page, 1), "Expected the candidate to be hidden until opting in")
+
+	require.NoError(t, testUtils.CandidateRepo.SetDiscoverable(1, true, config.DB), "Failed to show the candidate")
+	page, err = testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{Limit: candidateDto.MaxSearchLimit}, nil, config.DB)
+	require.NoError(t, err, "Failed to search candidates")
+	require.True(t, found(page, 1), "Expected the candidate to be discoverable")
+	assert.Nil(t, page.Candidates[0].Score, "Expected no score without job post")
+
+	// The pages are cut by the database, the total counts every candidate found
+	second, err := testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{Limit: 1, Page: 2}, nil, config.DB)
+	require.NoError(t, err, "Failed to search candidates")
+	assert.Equal(t, page.Total, second.Total)
+	assert.LessOrEqual(t, len(second.Candidates), 1)
+	if len(page.Candidates) > 1 {
+		require.Len(t, second.Candidates, 1)
+		assert.Equal(t, page.Candidates[1].ID, second.Candidates[0].ID)
+	}
+
+	// Every selected skill is required by default
+	page, err = testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{Skills: []uint{999999}}, nil, config.DB)
+	require.NoError(t, err, "Failed to search candidates")
+	assert.Empty(t, page.Candidates, "Expected no candidate with an unknown skill")
+
+	jobPost := models.JobPost{}
+	require.NoError(t, config.DB.Preload("Skills").Preload("Certifications").First(&jobPost).Error)
+	page, err = testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{Limit: candidateDto.MaxSearchLimit}, &jobPost, config.DB)
+	require.NoError(t, err, "Failed to search candidates for a job post")
+	for i, candidate := range page.Candidates {
+		require.NotNil(t, candidate.Score, "Expected the score for the job post")
+		if i > 0 {
+			assert.LessOrEqual(t, *candidate.Score, *page.Candidates[i-1].Score, "Expected the best fits first")
+		}
+	}
+}
+
+func CandidateOptOut(t *testing.T) {
+	err := testUtils.CandidateRepo.SetDiscoverable(1, false, config.DB)
+	require.NoError(t, err, "Failed to hide the candidate")
+
+	page, err := testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{Limit: candidateDto.MaxSearchLimit}, nil, config.DB)
+	require.NoError(t, err, "Failed to search candidates")
+	assert.False(t, found(page, 1), "Expected the candidate to be hidden")
+
+	err = testUtils.CandidateRepo.SetDiscoverable(1, true, config.DB)
+	require.NoError(t, err, "Failed to show the candidate")
+}
+
+func SearchCandidatesNearby(t *testing.T) {
+	create := func(email string, location string, workMode utils.WorkMode) models.ProfileCandidate {
+		user, err := testUtils.UserRepo.CreateUser(userDto.CreateUserDTO{
+			FirstName: "Camille",
+			LastName:  "Martin",
+			Email:     email,
+			Password:  "Password123!",
+			Role:      models.RoleCandidate,
+		}, config.DB)
+		require.NoError(t, err, "Failed to create user")
+
+		candidate, err := testUtils.CandidateRepo.CreateCandidate(candidateDto.CreateCandidateDTO{
+			Location:         location,
+			PreferedWorkMode: workMode,
+			User:             user,
+		}, config.DB)
+		require.NoError(t, err, "Failed to create candidate")
+		require.NoError(t, testUtils.CandidateRepo.SetDiscoverable(candidate.ID, true, config.DB), "Failed to show the candidate")
+		return candidate
+	}
+	lyon := create("camille.lyon@example.com", "Lyon 7e", "")
+	require.NotNil(t, lyon.Coordinates.Latitude, "Expected the location to be geocoded")
+	remote := create("camille.villeurbanne@example.com", "Villeurbanne", models.RemoteWork)
+	paris := create("camille.paris@example.com", "Paris", models.OnSiteWork)
+	farRemote := create("camille.brest@example.com", "Brest", models.RemoteWork)
+
+	search := func(dto candidateDto.SearchCandidatesDTO, jobPost *models.JobPost) models.CandidateSearchPage {
+		dto.Limit = candidateDto.MaxSearchLimit
+		page, err := testUtils.CandidateRepo.SearchCandidates(dto, jobPost, config.DB)
+		require.NoError(t, err, "Failed to search candidates")
+		return page
+	}
+
+	page := search(candidateDto.SearchCandidatesDTO{Near: "Lyon", RadiusKm: 30}, nil)
+	assert.True(t, found(page, lyon.ID))
+	assert.True(t, found(page, remote.ID))
+	assert.False(t, found(page, paris.ID), "Expected the candidates outside the radius to be hidden")
+	assert.True(t, found(page, farRemote.ID), "Expected the candidates preferring remote work in every radius")
+	for _, candidate := range page.Candidates {
+		if candidate.PreferedWorkMode == models.RemoteWork {
+			continue
+		}
+		require.NotNil(t, candidate.Distance, "Expected the distance to the center")
+		assert.LessOrEqual(t, *candidate.Distance, 30.0)
+	}
+
+	// The candidates without preference accept every work mode
+	page = search(candidateDto.SearchCandidatesDTO{Near: "Lyon", RadiusKm: 30, WorkModes: []utils.WorkMode{models.OnSiteWork}}, nil)
+	assert.True(t, found(page, lyon.ID))
+	assert.False(t, found(page, remote.ID))
+
+	// Without center, the radius is searched around the job post
+	jobPost := models.JobPost{Location: "Paris", Coordinates: models.Locate("Paris")}
+	page = search(candidateDto.SearchCandidatesDTO{RadiusKm: 30}, &jobPost)
+	assert.True(t, found(page, paris.ID))
+	assert.False(t, found(page, lyon.ID))
+
+	_, err := testUtils.CandidateRepo.SearchCandidates(candidateDto.SearchCandidatesDTO{RadiusKm: 30}, nil, config.DB)
+	assert.ErrorIs(t, err, geo.ErrMissingCenter)
+}
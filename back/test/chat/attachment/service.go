@@ -0,0 +1,103 @@
+package attachment_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/chat/handlers/attachment"
+	"skillly/chat/handlers/message"
+	messageDto "skillly/chat/handlers/message/dto"
+	"skillly/chat/models"
+)
+
+const attachmentRoom = "attachment_room"
+
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+
+// fileHeader builds the header of an uploaded file as gin would read it from a multipart form
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["file"][0]
+}
+
+func UploadAttachment(t *testing.T) {
+	service := attachment.NewAttachmentService()
+
+	uploaded, err := service.Upload(attachmentRoom, "1", fileHeader(t, "contract.pdf", pdfContent))
+	require.NoError(t, err, "Failed to upload attachment")
+	assert.Equal(t, "contract.pdf", uploaded.FileName)
+	assert.Equal(t, "application/pdf", uploaded.MimeType)
+	assert.Equal(t, int64(len(pdfContent)), uploaded.Size)
+	assert.Nil(t, uploaded.MessageID, "Expected the attachment to be pending")
+
+	// A pending attachment is only visible to its uploader
+	_, _, err = service.Open(attachmentRoom, "2", uploaded.ID.Hex())
+	assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+
+	_, content, err := service.Open(attachmentRoom, "1", uploaded.ID.Hex())
+	require.NoError(t, err, "Failed to open attachment")
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, pdfContent, data)
+}
+
+func UploadAttachmentNotAllowed(t *testing.T) {
+	service := attachment.NewAttachmentService()
+
+	_, err := service.Upload(attachmentRoom, "1", fileHeader(t, "script.sh", []byte("#!/bin/sh\necho hello\n")))
+	assert.ErrorIs(t, err, attachment.ErrFileTypeNotAllowed)
+
+	// The content must match the extension
+	_, err = service.Upload(attachmentRoom, "1", fileHeader(t, "cv.pdf", []byte("<html><script>alert(1)</script></html>")))
+	assert.ErrorIs(t, err, attachment.ErrFileTypeNotAllowed)
+}
+
+func SendAttachment(t *testing.T) {
+	service := attachment.NewAttachmentService()
+	messageService := message.NewMessageService()
+
+	uploaded, err := service.Upload(attachmentRoom, "1", fileHeader(t, "portfolio.pdf", pdfContent))
+	require.NoError(t, err, "Failed to upload attachment")
+
+	// Only the uploader can send the attachment
+	_, err = messageService.CreateMessage(messageDto.CreateMessageDTO{Room: attachmentRoom, SenderID: "2", Attachments: []string{uploaded.ID.Hex()}})
+	assert.ErrorIs(t, err, models.ErrInvalidAttachments)
+
+	created, err := messageService.CreateMessage(messageDto.CreateMessageDTO{Room: attachmentRoom, SenderID: "1", Attachments: []string{uploaded.ID.Hex()}})
+	require.NoError(t, err, "Failed to send attachment")
+	require.Len(t, created.Attachments, 1)
+	assert.Equal(t, uploaded.ID, created.Attachments[0].ID)
+
+	// An attachment is sent once
+	_, err = messageService.CreateMessage(messageDto.CreateMessageDTO{Room: attachmentRoom, SenderID: "1", Attachments: []string{uploaded.ID.Hex()}})
+	assert.ErrorIs(t, err, models.ErrInvalidAttachments)
+
+	// Once sent, the members of the room can download it
+	_, content, err := service.Open(attachmentRoom, "2", uploaded.ID.Hex())
+	require.NoError(t, err, "Failed to open sent attachment")
+	content.Close()
+
+	_, _, err = service.Open("other_room", "2", uploaded.ID.Hex())
+	assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+
+	// Deleting the message deletes its attachments
+	_, err = messageService.DeleteMessage(attachmentRoom, created.ID.Hex(), "1")
+	require.NoError(t, err, "Failed to delete message")
+	_, _, err = service.Open(attachmentRoom, "2", uploaded.ID.Hex())
+	assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+}
@@ -0,0 +1,173 @@
+package backplane_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/chat/backplane"
+	"skillly/chat/broadcast"
+	"skillly/chat/models"
+	"skillly/pkg/config"
+)
+
+const receiveTimeout = time.Second
+
+func receive(t *testing.T, frames chan []byte) []byte {
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(receiveTimeout):
+		require.FailNow(t, "Expected a frame")
+		return nil
+	}
+}
+
+func assertNothingReceived(t *testing.T, frames chan []byte) {
+	select {
+	case frame := <-frames:
+		assert.Failf(t, "Expected no frame", "received %s", frame)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// testClient is a client without socket, the frames stay in its send channel
+func testClient(id string, connID string) *models.Client {
+	return &models.Client{
+		Id:     id,
+		ConnID: connID,
+		Rooms:  map[string]*models.Room{},
+		Send:   make(chan []byte, 16),
+	}
+}
+
+// receiveType returns the next frame of a type, the other frames are skipped
+func receiveType(t *testing.T, frames chan []byte, eventType models.EventType) []byte {
+	for {
+		frame := receive(t, frames)
+		envelope := models.Envelope{}
+		require.NoError(t, json.Unmarshal(frame, &envelope))
+		if envelope.Type == eventType {
+			return frame
+		}
+	}
+}
+
+func PublishAcrossHubs(t *testing.T) {
+	bp := backplane.NewMemoryBackplane()
+
+	// Two instances of the backend sharing the same backplane
+	first := models.NewHubWithBackplane(bp)
+	second := models.NewHubWithBackplane(bp)
+	go first.RunHub()
+	go second.RunHub()
+
+	alice := testClient("1", "alice-phone")
+	bob := testClient("2", "bob-laptop")
+	// The hubs take the clients once they are subscribed to the backplane
+	first.Register <- alice
+	second.Register <- bob
+
+	// Both rooms exist before the clients join, so that each presence reaches the other instance
+	firstRoom := first.JoinRoom("shared")
+	secondRoom := second.JoinRoom("shared")
+	firstRoom.Register <- alice
+	secondRoom.Register <- bob
+
+	// Alice is told that Bob joined from the other instance
+	presence := models.Envelope{}
+	require.NoError(t, json.Unmarshal(receive(t, alice.Send), &presence))
+	assert.Equal(t, models.PresenceEvent, presence.Type)
+	assert.Equal(t, "2", presence.SenderID)
+
+	frame, err := models.NewEnvelope(models.TypingEvent, "", "shared", "1", models.TypingPayload{Typing: true})
+	require.NoError(t, err)
+	first.Publish(models.Frame{Room: "shared", Data: frame, Except: alice.ConnID})
+
+	// Bob may have been told that Alice joined before
+	assert.JSONEq(t, string(frame), string(receiveType(t, bob.Send, models.TypingEvent)))
+	assertNothingReceived(t, alice.Send)
+}
+
+// PublishLargeEvent sends an event too large for a NOTIFY through the Postgres backplane
+func PublishLargeEvent(t *testing.T) {
+	bp := backplane.NewPostgresBackplane(config.DB)
+	events := make(chan backplane.Event, 16)
+	stop := bp.Subscribe(func(event backplane.Event) { events <- event })
+	defer stop()
+
+	data, err := json.Marshal(strings.Repeat("a", 10000))
+	require.NoError(t, err)
+	event := backplane.Event{Topic: backplane.RoomTopic("large"), Data: data}
+
+	// The subscription starts listening in the background, the event is published until it is received
+	timeout := time.After(5 * time.Second)
+	for {
+		require.NoError(t, bp.Publish(event), "Expected the large event to be published")
+		select {
+		case received := <-events:
+			assert.Equal(t, event.Topic, received.Topic)
+			assert.JSONEq(t, string(data), string(received.Data))
+			return
+		case <-time.After(200 * time.Millisecond):
+		case <-timeout:
+			require.FailNow(t, "Expected the large event to be received")
+		}
+	}
+}
+
+func BroadcastToUserAcrossInstances(t *testing.T) {
+	stop := broadcast.Listen(backplane.Default)
+	defer stop()
+
+	messages := make(chan []byte, 16)
+	broadcast.RegisterUser("backplane_user", messages)
+	defer broadcast.UnregisterUser("backplane_user")
+
+	broadcast.BroadcastToUser("backplane_user", []byte(`{"type":"new_message"}`))
+	assert.JSONEq(t, `{"type":"new_message"}`, string(receive(t, messages)))
+
+	// The excluded user is not sent the message
+	broadcast.BroadcastToAllUsersExcept("backplane_user", []byte(`{"type":"new_message"}`))
+	assertNothingReceived(t, messages)
+}
+
+func DisconnectSlowClient(t *testing.T) {
+	// The hub is not subscribed to the backplane, only the frames broadcast by the test reach the room
+	room := models.NewHubWithBackplane(backplane.NewMemoryBackplane()).JoinRoom("slow")
+
+	// A client that never reads, its buffer is full after the first frame
+	slow := testClient("1", "slow-phone")
+	slow.Send = make(chan []byte, 1)
+	room.Register <- slow
+	// The newcomer is told that the slow client is there without blocking the room
+	fast := testClient("2", "fast-laptop")
+	room.Register <- fast
+	receive(t, fast.Send)
+
+	// The first frame fills the buffer of the slow client, the second one disconnects it
+	for i := 0; i < 2; i++ {
+		frame, err := models.NewEnvelope(models.TypingEvent, "", "slow", "2", models.TypingPayload{Typing: true})
+		require.NoError(t, err)
+		room.Broadcast <- models.Frame{Room: "slow", Data: frame}
+		receive(t, fast.Send)
+	}
+
+	// The slow client is dropped and its channel is closed
+	timeout := time.After(receiveTimeout)
+	for closed := false; !closed; {
+		select {
+		case _, ok := <-slow.Send:
+			closed = !ok
+		case <-timeout:
+			require.FailNow(t, "Expected the slow client to be disconnected")
+		}
+	}
+
+	// Leaving the room afterwards doesn't close the channel twice
+	room.Unregister <- slow
+}
@@ -1,13 +1,16 @@
 package message_test
 
 import (
+	"skillly/chat/handlers/message"
 	messageDto "skillly/chat/handlers/message/dto"
+	"skillly/chat/models"
 	"skillly/pkg/utils"
 	testUtils "skillly/test/utils"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 func CreateMessage(t *testing.T) {
@@ -55,3 +58,105 @@ func DeleteMessage(t *testing.T) {
 	err = testUtils.MessageRepo.Delete(message[0].ID.String())
 	require.NoError(t, err, "Failed to delete message")
 }
+
+func GetMessagesPage(t *testing.T) {
+	room := "page_room"
+	ids := []string{}
+	for _, content := range []string{"One", "Two", "Three", "Four", "Five"} {
+		message, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: room, SenderID: "1", Content: content})
+		require.NoError(t, err, "Failed to create message")
+		ids = append(ids, message.ID.Hex())
+	}
+
+	// Without cursor, the latest messages come from the oldest to the newest
+	page, err := testUtils.MessageRepo.GetPage(room, messageDto.MessagePageQuery{Limit: 2})
+	require.NoError(t, err, "Failed to get messages page")
+	require.Len(t, page.Messages, 2)
+	assert.Equal(t, "Four", page.Messages[0].Content)
+	assert.Equal(t, "Five", page.Messages[1].Content)
+	assert.Equal(t, ids[3], page.NextCursor)
+
+	page, err = testUtils.MessageRepo.GetPage(room, messageDto.MessagePageQuery{Before: page.NextCursor, Limit: 2})
+	require.NoError(t, err, "Failed to get previous page")
+	require.Len(t, page.Messages, 2)
+	assert.Equal(t, "Two", page.Messages[0].Content)
+	assert.Equal(t, ids[1], page.NextCursor)
+
+	page, err = testUtils.MessageRepo.GetPage(room, messageDto.MessagePageQuery{Before: page.NextCursor, Limit: 2})
+	require.NoError(t, err, "Failed to get last page")
+	require.Len(t, page.Messages, 1)
+	assert.Equal(t, "One", page.Messages[0].Content)
+	assert.Empty(t, page.NextCursor, "Expected no more pages")
+
+	page, err = testUtils.MessageRepo.GetPage(room, messageDto.MessagePageQuery{After: ids[2], Limit: 5})
+	require.NoError(t, err, "Failed to get next messages")
+	require.Len(t, page.Messages, 2)
+	assert.Equal(t, "Four", page.Messages[0].Content)
+	assert.Empty(t, page.NextCursor, "Expected no more pages")
+}
+
+func GetMessagesPageInvalidCursor(t *testing.T) {
+	_, err := testUtils.MessageRepo.GetPage("page_room", messageDto.MessagePageQuery{Before: "invalid"})
+	assert.ErrorIs(t, err, message.ErrInvalidCursor)
+
+	_, err = testUtils.MessageRepo.GetPage("other_room", messageDto.MessagePageQuery{Before: bson.NewObjectID().Hex()})
+	assert.ErrorIs(t, err, message.ErrInvalidCursor)
+}
+
+func EditMessage(t *testing.T) {
+	created, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: "edit_room", SenderID: "1", Content: "Helo"})
+	require.NoError(t, err, "Failed to create message")
+
+	edited, err := testUtils.MessageRepo.EditMessage("edit_room", created.ID.Hex(), "1", "Hello")
+	require.NoError(t, err, "Failed to edit message")
+	assert.Equal(t, "Hello", edited.Content)
+	assert.NotNil(t, edited.EditedAt, "Expected edited_at to be set")
+
+	// Only the sender can edit the message
+	_, err = testUtils.MessageRepo.EditMessage("edit_room", created.ID.Hex(), "2", "Hacked")
+	assert.ErrorIs(t, err, models.ErrNotMessageSender)
+
+	_, err = testUtils.MessageRepo.EditMessage("other_room", created.ID.Hex(), "1", "Hello")
+	assert.ErrorIs(t, err, models.ErrMessageNotFound)
+}
+
+func DeleteMessageTombstone(t *testing.T) {
+	created, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: "edit_room", SenderID: "1", Content: "Oops"})
+	require.NoError(t, err, "Failed to create message")
+
+	_, err = testUtils.MessageRepo.DeleteMessage("edit_room", created.ID.Hex(), "2")
+	assert.ErrorIs(t, err, models.ErrNotMessageSender)
+
+	deleted, err := testUtils.MessageRepo.DeleteMessage("edit_room", created.ID.Hex(), "1")
+	require.NoError(t, err, "Failed to delete message")
+	assert.NotNil(t, deleted.DeletedAt, "Expected deleted_at to be set")
+	assert.Empty(t, deleted.Content, "Expected the content to be cleared")
+
+	// The tombstone stays in the history
+	page, err := testUtils.MessageRepo.GetPage("edit_room", messageDto.MessagePageQuery{})
+	require.NoError(t, err, "Failed to get history")
+	require.NotEmpty(t, page.Messages)
+	tombstone := page.Messages[len(page.Messages)-1]
+	assert.Equal(t, created.ID, tombstone.ID)
+	assert.NotNil(t, tombstone.DeletedAt)
+
+	_, err = testUtils.MessageRepo.EditMessage("edit_room", created.ID.Hex(), "1", "Back")
+	assert.ErrorIs(t, err, models.ErrMessageDeleted)
+}
+
+func ReactToMessage(t *testing.T) {
+	created, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: "edit_room", SenderID: "1", Content: "Great news"})
+	require.NoError(t, err, "Failed to create message")
+
+	_, err = testUtils.MessageRepo.SetReaction("edit_room", created.ID.Hex(), "2", "👍", true)
+	require.NoError(t, err, "Failed to add reaction")
+
+	// Adding the same reaction twice keeps a single one
+	reacted, err := testUtils.MessageRepo.SetReaction("edit_room", created.ID.Hex(), "2", "👍", true)
+	require.NoError(t, err, "Failed to add reaction")
+	assert.Equal(t, []models.Reaction{{Emoji: "👍", UserID: "2"}}, reacted.Reactions)
+
+	reacted, err = testUtils.MessageRepo.SetReaction("edit_room", created.ID.Hex(), "2", "👍", false)
+	require.NoError(t, err, "Failed to remove reaction")
+	assert.Empty(t, reacted.Reactions)
+}
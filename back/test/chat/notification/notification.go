@@ -0,0 +1,57 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/chat/backplane"
+	"skillly/chat/broadcast"
+	"skillly/chat/notification"
+)
+
+func SendToParticipantsOnly(t *testing.T) {
+	stop := broadcast.Listen(backplane.Default)
+	defer stop()
+
+	sender := make(chan []byte, 16)
+	participant := make(chan []byte, 16)
+	outsider := make(chan []byte, 16)
+	broadcast.RegisterUser("901", sender)
+	broadcast.RegisterUser("902", participant)
+	broadcast.RegisterUser("903", outsider)
+	defer broadcast.UnregisterUser("901")
+	defer broadcast.UnregisterUser("902")
+	defer broadcast.UnregisterUser("903")
+
+	notification.Send([]uint{901, 902}, notification.Event{
+		Type:     notification.NewMessageEvent,
+		SenderID: "901",
+		RoomID:   "1",
+		Content:  "Hello",
+	})
+
+	select {
+	case data := <-participant:
+		event := notification.Event{}
+		require.NoError(t, json.Unmarshal(data, &event))
+		assert.Equal(t, notification.NewMessageEvent, event.Type)
+		assert.Equal(t, "1", event.RoomID)
+		assert.Equal(t, "Hello", event.Content)
+		assert.False(t, event.Timestamp.IsZero(), "Expected the timestamp to be set")
+	case <-time.After(time.Second):
+		require.FailNow(t, "Expected the participant to be notified")
+	}
+
+	// Neither the sender nor the users outside of the match are notified
+	select {
+	case data := <-sender:
+		assert.Failf(t, "Expected the sender not to be notified", "received %s", data)
+	case data := <-outsider:
+		assert.Failf(t, "Expected the outsider not to be notified", "received %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
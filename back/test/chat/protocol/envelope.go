@@ -0,0 +1,51 @@
+package protocol_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/chat/models"
+)
+
+func DecodeEnvelope(t *testing.T) {
+	envelope, protocolErr := models.DecodeEnvelope([]byte(`{"v":1,"type":"message","id":"c-1","payload":{"content":"Hello"}}`))
+	require.Nil(t, protocolErr)
+	assert.Equal(t, models.MessageEvent, envelope.Type)
+	assert.Equal(t, "c-1", envelope.ID)
+
+	payload, err := models.DecodePayload[models.MessagePayload](envelope)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", payload.Content)
+}
+
+func DecodeInvalidEnvelope(t *testing.T) {
+	cases := map[string]string{
+		"not json":                 models.InvalidJSONError,
+		`{"v":2,"type":"message"}`: models.UnsupportedVersionError,
+		`{"v":1,"type":"ack"}`:     models.UnknownTypeError,
+		`{"v":1,"type":"unknown"}`: models.UnknownTypeError,
+	}
+
+	for frame, code := range cases {
+		_, protocolErr := models.DecodeEnvelope([]byte(frame))
+		require.NotNil(t, protocolErr, "Expected an error for %s", frame)
+		assert.Equal(t, code, protocolErr.Code)
+	}
+}
+
+func ErrorFrame(t *testing.T) {
+	frame := models.NewErrorFrame("c-1", models.InvalidPayloadError, "A message needs a content")
+
+	envelope := models.Envelope{}
+	require.NoError(t, json.Unmarshal(frame, &envelope))
+	assert.Equal(t, models.ProtocolVersion, envelope.Version)
+	assert.Equal(t, models.ErrorEvent, envelope.Type)
+	assert.Equal(t, "c-1", envelope.ID)
+
+	payload, err := models.DecodePayload[models.ErrorPayload](envelope)
+	require.NoError(t, err)
+	assert.Equal(t, models.InvalidPayloadError, payload.Code)
+}
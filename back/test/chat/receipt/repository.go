@@ -0,0 +1,120 @@
+package receipt_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	messageDto "skillly/chat/handlers/message/dto"
+	"skillly/chat/handlers/receipt"
+	"skillly/chat/models"
+	testUtils "skillly/test/utils"
+)
+
+const receiptRoom = "receipt_room"
+
+func MarkRead(t *testing.T) {
+	first, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: receiptRoom, SenderID: "2", Content: "First"})
+	require.NoError(t, err)
+	second, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: receiptRoom, SenderID: "2", Content: "Second"})
+	require.NoError(t, err)
+
+	// Nothing read yet
+	count, err := testUtils.ReceiptRepo.CountUnread(receiptRoom, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	cursor, err := testUtils.ReceiptRepo.MarkRead(receiptRoom, "1", first.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, cursor.LastReadMessageID)
+
+	count, err = testUtils.ReceiptRepo.CountUnread(receiptRoom, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = testUtils.ReceiptRepo.MarkRead(receiptRoom, "1", second.ID.Hex())
+	require.NoError(t, err)
+
+	// The cursor never goes back
+	cursor, err = testUtils.ReceiptRepo.MarkRead(receiptRoom, "1", first.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, cursor.LastReadMessageID)
+
+	count, err = testUtils.ReceiptRepo.CountUnread(receiptRoom, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// The sender has no unread message of their own
+	count, err = testUtils.ReceiptRepo.CountUnread(receiptRoom, "2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func MarkReadOtherRoom(t *testing.T) {
+	message, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: "other_room", SenderID: "2", Content: "Hello"})
+	require.NoError(t, err)
+
+	_, err = testUtils.ReceiptRepo.MarkRead(receiptRoom, "1", message.ID.Hex())
+	assert.ErrorIs(t, err, receipt.ErrMessageNotInRoom)
+}
+
+func MarkReadConcurrently(t *testing.T) {
+	const room = "receipt_concurrent_room"
+	messages := []string{}
+	for _, content := range []string{"One", "Two", "Three", "Four"} {
+		message, err := testUtils.MessageRepo.CreateMessage(messageDto.CreateMessageDTO{Room: room, SenderID: "2", Content: content})
+		require.NoError(t, err)
+		messages = append(messages, message.ID.Hex())
+		// The messages are ordered by their millisecond timestamps
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// The devices of the user read the messages at the same time, the cursor ends on the last one
+	var wg sync.WaitGroup
+	for _, messageID := range messages {
+		wg.Add(1)
+		go func(messageID string) {
+			defer wg.Done()
+			_, err := testUtils.ReceiptRepo.MarkRead(room, "1", messageID)
+			assert.NoError(t, err)
+		}(messageID)
+	}
+	wg.Wait()
+
+	cursor, err := testUtils.ReceiptRepo.GetCursor(room, "1")
+	require.NoError(t, err)
+	assert.Equal(t, messages[len(messages)-1], cursor.LastReadMessageID.Hex())
+}
+
+func CountUnreadSameTime(t *testing.T) {
+	const room = "receipt_same_time_room"
+	createdAt := time.Now().Truncate(time.Millisecond)
+	messages := []models.Message{}
+	for _, content := range []string{"One", "Two", "Three"} {
+		message := models.Message{ID: bson.NewObjectID(), Room: room, SenderID: "2", Content: content, CreatedAt: createdAt}
+		require.NoError(t, testUtils.MessageRepo.Create(&message))
+		messages = append(messages, message)
+	}
+
+	// The messages sent in the same millisecond are ordered by their ID
+	_, err := testUtils.ReceiptRepo.MarkRead(room, "1", messages[1].ID.Hex())
+	require.NoError(t, err)
+	count, err := testUtils.ReceiptRepo.CountUnread(room, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "Expected the message sent at the same time after the cursor to be unread")
+
+	cursor, err := testUtils.ReceiptRepo.MarkRead(room, "1", messages[0].ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, messages[1].ID, cursor.LastReadMessageID, "Expected the cursor not to go back")
+
+	cursor, err = testUtils.ReceiptRepo.MarkRead(room, "1", messages[2].ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, messages[2].ID, cursor.LastReadMessageID)
+	count, err = testUtils.ReceiptRepo.CountUnread(room, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
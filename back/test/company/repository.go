@@ -0,0 +1,193 @@
+package company_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"skillly/pkg/config"
+	applicationDto "skillly/pkg/handlers/application/dto"
+	companyDto "skillly/pkg/handlers/company/dto"
+	"skillly/pkg/handlers/companyInvitation"
+	jobPostDto "skillly/pkg/handlers/jobPost/dto"
+	recruiter "skillly/pkg/handlers/recruiterProfile"
+	recruiterDto "skillly/pkg/handlers/recruiterProfile/dto"
+	userDto "skillly/pkg/handlers/user/dto"
+	"skillly/pkg/models"
+	"skillly/pkg/utils"
+	testUtils "skillly/test/utils"
+)
+
+func createUser(t *testing.T, email string) models.User {
+	user, err := testUtils.UserRepo.CreateUser(userDto.CreateUserDTO{
+		FirstName: "Team",
+		LastName:  "Member",
+		Email:     email,
+		Password:  "Password123!",
+		Role:      models.RoleRecruiter,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the user")
+	return user
+}
+
+// createMember creates an active recruiter of the company
+func createMember(t *testing.T, companyID uint, email string, role utils.CompanyRole) models.ProfileRecruiter {
+	member, err := testUtils.RecruiterRepo.CreateRecruiter(recruiterDto.CreateRecruiterDTO{
+		Title:     "Recruiter",
+		CompanyID: companyID,
+		Role:      role,
+		State:     models.ActiveState,
+		User:      createUser(t, email),
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the recruiter")
+	return member
+}
+
+func CompanyTeam(t *testing.T) {
+	company, err := testUtils.CompanyRepo.CreateCompany(companyDto.CreateCompanyDTO{CompanyName: "Team Company", SIRET: "98765432109876"}, config.DB)
+	require.NoError(t, err, "Failed to create the company")
+
+	admin := createMember(t, company.ID, "team.admin@test.com", models.AdminRole)
+	member := createMember(t, company.ID, "team.member@test.com", models.RecruiterRole)
+
+	// The company always keeps an admin
+	_, err = testUtils.RecruiterRepo.ChangeRole(admin.ID, company.ID, models.RecruiterRole, config.DB)
+	assert.ErrorIs(t, err, recruiter.ErrLastAdmin)
+	_, _, err = testUtils.RecruiterRepo.RemoveRecruiter(admin.ID, company.ID, member.ID, config.DB)
+	assert.ErrorIs(t, err, recruiter.ErrLastAdmin)
+
+	// Transfer of the admin rights: the member is promoted before the admin is demoted
+	promoted, err := testUtils.RecruiterRepo.ChangeRole(member.ID, company.ID, models.AdminRole, config.DB)
+	require.NoError(t, err, "Failed to promote the member")
+	assert.Equal(t, models.AdminRole, promoted.Role)
+	demoted, err := testUtils.RecruiterRepo.ChangeRole(admin.ID, company.ID, models.RecruiterRole, config.DB)
+	require.NoError(t, err, "Failed to demote the admin")
+	assert.Equal(t, models.RecruiterRole, demoted.Role)
+
+	// The job posts of a removed recruiter are reassigned
+	duration := 6
+	jobPost, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Team Job",
+		Description:     "Job post of the team.",
+		Location:        "Lille",
+		Contract_type:   models.CDDContract,
+		ContractTerms:   models.ContractTerms{DurationMonths: &duration},
+		Salary_range:    "30,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       company.ID,
+		RecruiterID:     &admin.ID,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the job post")
+
+	_, _, err = testUtils.RecruiterRepo.RemoveRecruiter(admin.ID, company.ID, admin.ID, config.DB)
+	assert.ErrorIs(t, err, recruiter.ErrInvalidReassignment)
+
+	removed, reassigned, err := testUtils.RecruiterRepo.RemoveRecruiter(admin.ID, company.ID, member.ID, config.DB)
+	require.NoError(t, err, "Failed to remove the recruiter")
+	assert.Equal(t, models.RemovedState, removed.State)
+	assert.Equal(t, int64(1), reassigned)
+
+	// A removed admin loses the admin role
+	secondAdmin := createMember(t, company.ID, "team.second.admin@test.com", models.AdminRole)
+	removedAdmin, _, err := testUtils.RecruiterRepo.RemoveRecruiter(secondAdmin.ID, company.ID, member.ID, config.DB)
+	require.NoError(t, err, "Failed to remove the admin")
+	assert.Equal(t, models.RecruiterRole, removedAdmin.Role)
+
+	jobPost, err = testUtils.JobPostRepo.GetByID(jobPost.ID, nil)
+	require.NoError(t, err)
+	require.NotNil(t, jobPost.RecruiterID)
+	assert.Equal(t, member.ID, *jobPost.RecruiterID)
+
+	state, err := testUtils.RecruiterRepo.GetState(admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RemovedState, state, "Expected the removed recruiter to be blocked")
+
+	// Only the active recruiters are notified and are members of the chat rooms of the company
+	pending, err := testUtils.RecruiterRepo.CreateRecruiter(recruiterDto.CreateRecruiterDTO{
+		CompanyID: company.ID,
+		State:     models.PendingState,
+		User:      createUser(t, "team.pending@test.com"),
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the pending recruiter")
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create the application")
+	_, recruiterUserIDs, err := testUtils.ApplicationRepo.GetApplicationUserIDs(application.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{member.UserID}, recruiterUserIDs)
+
+	match, err := testUtils.MatchRepo.CreateMatchFromApplication(application.ID, nil, config.DB)
+	require.NoError(t, err, "Failed to create the match")
+	participants, err := testUtils.MatchRepo.GetParticipantUserIDs(match.ID)
+	require.NoError(t, err)
+	assert.Contains(t, participants, member.UserID)
+	assert.NotContains(t, participants, admin.UserID, "Expected the removed recruiter not to be a participant")
+	assert.NotContains(t, participants, pending.UserID, "Expected the pending recruiter not to be a participant")
+}
+
+func CompanyInvitation(t *testing.T) {
+	company, err := testUtils.CompanyRepo.CreateCompany(companyDto.CreateCompanyDTO{CompanyName: "Inviting Company", SIRET: "11122233344455"}, config.DB)
+	require.NoError(t, err, "Failed to create the company")
+	admin := createMember(t, company.ID, "inviting.admin@test.com", models.AdminRole)
+
+	// Registered users can't be invited
+	_, _, err = testUtils.InvitationRepo.CreateInvitation(models.CompanyInvitation{
+		CompanyID: company.ID, Email: "inviting.admin@test.com", InvitedByID: admin.ID,
+	}, config.DB)
+	assert.ErrorIs(t, err, companyInvitation.ErrAlreadyRegistered)
+
+	first, firstToken, err := testUtils.InvitationRepo.CreateInvitation(models.CompanyInvitation{
+		CompanyID: company.ID, Email: "Invited@Test.com", InvitedByID: admin.ID,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the invitation")
+	assert.Equal(t, "invited@test.com", first.Email)
+	assert.Equal(t, models.RecruiterRole, first.Role, "Expected recruiter to be the default role")
+
+	// A new invitation replaces the previous one
+	invitation, token, err := testUtils.InvitationRepo.CreateInvitation(models.CompanyInvitation{
+		CompanyID: company.ID, Email: "invited@test.com", Role: models.AdminRole, InvitedByID: admin.ID,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the invitation")
+
+	pending, err := testUtils.InvitationRepo.GetPendingInvitations(company.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, invitation.ID, pending[0].ID)
+
+	_, err = testUtils.InvitationRepo.AcceptInvitation(firstToken, "invited@test.com", config.DB)
+	assert.ErrorIs(t, err, companyInvitation.ErrInvalidInvitation, "Expected the replaced invitation to be invalid")
+
+	// The invitation is personal
+	_, err = testUtils.InvitationRepo.AcceptInvitation(token, "someone.else@test.com", config.DB)
+	assert.ErrorIs(t, err, companyInvitation.ErrInvalidInvitation)
+
+	accepted, err := testUtils.InvitationRepo.AcceptInvitation(token, "INVITED@test.com", config.DB)
+	require.NoError(t, err, "Failed to accept the invitation")
+	assert.Equal(t, company.ID, accepted.CompanyID)
+	assert.Equal(t, models.AdminRole, accepted.Role)
+	assert.NotNil(t, accepted.AcceptedAt)
+
+	_, err = testUtils.InvitationRepo.AcceptInvitation(token, "invited@test.com", config.DB)
+	assert.ErrorIs(t, err, companyInvitation.ErrInvalidInvitation, "Expected an invitation to be used once")
+
+	// Expired invitations can't be accepted
+	expired, expiredToken, err := testUtils.InvitationRepo.CreateInvitation(models.CompanyInvitation{
+		CompanyID: company.ID, Email: "late@test.com", InvitedByID: admin.ID,
+	}, config.DB)
+	require.NoError(t, err)
+	require.NoError(t, config.DB.Model(&expired).Update("expires_at", time.Now().Add(-time.Hour)).Error)
+	_, err = testUtils.InvitationRepo.AcceptInvitation(expiredToken, "late@test.com", config.DB)
+	assert.ErrorIs(t, err, companyInvitation.ErrInvalidInvitation)
+
+	// Only the pending invitations of the company can be revoked
+	revoked, _, err := testUtils.InvitationRepo.CreateInvitation(models.CompanyInvitation{
+		CompanyID: company.ID, Email: "revoked@test.com", InvitedByID: admin.ID,
+	}, config.DB)
+	require.NoError(t, err)
+	assert.ErrorIs(t, testUtils.InvitationRepo.RevokeInvitation(revoked.ID, company.ID+1, config.DB), gorm.ErrRecordNotFound)
+	require.NoError(t, testUtils.InvitationRepo.RevokeInvitation(revoked.ID, company.ID, config.DB))
+	assert.ErrorIs(t, testUtils.InvitationRepo.RevokeInvitation(accepted.ID, company.ID, config.DB), gorm.ErrRecordNotFound)
+}
@@ -27,9 +27,10 @@ func MongoDatabaseConnection(t *testing.T) {
 
 func PostgresTableCheck(t *testing.T) {
 	tables := []string{
-		"applications", "candidate_reviews", "certifications", "companies",
-		"company_reviews", "files", "job_posts", "matches", "profile_candidates",
-		"profile_recruiters", "skills", "users",
+		"applications", "application_state_histories", "candidate_reviews", "certifications", "companies",
+		"company_invitations", "company_reviews", "files", "interviews", "job_posts", "matches",
+		"profile_candidates", "profile_recruiters", "scheduled_jobs", "sessions", "skills", "swipes",
+		"user_tokens", "users",
 	}
 	for _, table := range tables {
 		check := config.DB.Migrator().HasTable(table)
@@ -39,7 +40,7 @@ func PostgresTableCheck(t *testing.T) {
 
 func MongoCollectionCheck(t *testing.T) {
 	collections := []string{
-		"room", "message",
+		"room", "message", "readcursor", "attachment",
 	}
 
 	dbCollections, err := chatConfig.DBMongo.ListCollectionNames(context.TODO(), bson.D{})
@@ -5,13 +5,24 @@ import (
 
 	application_test "skillly/test/application"
 	auth_test "skillly/test/auth"
+	candidate_test "skillly/test/candidate"
 	certification_test "skillly/test/certification"
+	attachment_test "skillly/test/chat/attachment"
+	backplane_test "skillly/test/chat/backplane"
 	message_test "skillly/test/chat/message"
+	notification_test "skillly/test/chat/notification"
+	protocol_test "skillly/test/chat/protocol"
+	receipt_test "skillly/test/chat/receipt"
 	room_test "skillly/test/chat/room"
+	company_test "skillly/test/company"
 	db_test "skillly/test/db"
+	geo_test "skillly/test/geo"
+	interview_test "skillly/test/interview"
 	jobpost_test "skillly/test/jobPost"
 	match_test "skillly/test/match"
 	middleware_test "skillly/test/middleware"
+	scheduler_test "skillly/test/scheduler"
+	scoring_test "skillly/test/scoring"
 	skill_test "skillly/test/skill"
 	user_test "skillly/test/user"
 )
@@ -27,32 +38,99 @@ func TestDB(t *testing.T) {
 func TestAuth(t *testing.T) {
 	t.Run("RegisterCandidate", auth_test.RegisterCandidate)
 	t.Run("RegisterRecruiter", auth_test.RegisterRecruiter)
+	t.Run("RecruiterJoinRequest", auth_test.RecruiterJoinRequest)
 	t.Run("Login", auth_test.Login)
+	t.Run("RefreshToken", auth_test.RefreshToken)
+	t.Run("RefreshTokenReuse", auth_test.RefreshTokenReuse)
+	t.Run("RefreshTokenReplay", auth_test.RefreshTokenReplay)
+	t.Run("Logout", auth_test.Logout)
+	t.Run("LogoutAll", auth_test.LogoutAll)
+	t.Run("VerifyEmail", auth_test.VerifyEmail)
+	t.Run("ResetPassword", auth_test.ResetPassword)
+	t.Run("ForgotPasswordUnknownEmail", auth_test.ForgotPasswordUnknownEmail)
 }
 
 func TestUser(t *testing.T) {
 	t.Run("CreateUser", user_test.CreateUser)
 	t.Run("GetUserByEmail", user_test.GetUserByEmail)
 	t.Run("UpdateUser", user_test.UpdateUser)
+	t.Run("UpdateUserCredentials", user_test.UpdateUserCredentials)
 	t.Run("GetAllUsers", user_test.GetAllUsers)
 	t.Run("GetUserById", user_test.GetUserById)
 }
 
+func TestCompany(t *testing.T) {
+	t.Run("CompanyTeam", company_test.CompanyTeam)
+	t.Run("CompanyInvitation", company_test.CompanyInvitation)
+}
+
+func TestCandidate(t *testing.T) {
+	t.Run("SearchCandidates", candidate_test.SearchCandidates)
+	t.Run("CandidateOptOut", candidate_test.CandidateOptOut)
+	t.Run("SearchCandidatesNearby", candidate_test.SearchCandidatesNearby)
+}
+
 func TestJobPost(t *testing.T) {
 	t.Run("CreateJobPost", jobpost_test.CreateJobPost)
 	t.Run("GetJobPostById", jobpost_test.GetJobPostById)
 	t.Run("UpdateJobPost", jobpost_test.UpdateJobPost)
+	t.Run("GetJobFeed", jobpost_test.GetJobFeed)
+	t.Run("JobPostLifecycle", jobpost_test.JobPostLifecycle)
+	t.Run("CloseJobPostApplications", jobpost_test.CloseJobPostApplications)
+	t.Run("DeleteMatchedJobPost", jobpost_test.DeleteMatchedJobPost)
+	t.Run("SearchJobPosts", jobpost_test.SearchJobPosts)
+	t.Run("SearchJobPostsBySalary", jobpost_test.SearchJobPostsBySalary)
+	t.Run("SearchJobPostsNearby", jobpost_test.SearchJobPostsNearby)
+	t.Run("ContractTerms", jobpost_test.ContractTerms)
 }
 
 func TestApplication(t *testing.T) {
 	t.Run("CreateApplication", application_test.CreateApplication)
 	t.Run("GetApplicationById", application_test.GetApplicationById)
 	t.Run("UpdateApplication", application_test.UpdateApplication)
+	t.Run("GetApplicationUserIDs", application_test.GetApplicationUserIDs)
+	t.Run("ApplicationTransitions", application_test.ApplicationTransitions)
+	t.Run("ApplicationStateHistory", application_test.ApplicationStateHistory)
+	t.Run("ApplyOnce", application_test.ApplyOnce)
+	t.Run("WithdrawMatchedApplication", application_test.WithdrawMatchedApplication)
+}
+
+func TestScoring(t *testing.T) {
+	t.Run("PerfectMatch", scoring_test.PerfectMatch)
+	t.Run("PartialMatch", scoring_test.PartialMatch)
+	t.Run("NoRequirements", scoring_test.NoRequirements)
+	t.Run("CloseContracts", scoring_test.CloseContracts)
+	t.Run("LocationDistance", scoring_test.LocationDistance)
+	t.Run("SalaryExpectation", scoring_test.SalaryExpectation)
+	t.Run("ParseSalary", scoring_test.ParseSalary)
+	t.Run("FeedRelevance", scoring_test.FeedRelevance)
+}
+
+func TestGeo(t *testing.T) {
+	t.Run("Geocode", geo_test.Geocode)
+	t.Run("Distance", geo_test.Distance)
+	t.Run("Center", geo_test.Center)
 }
 
 func TestMatch(t *testing.T) {
 	t.Run("CreateMatch", match_test.CreateMatch)
 	t.Run("GetMatchById", match_test.GetMatchById)
+	t.Run("SwipeAndMatch", match_test.SwipeAndMatch)
+	t.Run("CanReachCandidate", match_test.CanReachCandidate)
+	t.Run("LockCandidacy", match_test.LockCandidacy)
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("RunDueJobs", scheduler_test.RunDueJobs)
+	t.Run("RunJobOnce", scheduler_test.RunJobOnce)
+	t.Run("CloseApplicationsOfExpiredJobPosts", scheduler_test.CloseApplicationsOfExpiredJobPosts)
+	t.Run("PurgeAbandonedRooms", scheduler_test.PurgeAbandonedRooms)
+}
+
+func TestInterview(t *testing.T) {
+	t.Run("ProposedSlots", interview_test.ProposedSlots)
+	t.Run("CalendarExport", interview_test.CalendarExport)
+	t.Run("ConfirmedInterviewFeed", interview_test.ConfirmedInterviewFeed)
 }
 
 func TestSkill(t *testing.T) {
@@ -72,6 +150,10 @@ func TestMiddlewares(t *testing.T) {
 	t.Run("AuthMiddlewareUnauthaurized", middleware_test.TestAuthMiddlewareUnauthorized)
 	t.Run("RoleMiddleware", middleware_test.TestRoleMiddleware)
 	t.Run("RoleMiddlewareForbidden", middleware_test.TestRoleMiddlewareForbidden)
+	t.Run("CompanyRoleMiddleware", middleware_test.TestCompanyRoleMiddleware)
+	t.Run("WsAuthMiddlewareUnauthorized", middleware_test.TestWsAuthMiddlewareUnauthorized)
+	t.Run("RoomMemberMiddleware", middleware_test.TestRoomMemberMiddleware)
+	t.Run("RoomMemberMiddlewareForbidden", middleware_test.TestRoomMemberMiddlewareForbidden)
 }
 
 func TestChat(t *testing.T) {
@@ -82,6 +164,31 @@ func TestChat(t *testing.T) {
 
 	t.Run("CreateMessage", message_test.CreateMessage)
 	t.Run("GetAllMessages", message_test.GetAllMessages)
+	t.Run("GetMessagesPage", message_test.GetMessagesPage)
+	t.Run("GetMessagesPageInvalidCursor", message_test.GetMessagesPageInvalidCursor)
+	t.Run("EditMessage", message_test.EditMessage)
+	t.Run("DeleteMessageTombstone", message_test.DeleteMessageTombstone)
+	t.Run("ReactToMessage", message_test.ReactToMessage)
+
+	t.Run("DecodeEnvelope", protocol_test.DecodeEnvelope)
+	t.Run("DecodeInvalidEnvelope", protocol_test.DecodeInvalidEnvelope)
+	t.Run("ErrorFrame", protocol_test.ErrorFrame)
+
+	t.Run("MarkRead", receipt_test.MarkRead)
+	t.Run("MarkReadOtherRoom", receipt_test.MarkReadOtherRoom)
+	t.Run("MarkReadConcurrently", receipt_test.MarkReadConcurrently)
+	t.Run("CountUnreadSameTime", receipt_test.CountUnreadSameTime)
+
+	t.Run("UploadAttachment", attachment_test.UploadAttachment)
+	t.Run("UploadAttachmentNotAllowed", attachment_test.UploadAttachmentNotAllowed)
+	t.Run("SendAttachment", attachment_test.SendAttachment)
+
+	t.Run("PublishAcrossHubs", backplane_test.PublishAcrossHubs)
+	t.Run("BroadcastToUserAcrossInstances", backplane_test.BroadcastToUserAcrossInstances)
+	t.Run("DisconnectSlowClient", backplane_test.DisconnectSlowClient)
+	t.Run("PublishLargeEvent", backplane_test.PublishLargeEvent)
+
+	t.Run("SendToParticipantsOnly", notification_test.SendToParticipantsOnly)
 }
 
 func TestDelete(t *testing.T) {
@@ -0,0 +1,72 @@
+package geo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/pkg/geo"
+)
+
+func Geocode(t *testing.T) {
+	lyon, ok := geo.Geocode("Lyon")
+	require.True(t, ok, "Expected Lyon to be known")
+	assert.InDelta(t, 45.76, lyon.Latitude, 0.01)
+	assert.InDelta(t, 4.84, lyon.Longitude, 0.01)
+
+	// The accents, arrondissements, postal codes and countries are ignored
+	for _, location := range []string{"lyon 3e", "69003 Lyon", "Lyon, France", "LYON"} {
+		point, ok := geo.Geocode(location)
+		if assert.True(t, ok, location) {
+			assert.Equal(t, lyon, point, location)
+		}
+	}
+
+	saintEtienne, ok := geo.Geocode("Saint-Étienne")
+	require.True(t, ok, "Expected Saint-Étienne to be known")
+	point, ok := geo.Geocode("St Etienne")
+	assert.True(t, ok, "Expected the abbreviation to be expanded")
+	assert.Equal(t, saintEtienne, point)
+
+	// The homonyms are told apart by their postal code or department
+	saintDenis, ok := geo.Geocode("Saint-Denis")
+	require.True(t, ok, "Expected Saint-Denis to be known")
+	for _, location := range []string{"93200 Saint-Denis", "Saint-Denis (93)"} {
+		point, _ := geo.Geocode(location)
+		assert.Equal(t, saintDenis, point, location)
+	}
+	for _, location := range []string{"97400 Saint-Denis", "Saint-Denis (974)", "Saint-Denis, 974"} {
+		point, ok := geo.Geocode(location)
+		if assert.True(t, ok, location) {
+			assert.InDelta(t, -20.88, point.Latitude, 0.01, location)
+		}
+	}
+
+	_, ok = geo.Geocode("Atlantis")
+	assert.False(t, ok, "Expected an unknown location")
+}
+
+func Distance(t *testing.T) {
+	paris, _ := geo.Geocode("Paris")
+	lyon, _ := geo.Geocode("Lyon")
+	villeurbanne, _ := geo.Geocode("Villeurbanne")
+
+	assert.InDelta(t, 392, geo.Distance(paris, lyon), 5)
+	assert.InDelta(t, geo.Distance(paris, lyon), geo.Distance(lyon, paris), 0.001)
+	assert.Less(t, geo.Distance(lyon, villeurbanne), 10.0)
+	assert.Equal(t, 0.0, geo.Distance(lyon, lyon))
+}
+
+func Center(t *testing.T) {
+	latitude, longitude := 45.0, 5.0
+	center, err := geo.Center("Paris", &latitude, &longitude)
+	require.NoError(t, err)
+	assert.Equal(t, geo.Point{Latitude: 45, Longitude: 5}, center, "Expected the coordinates to win over the location")
+
+	_, err = geo.Center("", nil, nil)
+	assert.ErrorIs(t, err, geo.ErrMissingCenter)
+
+	_, err = geo.Center("Atlantis", nil, nil)
+	assert.ErrorIs(t, err, geo.ErrUnknownLocation)
+}
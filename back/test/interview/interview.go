@@ -0,0 +1,126 @@
+package interview_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"skillly/pkg/config"
+	applicationDto "skillly/pkg/handlers/application/dto"
+	"skillly/pkg/handlers/interview"
+	jobPostDto "skillly/pkg/handlers/jobPost/dto"
+	"skillly/pkg/ical"
+	"skillly/pkg/models"
+	testUtils "skillly/test/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ProposedSlots(t *testing.T) {
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Local slots are read in the timezone of the proposal and stored in UTC, in chronological order
+	slots, err := interview.ParseSlots([]string{"2030-03-11T09:30", "2030-03-10T14:00", "2030-03-10T13:00:00Z"}, "Europe/Paris", now)
+	require.NoError(t, err, "Failed to parse the slots")
+	require.Len(t, slots, 2, "Expected the duplicated slot to be removed")
+	assert.Equal(t, time.Date(2030, 3, 10, 13, 0, 0, 0, time.UTC), slots[0])
+	assert.Equal(t, time.Date(2030, 3, 11, 8, 30, 0, 0, time.UTC), slots[1])
+
+	_, err = interview.ParseSlots([]string{"2030-03-10T14:00"}, "Mars/Olympus", now)
+	assert.ErrorIs(t, err, interview.ErrInvalidTimezone)
+	_, err = interview.ParseSlots([]string{"2030-02-10T14:00"}, "Europe/Paris", now)
+	assert.ErrorIs(t, err, interview.ErrInvalidSlot, "Expected past slots to be rejected")
+	_, err = interview.ParseSlots([]string{"tomorrow"}, "Europe/Paris", now)
+	assert.ErrorIs(t, err, interview.ErrInvalidSlot)
+
+	// The accepted slot is one of the proposed slots, whatever the offset it is written with
+	chosen, err := interview.MatchSlot(slots, "2030-03-11T10:30:00+02:00", "Europe/Paris")
+	require.NoError(t, err, "Failed to match the slot")
+	assert.Equal(t, slots[1], chosen)
+	_, err = interview.MatchSlot(slots, "2030-03-11T10:30", "Europe/Paris")
+	assert.ErrorIs(t, err, interview.ErrUnknownSlot)
+}
+
+func CalendarExport(t *testing.T) {
+	startsAt := time.Date(2030, 3, 10, 14, 0, 0, 0, time.FixedZone("CET", 3600))
+	event := interview.CalendarEvent(models.Interview{
+		ID:       42,
+		State:    models.ConfirmedInterview,
+		StartsAt: &startsAt,
+		Duration: 45,
+		Location: "12 rue de Rivoli, Paris",
+		Note:     strings.Repeat("Présentez-vous; apportez votre portfolio. ", 3),
+		Sequence: 2,
+	})
+	assert.Equal(t, "interview-42@skillly", event.UID)
+	assert.Equal(t, startsAt.Add(45*time.Minute), event.End)
+
+	calendar := string(ical.Calendar("Entretiens", []ical.Event{event}))
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	assert.Contains(t, calendar, "DTSTART:20300310T130000Z\r\n", "Expected the start in UTC")
+	assert.Contains(t, calendar, "DTEND:20300310T134500Z\r\n")
+	assert.Contains(t, calendar, "SEQUENCE:2\r\n")
+	assert.Contains(t, calendar, "LOCATION:12 rue de Rivoli\\, Paris\r\n", "Expected the commas to be escaped")
+
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "Expected the long lines to be folded")
+	}
+	unfolded := strings.ReplaceAll(calendar, "\r\n ", "")
+	assert.Contains(t, unfolded, `Présentez-vous\; apportez votre portfolio. `, "Expected the folded lines to keep the text")
+}
+
+func ConfirmedInterviewFeed(t *testing.T) {
+	jobPost, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Interview Engineer",
+		Description:     "Job post with an interview.",
+		Location:        "Paris",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+	match, err := testUtils.MatchRepo.CreateMatchFromApplication(application.ID, nil, config.DB)
+	require.NoError(t, err, "Failed to create the match")
+
+	candidate, err := testUtils.CandidateRepo.GetByID(1, nil)
+	require.NoError(t, err, "Failed to get the candidate")
+
+	slot := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+	proposed := models.Interview{MatchID: match.ID, ProposerID: candidate.UserID, State: models.ProposedInterview, Slots: []time.Time{slot}, Duration: 30, Timezone: "UTC"}
+	require.NoError(t, testUtils.InterviewRepo.Create(&proposed), "Failed to create interview")
+
+	feed, err := testUtils.InterviewRepo.GetUserConfirmedInterviews(candidate.UserID, config.DB)
+	require.NoError(t, err, "Failed to get the feed")
+	assert.Empty(t, feed, "Expected only the confirmed interviews")
+
+	// A reschedule and an answer at the same time, only the first one is saved
+	rescheduled := proposed
+	rescheduled.Sequence++
+	require.NoError(t, testUtils.InterviewRepo.SaveInterview(&rescheduled, models.ProposedInterview, proposed.Sequence, config.DB), "Failed to reschedule interview")
+	declined := proposed
+	declined.State = models.DeclinedInterview
+	err = testUtils.InterviewRepo.SaveInterview(&declined, models.ProposedInterview, proposed.Sequence, config.DB)
+	assert.ErrorIs(t, err, interview.ErrInterviewChanged, "Expected the answer to the previous slots to be refused")
+
+	proposed = rescheduled
+	proposed.State = models.ConfirmedInterview
+	proposed.StartsAt = &proposed.Slots[0]
+	require.NoError(t, testUtils.InterviewRepo.SaveInterview(&proposed, models.ProposedInterview, rescheduled.Sequence, config.DB), "Failed to confirm interview")
+
+	feed, err = testUtils.InterviewRepo.GetUserConfirmedInterviews(candidate.UserID, config.DB)
+	require.NoError(t, err, "Failed to get the feed")
+	require.Len(t, feed, 1)
+	assert.Equal(t, proposed.ID, feed[0].ID)
+	assert.Equal(t, "Interview Engineer", feed[0].Match.JobPost.Title, "Expected the match to be loaded for the calendar")
+	assert.True(t, slot.Equal(*feed[0].StartsAt))
+
+	interviews, err := testUtils.InterviewRepo.GetMatchInterviews(match.ID, config.DB)
+	require.NoError(t, err, "Failed to get the interviews of the match")
+	assert.Len(t, interviews, 1)
+}
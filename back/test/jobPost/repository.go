@@ -6,7 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"skillly/pkg/geo"
+	applicationDto "skillly/pkg/handlers/application/dto"
+	"skillly/pkg/handlers/jobPost"
 	jobPostDto "skillly/pkg/handlers/jobPost/dto"
+	"skillly/pkg/scoring"
 	"skillly/pkg/utils"
 	testUtils "skillly/test/utils"
 
@@ -22,8 +26,8 @@ func CreateJobPost(t *testing.T) {
 		Location:        "Paris, France",
 		Contract_type:   models.CDIContract,
 		Salary_range:    "50,000 - 70,000 EUR",
-		Expiration_Date: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
-		CompanyID:       1, // Assuming company with ID 1 exists
+		Expiration_Date: time.Now().AddDate(1, 0, 0), // Open to the applications
+		CompanyID:       1,                           // Assuming company with ID 1 exists
 	}
 
 	jobPost, err := testUtils.JobPostRepo.CreateJobPost(newJobPost, config.DB)
@@ -79,3 +83,441 @@ func DeleteJobPost(t *testing.T) {
 	_, err = testUtils.JobPostRepo.GetByID(jobPosts[0].ID, &params.Populate)
 	assert.Error(t, err, "Expected error when fetching deleted job post")
 }
+
+func GetJobFeed(t *testing.T) {
+	titles := []string{"Feed One", "Feed Two", "Feed Three"}
+	for _, title := range titles {
+		_, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+			Title:           title,
+			Description:     "Open job post of the feed.",
+			Location:        "Remote",
+			Contract_type:   models.CDIContract,
+			Salary_range:    "40,000 - 50,000 EUR",
+			Expiration_Date: time.Now().AddDate(0, 1, 0),
+			CompanyID:       1,
+		}, config.DB)
+		require.NoError(t, err, "Failed to create job post")
+	}
+
+	// Expired job posts are not recommended
+	expired, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Feed Expired",
+		Description:     "Expired job post.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 0, -1),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	var candidate models.ProfileCandidate
+	require.NoError(t, config.DB.Preload("Skills").First(&candidate, 1).Error)
+
+	// Walk through the whole feed page by page
+	seen := map[uint]bool{}
+	cursor := ""
+	for {
+		page, err := testUtils.JobPostRepo.GetFeed(1, jobPostDto.JobFeedQuery{Cursor: cursor, Limit: 2}, config.DB)
+		require.NoError(t, err, "Failed to get the job feed")
+		assert.LessOrEqual(t, len(page.JobPosts), 2)
+
+		for i, item := range page.JobPosts {
+			assert.False(t, seen[item.ID], "Expected each job post once")
+			seen[item.ID] = true
+			// The database ranks the feed like Relevance
+			assert.InDelta(t, scoring.Relevance(candidate, item.JobPost, time.Now()), item.Relevance, 0.1)
+			if i > 0 {
+				assert.LessOrEqual(t, item.Relevance, page.JobPosts[i-1].Relevance, "Expected the most relevant job posts first")
+			}
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.GreaterOrEqual(t, len(seen), len(titles), "Expected the open job posts in the feed")
+	assert.False(t, seen[expired.ID], "Expected no expired job post in the feed")
+
+	_, err = testUtils.JobPostRepo.GetFeed(1, jobPostDto.JobFeedQuery{Cursor: "invalid"}, config.DB)
+	assert.ErrorIs(t, err, jobPost.ErrInvalidCursor)
+}
+
+func JobPostLifecycle(t *testing.T) {
+	duration := 12
+	draft, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Lifecycle Draft",
+		Description:     "Job post written as a draft.",
+		Location:        "Lyon",
+		Contract_type:   models.CDDContract,
+		ContractTerms:   models.ContractTerms{DurationMonths: &duration},
+		Salary_range:    "30,000 - 35,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+		State:           models.DraftJobPost,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the draft")
+	assert.Equal(t, models.DraftJobPost, draft.State)
+
+	page, err := testUtils.JobPostRepo.GetFeed(1, jobPostDto.JobFeedQuery{Limit: jobPostDto.MaxFeedLimit}, config.DB)
+	require.NoError(t, err, "Failed to get the job feed")
+	for _, item := range page.JobPosts {
+		assert.NotEqual(t, draft.ID, item.ID, "Expected no draft in the feed")
+	}
+
+	// A draft cannot be closed before it is published
+	_, err = testUtils.JobPostRepo.Transition(draft.ID, models.ClosedJobPost, config.DB)
+	assert.ErrorIs(t, err, jobPost.ErrInvalidTransition)
+
+	title := "Lifecycle Engineer"
+	skills := []uint{}
+	updated, err := testUtils.JobPostRepo.UpdateJobPost(draft.ID, jobPostDto.UpdateJobPostDTO{Title: &title, Skills: &skills}, config.DB)
+	require.NoError(t, err, "Failed to update the draft")
+	assert.Equal(t, title, updated.Title)
+	assert.Equal(t, "Lyon", updated.Location, "Expected the other fields to be kept")
+
+	past := time.Now().AddDate(0, 0, -1)
+	_, err = testUtils.JobPostRepo.UpdateJobPost(draft.ID, jobPostDto.UpdateJobPostDTO{Expiration_Date: &past}, config.DB)
+	assert.ErrorIs(t, err, jobPost.ErrExpirationPassed)
+
+	published, err := testUtils.JobPostRepo.Transition(draft.ID, models.PublishedJobPost, config.DB)
+	require.NoError(t, err, "Failed to publish the draft")
+	assert.Equal(t, models.PublishedJobPost, published.State)
+
+	// The published job posts past their expiration date expire, pushing it back publishes them again
+	require.NoError(t, config.DB.Model(&published).Update("expiration_date", past).Error)
+	expired, err := testUtils.JobPostRepo.ExpireJobPosts(time.Now(), config.DB)
+	require.NoError(t, err, "Failed to expire job posts")
+	assert.GreaterOrEqual(t, expired, int64(1))
+
+	_, err = testUtils.JobPostRepo.Transition(draft.ID, models.PublishedJobPost, config.DB)
+	assert.ErrorIs(t, err, jobPost.ErrExpirationPassed, "Expected the expiration date to be pushed back first")
+
+	extended := time.Now().AddDate(0, 2, 0)
+	reopened, err := testUtils.JobPostRepo.UpdateJobPost(draft.ID, jobPostDto.UpdateJobPostDTO{Expiration_Date: &extended}, config.DB)
+	require.NoError(t, err, "Failed to extend the job post")
+	assert.Equal(t, models.PublishedJobPost, reopened.State)
+}
+
+func CloseJobPostApplications(t *testing.T) {
+	open, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Filled Position",
+		Description:     "Job post closed once filled.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: open.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+
+	recruiterUserID := uint(1)
+	closed, applications, err := testUtils.JobPostRepo.CloseJobPost(open.ID, &recruiterUserID, config.DB)
+	require.NoError(t, err, "Failed to close the job post")
+	assert.Equal(t, models.ClosedJobPost, closed.State)
+	require.Len(t, applications, 1)
+	assert.Equal(t, application.ID, applications[0].ID)
+	assert.Equal(t, models.ClosedApplication, applications[0].State)
+
+	history, err := testUtils.ApplicationStateRepo.GetHistory(application.ID, config.DB)
+	require.NoError(t, err, "Failed to get the application history")
+	require.NotEmpty(t, history)
+	assert.Equal(t, "Job post closed", history[len(history)-1].Reason)
+
+	// A closed job post receives no application
+	_, err = testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: open.ID, CandidateID: 1}, config.DB)
+	assert.Error(t, err)
+
+	_, _, err = testUtils.JobPostRepo.CloseJobPost(open.ID, &recruiterUserID, config.DB)
+	assert.ErrorIs(t, err, jobPost.ErrInvalidTransition)
+}
+
+func DeleteMatchedJobPost(t *testing.T) {
+	filled, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Matched Position",
+		Description:     "Job post closed with a matched application.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: filled.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+	_, err = testUtils.MatchRepo.CreateMatchFromApplication(application.ID, nil, config.DB)
+	require.NoError(t, err, "Failed to create the match")
+
+	_, _, err = testUtils.JobPostRepo.CloseJobPost(filled.ID, nil, config.DB)
+	require.NoError(t, err, "Failed to close the job post")
+
+	// The conversations of the matches go on
+	_, err = testUtils.JobPostRepo.DeleteJobPost(filled.ID, config.DB)
+	assert.ErrorIs(t, err, jobPost.ErrJobPostMatched)
+
+	// Without match the job post is deleted and the candidates of its applications are returned
+	require.NoError(t, testUtils.MatchRepo.DeleteApplicationMatches(application.ID, config.DB))
+	require.NoError(t, config.DB.Model(&application).Update("state", models.ClosedApplication).Error)
+	userIDs, err := testUtils.JobPostRepo.DeleteJobPost(filled.ID, config.DB)
+	require.NoError(t, err, "Failed to delete the job post")
+	assert.Len(t, userIDs, 1)
+
+	_, err = testUtils.JobPostRepo.GetByID(filled.ID, nil)
+	assert.Error(t, err, "Expected the job post to be deleted")
+}
+
+func SearchJobPosts(t *testing.T) {
+	skill := models.Skill{Name: "Kubernetes Search", Category: "DevOps"}
+	require.NoError(t, testUtils.SkillRepo.Create(&skill), "Failed to create skill")
+
+	create := func(title string, description string, contract utils.ContractType, salary string, skills []uint) models.JobPost {
+		terms := models.ContractTerms{}
+		if contract == models.CDDContract {
+			duration := 12
+			terms.DurationMonths = &duration
+		}
+		created, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+			Title:           title,
+			Description:     description,
+			Location:        "Bordeaux",
+			Contract_type:   contract,
+			ContractTerms:   terms,
+			Salary_range:    salary,
+			Expiration_Date: time.Now().AddDate(0, 1, 0),
+			CompanyID:       1,
+			Skills:          skills,
+		}, config.DB)
+		require.NoError(t, err, "Failed to create job post")
+		return created
+	}
+	french := create("Développeuse plateforme", "Nous recherchons des développeurs passionnés par les plateformes cloud.", models.CDIContract, "45,000 - 55,000 EUR", []uint{skill.ID})
+	english := create("Platform engineer", "We are hiring engineers building cloud platforms.", models.CDDContract, "60,000 - 70,000 EUR", []uint{skill.ID})
+	create("Comptable", "Tenue de la comptabilité.", models.CDIContract, "35,000 - 40,000 EUR", nil)
+
+	ids := func(page models.JobSearchPage) []uint {
+		found := []uint{}
+		for _, item := range page.JobPosts {
+			found = append(found, item.ID)
+		}
+		return found
+	}
+
+	// The French and English words are stemmed
+	page, err := testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Query: "développeur", Location: "Bordeaux"}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{french.ID}, ids(page))
+	assert.Greater(t, page.JobPosts[0].Rank, 0.0, "Expected the text relevance")
+
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Query: "engineers", Location: "Bordeaux"}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{english.ID}, ids(page))
+
+	// The salary ranges overlapping the wanted range are found
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Location: "Bordeaux", SalaryMin: 50000, SalaryMax: 58000}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{french.ID}, ids(page))
+
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{
+		Location:  "Bordeaux",
+		Contracts: []utils.ContractType{models.CDIContract},
+		Skills:    []uint{skill.ID},
+	}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{french.ID}, ids(page))
+	assert.Equal(t, int64(1), page.Total)
+
+	// Each facet ignores its own filter
+	contracts := map[string]int64{}
+	for _, facet := range page.Facets.Contracts {
+		contracts[facet.Value] = facet.Count
+	}
+	assert.Equal(t, map[string]int64{"CDI": 1, "CDD": 1}, contracts)
+	require.Len(t, page.Facets.Skills, 1)
+	assert.Equal(t, skill.ID, page.Facets.Skills[0].ID)
+	assert.Equal(t, int64(1), page.Facets.Skills[0].Count)
+	require.Len(t, page.Facets.Companies, 1)
+	assert.Equal(t, uint(1), page.Facets.Companies[0].ID)
+}
+
+func SearchJobPostsBySalary(t *testing.T) {
+	create := func(title string, salary *models.Salary, label string) models.JobPost {
+		created, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+			Title:           title,
+			Description:     "Poste à Toulouse",
+			Location:        "Toulouse",
+			Contract_type:   models.CDIContract,
+			Salary_range:    label,
+			Salary:          salary,
+			Expiration_Date: time.Now().AddDate(0, 1, 0),
+			CompanyID:       1,
+		}, config.DB)
+		require.NoError(t, err, "Failed to create job post")
+		return created
+	}
+	amount := func(value int) *int { return &value }
+
+	monthly := create("Développeur mensuel", &models.Salary{Min: amount(4000), Max: amount(4500), Period: models.MonthlySalary}, "")
+	assert.Equal(t, "EUR", monthly.Salary.Currency, "Expected the default currency")
+	assert.Equal(t, models.GrossSalary, monthly.Salary.Basis, "Expected the default basis")
+	assert.Equal(t, "4000 - 4500 EUR brut/mois", monthly.Salary_range, "Expected the label of the salary")
+
+	// The label is parsed when the amounts are not given
+	parsed := create("Développeur annuel", nil, "38k - 42k € brut/an")
+	require.True(t, parsed.Salary.IsSet(), "Expected the salary to be parsed")
+	assert.Equal(t, 38000, *parsed.Salary.Min)
+	assert.Equal(t, models.YearlySalary, parsed.Salary.Period)
+
+	dollars := create("Developer", &models.Salary{Min: amount(70000), Currency: "USD"}, "")
+
+	_, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Invalid",
+		Location:        "Toulouse",
+		Salary:          &models.Salary{Min: amount(50000), Max: amount(40000)},
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+	}, config.DB)
+	assert.ErrorIs(t, err, models.ErrInvalidSalary)
+
+	ids := func(page models.JobSearchPage) []uint {
+		found := []uint{}
+		for _, item := range page.JobPosts {
+			found = append(found, item.ID)
+		}
+		return found
+	}
+
+	// 4500 a month is 54000 a year, the amounts are compared in euros by default
+	page, err := testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Location: "Toulouse", SalaryMin: 50000}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{monthly.ID}, ids(page))
+
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{
+		Location:     "Toulouse",
+		SalaryMax:    3500,
+		SalaryPeriod: models.MonthlySalary,
+	}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{parsed.ID}, ids(page))
+
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Location: "Toulouse", SalaryCurrency: "USD"}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{dollars.ID}, ids(page))
+
+	// The best paid job posts come first, the salaries in other currencies are not converted
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Location: "Toulouse", Sort: jobPostDto.SortBySalary}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{dollars.ID, monthly.ID, parsed.ID}, ids(page))
+}
+
+func SearchJobPostsNearby(t *testing.T) {
+	create := func(location string, workMode utils.WorkMode) models.JobPost {
+		created, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+			Title:           "Cartographe",
+			Description:     "Cartographe des réseaux",
+			Location:        location,
+			WorkMode:        workMode,
+			Contract_type:   models.CDIContract,
+			Salary_range:    "40,000 - 50,000 EUR",
+			Expiration_Date: time.Now().AddDate(0, 1, 0),
+			CompanyID:       1,
+		}, config.DB)
+		require.NoError(t, err, "Failed to create job post")
+		return created
+	}
+	villeurbanne := create("Villeurbanne", "")
+	assert.NotNil(t, villeurbanne.Coordinates.Latitude, "Expected the location to be geocoded")
+	assert.Equal(t, models.OnSiteWork, villeurbanne.WorkMode, "Expected on site by default")
+	grenoble := create("Grenoble", models.HybridWork)
+	remote := create("Paris", models.RemoteWork)
+
+	ids := func(page models.JobSearchPage) []uint {
+		found := []uint{}
+		for _, item := range page.JobPosts {
+			found = append(found, item.ID)
+		}
+		return found
+	}
+
+	// The remote job posts are found at any distance
+	page, err := testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Query: "cartographe", Near: "Lyon", RadiusKm: 30}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.ElementsMatch(t, []uint{villeurbanne.ID, remote.ID}, ids(page))
+
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{
+		Query:     "cartographe",
+		Near:      "Lyon",
+		RadiusKm:  150,
+		WorkModes: []utils.WorkMode{models.OnSiteWork, models.HybridWork},
+		Sort:      jobPostDto.SortByDistance,
+	}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Equal(t, []uint{villeurbanne.ID, grenoble.ID}, ids(page))
+	require.NotNil(t, page.JobPosts[1].Distance)
+	assert.InDelta(t, 95, *page.JobPosts[1].Distance, 10)
+
+	_, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Near: "Atlantis", RadiusKm: 30}, config.DB)
+	assert.ErrorIs(t, err, geo.ErrUnknownLocation)
+}
+
+func ContractTerms(t *testing.T) {
+	create := func(contract utils.ContractType, terms models.ContractTerms) (models.JobPost, error) {
+		return testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+			Title:           "Technicien réseau",
+			Description:     "Technicien réseau à Nantes",
+			Location:        "Nantes",
+			Contract_type:   contract,
+			ContractTerms:   terms,
+			Salary_range:    "1 800 € brut/mois",
+			Expiration_Date: time.Now().AddDate(0, 1, 0),
+			CompanyID:       1,
+		}, config.DB)
+	}
+	months := func(value int) *int { return &value }
+
+	_, err := create(models.CDIContract, models.ContractTerms{DurationMonths: months(12)})
+	assert.ErrorIs(t, err, models.ErrDurationForbidden)
+	_, err = create(models.InternshipContract, models.ContractTerms{})
+	assert.ErrorIs(t, err, models.ErrDurationRequired)
+	_, err = create(models.InternshipContract, models.ContractTerms{DurationMonths: months(8)})
+	assert.ErrorIs(t, err, models.ErrDurationTooLong, "Expected an internship to last 6 months at most")
+	_, err = create(models.WorkStudyContract, models.ContractTerms{DurationMonths: months(24)})
+	assert.ErrorIs(t, err, models.ErrRhythmRequired)
+	_, err = create(models.CDDContract, models.ContractTerms{DurationMonths: months(6), Rhythm: "1 semaine / 1 semaine"})
+	assert.ErrorIs(t, err, models.ErrRhythmForbidden)
+
+	internship, err := create(models.InternshipContract, models.ContractTerms{DurationMonths: months(6)})
+	require.NoError(t, err, "Failed to create the internship")
+	workStudy, err := create(models.WorkStudyContract, models.ContractTerms{DurationMonths: months(24), Rhythm: "3 semaines / 1 semaine"})
+	require.NoError(t, err, "Failed to create the work-study job post")
+	permanent, err := create(models.CDIContract, models.ContractTerms{})
+	require.NoError(t, err, "Failed to create the CDI")
+
+	// The permanent contracts have no duration
+	page, err := testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{Location: "Nantes", MaxDuration: 12}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	require.Len(t, page.JobPosts, 1)
+	assert.Equal(t, internship.ID, page.JobPosts[0].ID)
+
+	page, err = testUtils.JobPostRepo.SearchJobPosts(jobPostDto.SearchJobPostsDTO{
+		Location:  "Nantes",
+		Contracts: []utils.ContractType{models.WorkStudyContract, models.CDIContract},
+	}, config.DB)
+	require.NoError(t, err, "Failed to search job posts")
+	assert.Len(t, page.JobPosts, 2)
+
+	// The terms are reset when the contract type changes
+	contract := models.CDIContract
+	updated, err := testUtils.JobPostRepo.UpdateJobPost(workStudy.ID, jobPostDto.UpdateJobPostDTO{Contract_type: &contract}, config.DB)
+	require.NoError(t, err, "Failed to update the contract type")
+	assert.Nil(t, updated.ContractTerms.DurationMonths)
+	assert.Empty(t, updated.ContractTerms.Rhythm)
+
+	_, err = testUtils.JobPostRepo.UpdateJobPost(permanent.ID, jobPostDto.UpdateJobPostDTO{ContractTerms: &models.ContractTerms{DurationMonths: months(3)}}, config.DB)
+	assert.ErrorIs(t, err, models.ErrDurationForbidden)
+}
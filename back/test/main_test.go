@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"skillly/pkg/mailer"
 	"skillly/test/setup"
 	testUtils "skillly/test/utils"
 )
@@ -15,6 +16,7 @@ func TestMain(m *testing.M) {
 	setup.SetupTestMongo()
 
 	testUtils.InitTestRepositories()
+	mailer.Default = testUtils.Mailer
 
 	// Run the tests
 	code := m.Run()
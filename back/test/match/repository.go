@@ -2,10 +2,16 @@ package match_test
 
 import (
 	"skillly/pkg/config"
+	applicationDto "skillly/pkg/handlers/application/dto"
+	"skillly/pkg/handlers/applicationState"
+	companyDto "skillly/pkg/handlers/company/dto"
+	jobPostDto "skillly/pkg/handlers/jobPost/dto"
 	matchDto "skillly/pkg/handlers/match/dto"
+	"skillly/pkg/models"
 	"skillly/pkg/utils"
 	testUtils "skillly/test/utils"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,3 +61,101 @@ func DeleteMatch(t *testing.T) {
 	require.Error(t, err, "Expected error when getting deleted match")
 
 }
+
+func SwipeAndMatch(t *testing.T) {
+	jobPost, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Swipe Engineer",
+		Description:     "Job post liked by the recruiters.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 1, 0),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	swipe, err := testUtils.MatchRepo.SaveSwipe(models.Swipe{JobPostID: jobPost.ID, CandidateID: 1, RecruiterID: 1, Decision: models.PassSwipe}, config.DB)
+	require.NoError(t, err, "Failed to save the pass")
+
+	// A new decision replaces the previous one
+	liked, err := testUtils.MatchRepo.SaveSwipe(models.Swipe{JobPostID: jobPost.ID, CandidateID: 1, RecruiterID: 1, Decision: models.LikeSwipe}, config.DB)
+	require.NoError(t, err, "Failed to save the like")
+	assert.Equal(t, swipe.ID, liked.ID, "Expected a single decision per candidate and job post")
+
+	saved, err := testUtils.MatchRepo.GetSwipe(jobPost.ID, 1, config.DB)
+	require.NoError(t, err, "Failed to get the decision")
+	assert.Equal(t, models.LikeSwipe, saved.Decision)
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+
+	recruiterUserID := uint(1)
+	created, err := testUtils.MatchRepo.CreateMatchFromApplication(application.ID, &recruiterUserID, config.DB)
+	require.NoError(t, err, "Failed to create the match")
+	assert.Equal(t, application.CandidateID, created.CandidateID)
+	assert.Equal(t, application.JobPostID, created.JobPostID)
+
+	matched, err := testUtils.ApplicationRepo.GetByID(application.ID, nil)
+	require.NoError(t, err, "Failed to get the application")
+	assert.Equal(t, models.MatchedApplication, matched.State)
+
+	// An application is matched once
+	_, err = testUtils.MatchRepo.CreateMatchFromApplication(application.ID, &recruiterUserID, config.DB)
+	assert.ErrorIs(t, err, applicationState.ErrInvalidTransition)
+}
+
+func CanReachCandidate(t *testing.T) {
+	// The candidate applied to the company
+	reachable, err := testUtils.MatchRepo.CanReachCandidate(1, 1)
+	require.NoError(t, err)
+	assert.True(t, reachable, "Expected an applicant to be reachable")
+
+	company, err := testUtils.CompanyRepo.CreateCompany(companyDto.CreateCompanyDTO{CompanyName: "Stranger Company", SIRET: "55566677788899"}, config.DB)
+	require.NoError(t, err, "Failed to create the company")
+
+	var candidate models.ProfileCandidate
+	require.NoError(t, config.DB.First(&candidate, 1).Error)
+	defer testUtils.CandidateRepo.SetDiscoverable(1, candidate.Discoverable, config.DB)
+
+	require.NoError(t, testUtils.CandidateRepo.SetDiscoverable(1, false, config.DB), "Failed to hide the candidate")
+	reachable, err = testUtils.MatchRepo.CanReachCandidate(1, company.ID)
+	require.NoError(t, err)
+	assert.False(t, reachable, "Expected a hidden candidate to be unreachable by another company")
+
+	require.NoError(t, testUtils.CandidateRepo.SetDiscoverable(1, true, config.DB), "Failed to show the candidate")
+	reachable, err = testUtils.MatchRepo.CanReachCandidate(1, company.ID)
+	require.NoError(t, err)
+	assert.True(t, reachable, "Expected a discoverable candidate to be reachable")
+}
+
+func LockCandidacy(t *testing.T) {
+	first := config.DB.Begin()
+	require.NoError(t, testUtils.MatchRepo.LockCandidacy(1, 1, first), "Failed to lock the candidacy")
+
+	// Another candidacy is not locked
+	other := config.DB.Begin()
+	require.NoError(t, testUtils.MatchRepo.LockCandidacy(1, 2, other))
+	other.Rollback()
+
+	// The same candidacy waits for the end of the first transaction
+	locked := make(chan error)
+	go func() {
+		second := config.DB.Begin()
+		defer second.Rollback()
+		locked <- testUtils.MatchRepo.LockCandidacy(1, 1, second)
+	}()
+
+	select {
+	case <-locked:
+		assert.Fail(t, "Expected the second transaction to wait for the lock")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Commit().Error)
+	select {
+	case err := <-locked:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Expected the lock to be released on commit")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"skillly/pkg/middleware"
 	testUtils "skillly/test/utils"
 	"testing"
@@ -26,7 +25,8 @@ func TestAuthMiddleware(t *testing.T) {
 
 	// Create a test request
 	req, _ := http.NewRequest("GET", "/test", nil)
-	token, _ := testUtils.CandidateToken.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	token, err := testUtils.SignTestToken(testUtils.CandidateToken)
+	require.NoError(t, err)
 	req.Header.Set("Authorization", "Bearer "+token) // Use a valid token for testing
 
 	// Create a response recorder
@@ -40,7 +40,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 	// Assert the response body
 	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 	assert.Equal(t, "success", response["message"])
 }
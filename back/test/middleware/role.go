@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"skillly/pkg/middleware"
 	"skillly/pkg/models"
 	testUtils "skillly/test/utils"
@@ -27,7 +26,8 @@ func TestRoleMiddleware(t *testing.T) {
 
 	// Create a test request with the correct role
 	req, _ := http.NewRequest("GET", "/test", nil)
-	token, _ := testUtils.RecruiterToken.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	token, err := testUtils.SignTestToken(testUtils.RecruiterToken)
+	require.NoError(t, err)
 	req.Header.Set("Authorization", "Bearer "+token) // Use a valid token for testing
 
 	// Create a response recorder
@@ -41,7 +41,7 @@ func TestRoleMiddleware(t *testing.T) {
 
 	// Assert the response body
 	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 	assert.Equal(t, "success", response["message"])
 }
@@ -57,7 +57,9 @@ func TestRoleMiddlewareForbidden(t *testing.T) {
 
 	// Create a test request with an incorrect role
 	req, _ := http.NewRequest("GET", "/test", nil)
-	req.Header.Set("Authorization", "Bearer "+testUtils.CandidateToken.Raw) // Use a candidate token for testing
+	token, err := testUtils.SignTestToken(testUtils.CandidateToken)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token) // Use a candidate token for testing
 
 	// Create a response recorder
 	w := httptest.NewRecorder()
@@ -70,7 +72,39 @@ func TestRoleMiddlewareForbidden(t *testing.T) {
 
 	// Assert the response body
 	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "Forbidden", response["error"])
+}
+
+func TestCompanyRoleMiddleware(t *testing.T) {
+	r := gin.Default()
+
+	// The role in the company comes from the token
+	r.GET("/recruiter", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), middleware.CompanyRoleMiddleware(models.RecruiterRole), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+	r.GET("/admin", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleRecruiter), middleware.CompanyRoleMiddleware(models.AdminRole), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	token, err := testUtils.SignTestToken(testUtils.RecruiterToken)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/recruiter", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 	assert.Equal(t, "Forbidden", response["error"])
 }
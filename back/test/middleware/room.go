@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chatMiddleware "skillly/chat/middleware"
+	"skillly/pkg/middleware"
+	"skillly/pkg/models"
+	testUtils "skillly/test/utils"
+)
+
+func roomRequest(t *testing.T, token *jwt.Token, roomID string) int {
+	r := gin.Default()
+	r.GET("/ws/:roomId", middleware.WsAuthMiddleware(), chatMiddleware.RoomMemberMiddleware("roomId"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	signedToken, err := testUtils.SignTestToken(token)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/ws/"+roomID+"?token="+signedToken, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func TestRoomMemberMiddleware(t *testing.T) {
+	// The candidate of the match 1 is a member of the room
+	assert.Equal(t, http.StatusOK, roomRequest(t, testUtils.CandidateToken, "1"))
+	assert.Equal(t, http.StatusNotFound, roomRequest(t, testUtils.CandidateToken, "999"))
+}
+
+func TestRoomMemberMiddlewareForbidden(t *testing.T) {
+	// The user created in the user tests takes no part in the match
+	outsider := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email":       "test@test.com",
+		"role":        models.RoleCandidate,
+		"id":          3,
+		"candidateID": 0,
+	})
+
+	assert.Equal(t, http.StatusForbidden, roomRequest(t, outsider, "1"))
+}
+
+func TestWsAuthMiddlewareUnauthorized(t *testing.T) {
+	r := gin.Default()
+	r.GET("/ws/:roomId", middleware.WsAuthMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest("GET", "/ws/1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
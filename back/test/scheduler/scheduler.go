@@ -0,0 +1,114 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"skillly/pkg/config"
+	applicationDto "skillly/pkg/handlers/application/dto"
+	jobPostDto "skillly/pkg/handlers/jobPost/dto"
+	"skillly/pkg/models"
+	"skillly/pkg/scheduler"
+	testUtils "skillly/test/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func RunDueJobs(t *testing.T) {
+	ctx := context.Background()
+	runs := 0
+	job := scheduler.Job{Name: "test_due_jobs", Interval: time.Hour, Run: func(ctx context.Context, now time.Time) error {
+		runs++
+		return nil
+	}}
+	now := time.Now()
+
+	ran := scheduler.New(config.DB, job).RunDue(ctx, now)
+	assert.Equal(t, []string{job.Name}, ran)
+
+	ran = scheduler.New(config.DB, job).RunDue(ctx, now.Add(time.Minute))
+	assert.Empty(t, ran, "Expected the last run to survive a restart")
+
+	ran = scheduler.New(config.DB, job).RunDue(ctx, now.Add(time.Hour))
+	assert.Equal(t, []string{job.Name}, ran, "Expected the job to run again after its interval")
+	assert.Equal(t, 2, runs)
+
+	var state models.ScheduledJob
+	require.NoError(t, config.DB.First(&state, "name = ?", job.Name).Error)
+	require.NotNil(t, state.LastRunAt)
+	assert.WithinDuration(t, now.Add(time.Hour), *state.LastRunAt, time.Millisecond)
+}
+
+func RunJobOnce(t *testing.T) {
+	ctx := context.Background()
+	var concurrent []string
+	job := scheduler.Job{Name: "test_job_once", Interval: time.Minute}
+	job.Run = func(ctx context.Context, now time.Time) error {
+		// Another instance trying to run the same job while it runs is stopped by the lock
+		other := scheduler.Job{Name: job.Name, Interval: job.Interval, Run: func(ctx context.Context, now time.Time) error { return nil }}
+		concurrent = scheduler.New(config.DB, other).RunDue(ctx, now)
+		return nil
+	}
+
+	ran := scheduler.New(config.DB, job).RunDue(ctx, time.Now())
+	assert.Equal(t, []string{job.Name}, ran)
+	assert.Empty(t, concurrent, "Expected the job to run on a single instance")
+}
+
+func CloseApplicationsOfExpiredJobPosts(t *testing.T) {
+	jobPost, err := testUtils.JobPostRepo.CreateJobPost(jobPostDto.CreateJobPostDTO{
+		Title:           "Expiring Position",
+		Description:     "Job post expiring with a pending application.",
+		Location:        "Remote",
+		Contract_type:   models.CDIContract,
+		Salary_range:    "40,000 - 50,000 EUR",
+		Expiration_Date: time.Now().AddDate(0, 0, 1),
+		CompanyID:       1,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create job post")
+
+	application, err := testUtils.ApplicationRepo.CreateApplication(applicationDto.CreateApplicationDTO{JobPostID: jobPost.ID, CandidateID: 1}, config.DB)
+	require.NoError(t, err, "Failed to create application")
+
+	later := time.Now().AddDate(0, 0, 2)
+	require.NoError(t, scheduler.ExpireJobPosts(context.Background(), later), "Failed to expire job posts")
+	require.NoError(t, scheduler.CloseStaleApplications(context.Background(), later), "Failed to close applications")
+
+	expired, err := testUtils.JobPostRepo.GetByID(jobPost.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExpiredJobPost, expired.State)
+
+	closed, err := testUtils.ApplicationRepo.GetByID(application.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClosedApplication, closed.State)
+}
+
+func PurgeAbandonedRooms(t *testing.T) {
+	// The room of a match which doesn't exist
+	orphan, err := testUtils.RoomRepo.CreateRoom("987654321")
+	require.NoError(t, err, "Failed to create room")
+
+	roomExists := func(at time.Time) bool {
+		rooms, err := testUtils.RoomRepo.GetRoomsCreatedBefore(at)
+		require.NoError(t, err)
+		for _, room := range rooms {
+			if room.Name == orphan.Name {
+				return true
+			}
+		}
+		return false
+	}
+
+	// The grace period runs from when the room is found orphaned, not from its creation
+	found := time.Now().Add(scheduler.AbandonedRoomGrace + time.Minute)
+	require.NoError(t, scheduler.PurgeAbandonedRooms(context.Background(), found), "Failed to purge rooms")
+	assert.True(t, roomExists(found), "Expected the orphaned room to be kept during the grace period")
+
+	require.NoError(t, scheduler.PurgeAbandonedRooms(context.Background(), found.Add(time.Hour)), "Failed to purge rooms")
+	assert.True(t, roomExists(found), "Expected the orphaned room to be kept during the grace period")
+
+	require.NoError(t, scheduler.PurgeAbandonedRooms(context.Background(), found.Add(scheduler.AbandonedRoomGrace+time.Minute)), "Failed to purge rooms")
+	assert.False(t, roomExists(found), "Expected the abandoned room to be deleted")
+}
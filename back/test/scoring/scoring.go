@@ -0,0 +1,185 @@
+package scoring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"skillly/pkg/models"
+	"skillly/pkg/scoring"
+)
+
+func jobPost() models.JobPost {
+	return models.JobPost{
+		Location:       "Paris, France",
+		Contract_type:  models.CDIContract,
+		ExperienceYear: 4,
+		Skills:         []models.Skill{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}},
+		Certifications: []models.Certification{{ID: 1}},
+	}
+}
+
+func factor(factors []models.ScoreFactor, name string) models.ScoreFactor {
+	for _, factor := range factors {
+		if factor.Name == name {
+			return factor
+		}
+	}
+	return models.ScoreFactor{}
+}
+
+func PerfectMatch(t *testing.T) {
+	candidate := models.ProfileCandidate{
+		Location:         "paris",
+		PreferedContract: models.CDIContract,
+		ExperienceYear:   6,
+		Skills:           []models.Skill{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}},
+		Certifications:   []models.Certification{{ID: 1}},
+	}
+
+	score, factors := scoring.Compute(candidate, jobPost())
+	assert.Equal(t, 100, score)
+	assert.Len(t, factors, 6, "Expected the contribution of each criterion")
+}
+
+func PartialMatch(t *testing.T) {
+	candidate := models.ProfileCandidate{
+		Location:         "Lyon",
+		PreferedContract: models.CDDContract,
+		ExperienceYear:   2,
+		Skills:           []models.Skill{{ID: 1}, {ID: 2}},
+	}
+
+	score, factors := scoring.Compute(candidate, jobPost())
+
+	// Half of the skills, no certification, half of the experience, other contract and city, no expected salary
+	assert.Equal(t, 20.0, factor(factors, scoring.SkillsFactor).Points)
+	assert.Equal(t, 0.0, factor(factors, scoring.CertificationsFactor).Points)
+	assert.Equal(t, 7.5, factor(factors, scoring.ExperienceFactor).Points)
+	assert.Equal(t, 0.0, factor(factors, scoring.ContractFactor).Points)
+	assert.Equal(t, 0.0, factor(factors, scoring.LocationFactor).Points)
+	assert.Equal(t, 5.0, factor(factors, scoring.SalaryFactor).Points)
+	assert.Equal(t, 33, score)
+}
+
+func CloseContracts(t *testing.T) {
+	internship := jobPost()
+	internship.Contract_type = models.InternshipContract
+
+	candidate := models.ProfileCandidate{PreferedContract: models.WorkStudyContract}
+	_, factors := scoring.Compute(candidate, internship)
+	assert.Equal(t, 0.5, factor(factors, scoring.ContractFactor).Ratio, "Expected an internship to partly fit a work-study candidate")
+
+	candidate.PreferedContract = models.CDIContract
+	_, factors = scoring.Compute(candidate, internship)
+	assert.Equal(t, 0.0, factor(factors, scoring.ContractFactor).Ratio)
+}
+
+func LocationDistance(t *testing.T) {
+	offered := jobPost()
+	offered.Location = "Lyon"
+	offered.Coordinates = models.Locate("Lyon")
+
+	candidate := func(location string) models.ProfileCandidate {
+		return models.ProfileCandidate{Location: location, Coordinates: models.Locate(location)}
+	}
+
+	// Every point nearby, fewer points further, none from far away
+	_, factors := scoring.Compute(candidate("Villeurbanne"), offered)
+	assert.Equal(t, 1.0, factor(factors, scoring.LocationFactor).Ratio)
+	_, factors = scoring.Compute(candidate("Saint-Étienne"), offered)
+	assert.InDelta(t, 0.7, factor(factors, scoring.LocationFactor).Ratio, 0.1)
+	_, factors = scoring.Compute(candidate("Paris"), offered)
+	assert.Equal(t, 0.0, factor(factors, scoring.LocationFactor).Ratio)
+
+	offered.WorkMode = models.RemoteWork
+	_, factors = scoring.Compute(candidate("Paris"), offered)
+	assert.Equal(t, 1.0, factor(factors, scoring.LocationFactor).Ratio, "Expected a remote job post to accept everyone")
+
+	offered.WorkMode = models.OnSiteWork
+	remote := candidate("Villeurbanne")
+	remote.PreferedWorkMode = models.RemoteWork
+	_, factors = scoring.Compute(remote, offered)
+	assert.Equal(t, 0.0, factor(factors, scoring.LocationFactor).Ratio, "Expected an on site job post not to fit a remote candidate")
+}
+
+func SalaryExpectation(t *testing.T) {
+	min, max := 3000, 3500
+	offered := jobPost()
+	offered.Salary = models.Salary{Min: &min, Max: &max, Currency: "EUR", Period: models.MonthlySalary, Basis: models.GrossSalary}
+
+	// 3500 a month is 42000 a year
+	expected := 42000
+	candidate := models.ProfileCandidate{ExpectedSalary: models.Salary{Min: &expected, Currency: "EUR", Period: models.YearlySalary}}
+	_, factors := scoring.Compute(candidate, offered)
+	assert.Equal(t, 1.0, factor(factors, scoring.SalaryFactor).Ratio)
+
+	expected = 60000
+	_, factors = scoring.Compute(candidate, offered)
+	assert.Equal(t, 0.7, factor(factors, scoring.SalaryFactor).Ratio)
+
+	candidate.ExpectedSalary.Currency = "USD"
+	_, factors = scoring.Compute(candidate, offered)
+	assert.Equal(t, 0.5, factor(factors, scoring.SalaryFactor).Ratio)
+}
+
+func ParseSalary(t *testing.T) {
+	cases := []struct {
+		text     string
+		min, max int
+		currency string
+		period   string
+		basis    string
+	}{
+		{"40,000 - 50,000 EUR", 40000, 50000, "EUR", "yearly", "gross"},
+		{"45k-55k€ brut/an", 45000, 55000, "EUR", "yearly", "gross"},
+		{"40-50k€", 40000, 50000, "EUR", "yearly", "gross"},
+		{"2 500 € net par mois", 2500, 2500, "EUR", "monthly", "net"},
+		{"$60k - $80k", 60000, 80000, "USD", "yearly", "gross"},
+		{"15 CHF/h", 15, 15, "CHF", "hourly", "gross"},
+		{"1800 - 2200", 1800, 2200, "EUR", "monthly", "gross"},
+	}
+	for _, c := range cases {
+		salary, ok := models.ParseSalary(c.text)
+		if assert.True(t, ok, c.text) {
+			assert.Equal(t, c.min, *salary.Min, c.text)
+			assert.Equal(t, c.max, *salary.Max, c.text)
+			assert.Equal(t, c.currency, salary.Currency, c.text)
+			assert.Equal(t, c.period, string(salary.Period), c.text)
+			assert.Equal(t, c.basis, string(salary.Basis), c.text)
+		}
+	}
+
+	_, ok := models.ParseSalary("À négocier")
+	assert.False(t, ok, "Expected no salary without amount")
+}
+
+func NoRequirements(t *testing.T) {
+	score, _ := scoring.Compute(models.ProfileCandidate{}, models.JobPost{Location: "Remote"})
+	assert.Equal(t, 100, score, "Expected a job post without requirements to match everyone")
+}
+
+func FeedRelevance(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	candidate := models.ProfileCandidate{
+		Location:         "Paris",
+		PreferedContract: models.CDIContract,
+		Skills:           []models.Skill{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}},
+	}
+
+	fresh := jobPost()
+	fresh.CreatedAt = now
+	assert.Equal(t, 100.0, scoring.Relevance(candidate, fresh, now))
+
+	// The recency points are halved after a week
+	old := jobPost()
+	old.CreatedAt = now.Add(-scoring.RecencyHalfLife)
+	assert.Equal(t, 90.0, scoring.Relevance(candidate, old, now))
+
+	// Skill overlap weighs more than recency
+	unskilled := jobPost()
+	unskilled.CreatedAt = now
+	unskilled.Skills = []models.Skill{{ID: 5}, {ID: 6}}
+	assert.Less(t, scoring.Relevance(candidate, unskilled, now), scoring.Relevance(candidate, old, now))
+}
@@ -0,0 +1,78 @@
+package user_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"skillly/pkg/config"
+	"skillly/pkg/handlers/user"
+	userDto "skillly/pkg/handlers/user/dto"
+	"skillly/pkg/models"
+	testUtils "skillly/test/utils"
+)
+
+func updateUser(t *testing.T, userID uint, currentUserID uint, dto userDto.UpdateUserDTO) *httptest.ResponseRecorder {
+	jsonData, err := json.Marshal(dto)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("/user/%d", userID), bytes.NewBuffer(jsonData))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(userID)}}
+	c.Set("user_id", currentUserID)
+
+	user.NewUserService().UpdateUser(c)
+	return w
+}
+
+func UpdateUserCredentials(t *testing.T) {
+	owner := createUser(t, "credentials.owner@test.com")
+	other := createUser(t, "credentials.other@test.com")
+
+	// Another user can't take over the account
+	w := updateUser(t, owner.ID, other.ID, userDto.UpdateUserDTO{Email: "thief@test.com", CurrentPassword: "Password123!"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The current password is required to change the credentials
+	w = updateUser(t, owner.ID, owner.ID, userDto.UpdateUserDTO{Password: "NewPassword123!"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = updateUser(t, owner.ID, owner.ID, userDto.UpdateUserDTO{Password: "NewPassword123!", CurrentPassword: "WrongPassword123!"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The name is changed without the current password
+	w = updateUser(t, owner.ID, owner.ID, userDto.UpdateUserDTO{FirstName: "Renamed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = updateUser(t, owner.ID, owner.ID, userDto.UpdateUserDTO{Email: "credentials.new@test.com", CurrentPassword: "Password123!"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var updated models.User
+	require.NoError(t, config.DB.First(&updated, owner.ID).Error)
+	assert.Equal(t, "Renamed", updated.FirstName)
+	assert.Equal(t, "credentials.new@test.com", updated.Email)
+	assert.Nil(t, updated.EmailVerifiedAt, "Expected the new email to be verified again")
+}
+
+func createUser(t *testing.T, email string) models.User {
+	created, err := testUtils.UserRepo.CreateUser(userDto.CreateUserDTO{
+		FirstName: "Credentials",
+		LastName:  "User",
+		Email:     email,
+		Password:  "Password123!",
+		Role:      models.RoleCandidate,
+	}, config.DB)
+	require.NoError(t, err, "Failed to create the user")
+	return created
+}
@@ -3,6 +3,7 @@ package testUtils
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
 
 	"github.com/golang-jwt/jwt/v5"
 
@@ -11,19 +12,25 @@ import (
 	"net/url"
 	chatConf "skillly/chat/config"
 	"skillly/chat/handlers/message"
+	"skillly/chat/handlers/receipt"
 	"skillly/chat/handlers/room"
 	"skillly/pkg/config"
 	"skillly/pkg/handlers/application"
+	"skillly/pkg/handlers/applicationState"
 	authDto "skillly/pkg/handlers/auth/dto"
 	candidate "skillly/pkg/handlers/candidateProfile"
 	"skillly/pkg/handlers/certification"
 	"skillly/pkg/handlers/company"
 	companyDto "skillly/pkg/handlers/company/dto"
+	"skillly/pkg/handlers/companyInvitation"
+	"skillly/pkg/handlers/interview"
 	"skillly/pkg/handlers/jobPost"
 	"skillly/pkg/handlers/match"
 	recruiter "skillly/pkg/handlers/recruiterProfile"
+	"skillly/pkg/handlers/session"
 	"skillly/pkg/handlers/skill"
 	"skillly/pkg/handlers/user"
+	"skillly/pkg/mailer"
 	"skillly/pkg/models"
 )
 
@@ -32,29 +39,42 @@ var UserRepo user.UserRepository
 var CandidateRepo candidate.CandidateRepository
 var RecruiterRepo recruiter.RecruiterRepository
 var ApplicationRepo application.ApplicationRepository
+var ApplicationStateRepo applicationState.ApplicationStateRepository
 var CompanyRepo company.CompanyRepository
+var InvitationRepo companyInvitation.CompanyInvitationRepository
 var JobPostRepo jobPost.JobPostRepository
 var MatchRepo match.MatchRepository
+var InterviewRepo interview.InterviewRepository
 var SkillRepo skill.SkillRepository
 var CertifRepo certification.CertificationRepository
+var SessionRepo session.SessionRepository
+
+// Mails sent during the tests
+var Mailer = mailer.NewMemoryMailer()
 
 // Chat repositories
 var MessageRepo message.MessageRepository
 var RoomRepo room.RoomRepository
+var ReceiptRepo receipt.ReceiptRepository
 
 func InitTestRepositories() {
 	UserRepo = user.NewUserRepository(config.DB)
 	CandidateRepo = candidate.NewCandidateRepository(config.DB)
 	RecruiterRepo = recruiter.NewRecruiterRepository(config.DB)
 	ApplicationRepo = application.NewApplicationRepository(config.DB)
+	ApplicationStateRepo = applicationState.NewApplicationStateRepository(config.DB)
 	CompanyRepo = company.NewCompanyRepository(config.DB)
+	InvitationRepo = companyInvitation.NewCompanyInvitationRepository(config.DB)
 	JobPostRepo = jobPost.NewJobPostRepository(config.DB)
 	MatchRepo = match.NewMatchRepository(config.DB)
+	InterviewRepo = interview.NewInterviewRepository(config.DB)
 	SkillRepo = skill.NewSkillRepository(config.DB)
 	CertifRepo = certification.NewCertificationRepository(config.DB)
+	SessionRepo = session.NewSessionRepository(config.DB)
 
 	MessageRepo = message.NewMessageRepository(chatConf.DBMongo)
 	RoomRepo = room.NewRoomRepository(chatConf.DBMongo)
+	ReceiptRepo = receipt.NewReceiptRepository(chatConf.DBMongo)
 }
 
 func CreateTestContext() *gin.Context {
@@ -129,5 +149,19 @@ var RecruiterToken = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 	/* "exp":         "24h", */
 	"recruiterID": 1,
 	"companyID":   1,
-	"authRole":    models.RecruiterRole,
+	"companyRole": models.RecruiterRole,
 })
+
+// SignTestToken opens a session for the user of the token and signs it
+func SignTestToken(token *jwt.Token) (string, error) {
+	claims := token.Claims.(jwt.MapClaims)
+
+	userID, _ := claims["id"].(int)
+	testSession, _, err := SessionRepo.CreateSession(uint(userID), "test", config.DB)
+	if err != nil {
+		return "", err
+	}
+
+	claims["sid"] = testSession.ID
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
@@ -24,12 +24,21 @@ import {
 import { useJobPost } from "@/lib/hooks/useJobPost";
 import { useSkills } from "@/lib/hooks/useSkills";
 import { useCertifications } from "@/lib/hooks/useCertifications";
-import { CreateJobPostDTO } from "@/types/interfaces";
+import { ContractType, CreateJobPostDTO } from "@/types/interfaces";
 import DateTimePicker, {
   DateTimePickerEvent,
 } from "@react-native-community/datetimepicker";
 import { Portal } from "react-native-portalize";
 
+const CONTRACT_TYPES: { value: ContractType; label: string }[] = [
+  { value: "CDI", label: "CDI" },
+  { value: "CDD", label: "CDD" },
+  { value: "STAGE", label: "Stage" },
+  { value: "ALTERNANCE", label: "Alternance" },
+  { value: "FREELANCE", label: "Freelance" },
+  { value: "INTERIM", label: "Intérim" },
+];
+
 interface CreateJobPostProps {
   onSuccess: () => void;
 }
@@ -272,56 +281,100 @@ export default function CreateJobPost({ onSuccess }: CreateJobPostProps) {
           <View style={styles.inputSection}>
             <Text style={styles.inputLabel}>Type de contrat *</Text>
             <View style={styles.contractTypeContainer}>
-              <Pressable
-                style={[
-                  styles.contractButton,
-                  formData.contract_type === "CDI" &&
-                    styles.contractButtonSelected,
-                ]}
-                onPress={() =>
-                  setFormData({ ...formData, contract_type: "CDI" })
-                }
-              >
-                {formData.contract_type === "CDI" ? (
-                  <LinearGradient
-                    colors={["#4717F6", "#6366f1"]}
-                    style={styles.contractButtonGradient}
-                    start={{ x: 0, y: 0 }}
-                    end={{ x: 1, y: 1 }}
-                  >
-                    <Text style={styles.contractButtonTextSelected}>CDI</Text>
-                  </LinearGradient>
-                ) : (
-                  <Text style={styles.contractButtonText}>CDI</Text>
-                )}
-              </Pressable>
-
-              <Pressable
-                style={[
-                  styles.contractButton,
-                  formData.contract_type === "CDD" &&
-                    styles.contractButtonSelected,
-                ]}
-                onPress={() =>
-                  setFormData({ ...formData, contract_type: "CDD" })
-                }
-              >
-                {formData.contract_type === "CDD" ? (
-                  <LinearGradient
-                    colors={["#4717F6", "#6366f1"]}
-                    style={styles.contractButtonGradient}
-                    start={{ x: 0, y: 0 }}
-                    end={{ x: 1, y: 1 }}
-                  >
-                    <Text style={styles.contractButtonTextSelected}>CDD</Text>
-                  </LinearGradient>
-                ) : (
-                  <Text style={styles.contractButtonText}>CDD</Text>
-                )}
-              </Pressable>
+              {CONTRACT_TYPES.map((contract) => (
+                <Pressable
+                  key={contract.value}
+                  style={[
+                    styles.contractButton,
+                    formData.contract_type === contract.value &&
+                      styles.contractButtonSelected,
+                  ]}
+                  onPress={() =>
+                    setFormData({
+                      ...formData,
+                      contract_type: contract.value,
+                      contract_terms: {},
+                    })
+                  }
+                >
+                  {formData.contract_type === contract.value ? (
+                    <LinearGradient
+                      colors={["#4717F6", "#6366f1"]}
+                      style={styles.contractButtonGradient}
+                      start={{ x: 0, y: 0 }}
+                      end={{ x: 1, y: 1 }}
+                    >
+                      <Text style={styles.contractButtonTextSelected}>
+                        {contract.label}
+                      </Text>
+                    </LinearGradient>
+                  ) : (
+                    <Text style={styles.contractButtonText}>
+                      {contract.label}
+                    </Text>
+                  )}
+                </Pressable>
+              ))}
             </View>
           </View>
 
+          {/* Durée du contrat */}
+          {formData.contract_type !== "CDI" && (
+            <View style={styles.inputSection}>
+              <Text style={styles.inputLabel}>
+                Durée en mois
+                {formData.contract_type === "FREELANCE" ? "" : " *"}
+              </Text>
+              <View style={styles.inputContainer}>
+                <Calendar size={20} color="#6B7280" />
+                <TextInput
+                  style={[styles.textInput, styles.inputWithIcon]}
+                  value={
+                    formData.contract_terms?.duration_months?.toString() ?? ""
+                  }
+                  onChangeText={(text) => {
+                    const months = parseInt(text, 10);
+                    setFormData({
+                      ...formData,
+                      contract_terms: {
+                        ...formData.contract_terms,
+                        duration_months: isNaN(months) ? undefined : months,
+                      },
+                    });
+                  }}
+                  keyboardType="numeric"
+                  placeholder="Ex: 6"
+                  placeholderTextColor="#9CA3AF"
+                />
+              </View>
+            </View>
+          )}
+
+          {/* Rythme de l'alternance */}
+          {formData.contract_type === "ALTERNANCE" && (
+            <View style={styles.inputSection}>
+              <Text style={styles.inputLabel}>Rythme *</Text>
+              <View style={styles.inputContainer}>
+                <Briefcase size={20} color="#6B7280" />
+                <TextInput
+                  style={[styles.textInput, styles.inputWithIcon]}
+                  value={formData.contract_terms?.rhythm ?? ""}
+                  onChangeText={(text) =>
+                    setFormData({
+                      ...formData,
+                      contract_terms: {
+                        ...formData.contract_terms,
+                        rhythm: text,
+                      },
+                    })
+                  }
+                  placeholder="Ex: 3 semaines entreprise / 1 semaine école"
+                  placeholderTextColor="#9CA3AF"
+                />
+              </View>
+            </View>
+          )}
+
           {/* Salaire */}
           <View style={styles.inputSection}>
             <Text style={styles.inputLabel}>Fourchette de salaire</Text>
@@ -682,10 +735,12 @@ const styles = StyleSheet.create({
   },
   contractTypeContainer: {
     flexDirection: "row",
+    flexWrap: "wrap",
     gap: 12,
   },
   contractButton: {
-    flex: 1,
+    flexBasis: "30%",
+    flexGrow: 1,
     borderRadius: 12,
     borderWidth: 1,
     borderColor: "#E5E7EB",
@@ -44,7 +44,7 @@ export const useApplication = () => {
       state,
     }: {
       applicationId: string;
-      state: "pending" | "matched" | "rejected" | "accepted";
+      state: "matched" | "rejected" | "closed";
     }) => ApplicationService.updateApplicationState(applicationId, state),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["myApplications"] });
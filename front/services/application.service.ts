@@ -34,13 +34,15 @@ export const createApplication = async (
 
 export const updateApplicationState = async (
   applicationId: string,
-  state: "pending" | "matched" | "rejected" | "accepted"
-): Promise<{ message: string }> => {
+  state: "matched" | "rejected" | "closed",
+  reason?: string
+): Promise<Application> => {
   try {
-    const response = await instance.put<{ message: string }>(
+    const response = await instance.put<Application>(
       `/application/${applicationId}/state`,
       {
         state: state,
+        reason: reason,
       }
     );
     return response.data;
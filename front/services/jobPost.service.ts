@@ -70,10 +70,11 @@ export const getCompanyMatches = async (): Promise<JobPost[]> => {
 
 export const getCandidateJobPosts = async (): Promise<JobPost[]> => {
   try {
-    const response = await instance.get<JobPost[]>(
-      "/jobpost/candidate?populate=Skills&populate=Certifications&populate=Company"
-    );
-    return response.data;
+    const response = await instance.get<{
+      job_posts: JobPost[];
+      next_cursor: string;
+    }>("/jobpost/candidate");
+    return response.data.job_posts;
   } catch (error) {
     console.error("Erreur lors de la récupération des offres d'emploi:", error);
     throw error;
@@ -1,6 +1,6 @@
 import { AxiosError } from "axios";
 import instance from "./api";
-import { Match } from "@/types/interfaces";
+import { Match, SwipeResponse } from "@/types/interfaces";
 
 interface CreateMatchPayload {
   candidate_id: number;
@@ -10,9 +10,9 @@ interface CreateMatchPayload {
 
 export const createMatch = async (
   payload: CreateMatchPayload
-): Promise<Match> => {
+): Promise<SwipeResponse> => {
   try {
-    const response = await instance.post<Match>("/match", payload);
+    const response = await instance.post<SwipeResponse>("/match", payload);
     return response.data;
   } catch (error) {
     console.error("Erreur lors de la création du match:", error);
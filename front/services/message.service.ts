@@ -11,20 +11,20 @@ interface CreateMessagePayload {
 export const getMessagesByRoom = async (roomId: string): Promise<Message[]> => {
   try {
     const url = `/messages/room/${roomId}`;
-    const response = await instance.get<Message[]>(url);
+    const response = await instance.get<{ messages: any[]; next_cursor: string }>(url);
 
     // Vérification de la réponse avant d'accéder aux propriétés
     if (!response || response.data === null || response.data === undefined) {
       return [];
     }
 
-    if (!Array.isArray(response.data)) {
-      console.error(`❌ [FRONT] Response data is not an array:`, response.data);
-      throw new Error("Response data is not an array");
+    if (!Array.isArray(response.data.messages)) {
+      console.error(`❌ [FRONT] Response messages is not an array:`, response.data);
+      throw new Error("Response messages is not an array");
     }
 
     // Mapping des clés backend -> frontend
-    const mappedMessages = response.data.map((msg: any) => ({
+    const mappedMessages = response.data.messages.map((msg: any) => ({
       id: msg.ID || msg.id,
       content: msg.Content || msg.content,
       sender: msg.SenderID || msg.sender,
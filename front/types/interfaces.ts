@@ -209,13 +209,61 @@ export interface Match {
   jobPost: JobPost;
   matched_at: string;
 }
+export interface Swipe {
+  id: number;
+  job_post_id: number;
+  candidate_id: number;
+  recruiter_id: number;
+  decision: "like" | "pass";
+  created_at: string;
+  updated_at: string;
+}
+// The match is null until the candidate applies to the job post
+export interface SwipeResponse {
+  swipe: Swipe;
+  match: Match | null;
+}
+export interface Salary {
+  min?: number | null;
+  max?: number | null;
+  currency?: string;
+  period?: "hourly" | "monthly" | "yearly";
+  basis?: "gross" | "net";
+}
+
+export interface Coordinates {
+  latitude?: number | null;
+  longitude?: number | null;
+}
+
+export type WorkMode = "onsite" | "hybrid" | "remote";
+
+export type ContractType =
+  | "CDI"
+  | "CDD"
+  | "STAGE"
+  | "ALTERNANCE"
+  | "FREELANCE"
+  | "INTERIM";
+
+export interface ContractTerms {
+  duration_months?: number | null;
+  start_date?: string | null;
+  rhythm?: string;
+}
+
 export interface JobPost {
   id: string;
   title: string;
   location: string;
+  coordinates?: Coordinates;
+  work_mode?: WorkMode;
   contract_type: string;
+  contract_terms?: ContractTerms;
   salary_range: string;
+  salary?: Salary;
   expiration_date: string;
+  state?: "draft" | "published" | "closed" | "expired";
   created_at: string;
   skills?: Skill[];
   certifications?: Certification[];
@@ -229,9 +277,13 @@ export interface CreateJobPostDTO {
   description: string;
   title: string;
   location: string;
-  contract_type: "CDI" | "CDD";
-  salary_range: string;
+  work_mode?: WorkMode;
+  contract_type: ContractType;
+  contract_terms?: ContractTerms;
+  salary_range?: string;
+  salary?: Salary;
   expiration_date: string;
+  state?: "draft" | "published";
   skills: number[];
   certifications: number[];
 }